@@ -0,0 +1,170 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/omariomari2/uncluster/internal/converter"
+	"github.com/omariomari2/uncluster/pkg/htmlfmt"
+)
+
+// --- format ---
+
+func formatCommand(fs *flag.FlagSet) func() (pageFunc, error) {
+	var opts htmlfmt.FormatOptions
+	fs.BoolVar(&opts.Lenient, "lenient", false, "format misplaced elements where they were written instead of where the parser moves them")
+	fs.BoolVar(&opts.EncodeEntities, "entities", false, "re-encode non-ASCII characters as entities")
+	fs.StringVar(&opts.VoidStyle, "void-style", "", "close void elements as `style`: xhtml (<br />, default) or html (<br>)")
+
+	return func() (pageFunc, error) {
+		if err := opts.Validate(); err != nil {
+			return nil, err
+		}
+		return func(p page, stdout io.Writer) (string, error) {
+			formatted, err := htmlfmt.Format(p.html, opts)
+			if err != nil {
+				return "", err
+			}
+			if p.dir == "" {
+				_, err := io.WriteString(stdout, formatted)
+				return "", err
+			}
+			target := filepath.Join(p.dir, filepath.Base(p.path))
+			return target, writeFile(target, []byte(formatted))
+		}, nil
+	}
+}
+
+// --- convert ---
+
+func convertCommand(fs *flag.FlagSet) func() (pageFunc, error) {
+	var opts htmlfmt.ConvertOptions
+	fs.StringVar(&opts.ComponentName, "name", "", "component `name` for every page; defaults to the page's file name in PascalCase")
+	fs.StringVar(&opts.Language, "language", "", "js (default) or ts")
+	fs.StringVar(&opts.Indent, "indent", "", "indentation of the generated code: 2 (default), 4, or tab")
+	fs.StringVar(&opts.WrapperMode, "wrapper", "", "wrap the markup in a fragment (default), a div, or none")
+	fs.StringVar(&opts.StyleStrategy, "style", "", "convert style attributes to inline objects (default) or cssModules")
+	fs.BoolVar(&opts.GenerateProps, "props", false, "turn a repeated list into an items prop")
+	fs.BoolVar(&opts.Clean, "clean", false, "remove editor-injected attributes before converting")
+	fs.BoolVar(&opts.StripScripts, "strip-scripts", false, "drop the page's scripts")
+	fs.BoolVar(&opts.StripStyles, "strip-styles", false, "drop the page's stylesheet imports")
+
+	return func() (pageFunc, error) {
+		if err := opts.Validate(); err != nil {
+			return nil, err
+		}
+		return func(p page, stdout io.Writer) (string, error) {
+			pageOpts := opts
+			if pageOpts.ComponentName == "" {
+				pageOpts.ComponentName = converter.ComponentNameFrom(p.stem())
+			}
+			component, err := htmlfmt.Convert(p.html, pageOpts)
+			if err != nil {
+				return "", err
+			}
+			if p.dir == "" {
+				_, err := io.WriteString(stdout, component.Code)
+				return "", err
+			}
+			if component.StyleModule != "" {
+				if err := writeFile(filepath.Join(p.dir, component.Name+".module.css"), []byte(component.StyleModule)); err != nil {
+					return "", err
+				}
+			}
+			target := filepath.Join(p.dir, component.Filename)
+			return target, writeFile(target, []byte(component.Code))
+		}, nil
+	}
+}
+
+// --- extract ---
+
+// extractFlags registers the resource extraction flags shared by extract and
+// export.
+func extractFlags(fs *flag.FlagSet, opts *htmlfmt.ExtractOptions) {
+	fs.BoolVar(&opts.KeepExternal, "keep-external", false, "link external CSS and JS at their original URLs instead of downloading them")
+	fs.BoolVar(&opts.SkipCSS, "no-css", false, "leave the page's CSS in the HTML")
+	fs.BoolVar(&opts.SkipJS, "no-js", false, "leave the page's JS in the HTML")
+	fs.BoolVar(&opts.DedupeCSS, "dedupe-css", false, "drop CSS rule blocks repeated in a later stylesheet")
+	fs.BoolVar(&opts.HashFilenames, "hash-names", false, "name downloaded CSS and JS after a hash of their content")
+	fs.BoolVar(&opts.ExternalizeDataURIs, "data-uris", false, "write data URI images over 4 KB to assets/ as files")
+	fs.DurationVar(&opts.Timeout, "timeout", 0, "overall `limit` on downloading external resources, such as 30s; 0 means none")
+}
+
+func extractCommand(fs *flag.FlagSet) func() (pageFunc, error) {
+	var opts htmlfmt.ExtractOptions
+	extractFlags(fs, &opts)
+
+	return func() (pageFunc, error) {
+		if err := opts.Validate(); err != nil {
+			return nil, err
+		}
+		return func(p page, _ io.Writer) (string, error) {
+			extracted, err := htmlfmt.Extract(p.html, opts)
+			if err != nil {
+				return "", err
+			}
+			files, err := extracted.Files()
+			if err != nil {
+				return "", err
+			}
+			target := filepath.Join(p.dir, p.stem())
+			return target, writeTree(target, nil, files)
+		}, nil
+	}
+}
+
+// --- export ---
+
+func exportCommand(fs *flag.FlagSet) func() (pageFunc, error) {
+	var react htmlfmt.ReactProjectOptions
+	var ejs htmlfmt.EJSProjectOptions
+	var extract htmlfmt.ExtractOptions
+	target := fs.String("target", "react", "project `kind`: react (Vite + React) or ejs (Express + EJS)")
+	fs.StringVar(&react.Language, "language", "", "React project language: ts (default) or js")
+	fs.StringVar(&react.PackageManager, "package-manager", "", "React project package manager: npm (default), yarn, or pnpm")
+	fs.BoolVar(&react.Router, "router", false, "add react-router-dom with a route per major section")
+	fs.BoolVar(&react.IncludeDocker, "docker", false, "add a Dockerfile to the React project")
+	fs.BoolVar(&react.Minify, "minify", false, "minify the React project's own CSS")
+	fs.IntVar(&ejs.Extraction.MaxDepth, "max-depth", 0, "EJS: levels below the content root searched for sections (default 5)")
+	fs.IntVar(&ejs.Extraction.MinTextLength, "min-length", 0, "EJS: minimum size in bytes of a partial (default 500)")
+	fs.IntVar(&ejs.Extraction.RootDepth, "root-depth", 0, "EJS: single-child wrappers skipped to find the content root (default 4, negative for none)")
+	extractFlags(fs, &extract)
+
+	return func() (pageFunc, error) {
+		if err := extract.Validate(); err != nil {
+			return nil, err
+		}
+		switch kind := strings.ToLower(strings.TrimSpace(*target)); kind {
+		case "react":
+			return func(p page, _ io.Writer) (string, error) {
+				opts := react
+				opts.Name = p.stem()
+				opts.Extract = extract
+				project, err := htmlfmt.GenerateReactProject(p.html, opts)
+				if err != nil {
+					return "", err
+				}
+				dir := filepath.Join(p.dir, p.stem())
+				return dir, writeTree(dir, project.Files, project.Assets)
+			}, nil
+		case "ejs":
+			return func(p page, _ io.Writer) (string, error) {
+				opts := ejs
+				opts.Name = p.stem()
+				opts.Extract = extract
+				project, err := htmlfmt.GenerateEJSProject(p.html, opts)
+				if err != nil {
+					return "", err
+				}
+				dir := filepath.Join(p.dir, p.stem())
+				return dir, writeTree(dir, project.Files, project.Assets)
+			}, nil
+		default:
+			return nil, fmt.Errorf("invalid target %q (expected react or ejs)", *target)
+		}
+	}
+}
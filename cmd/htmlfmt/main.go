@@ -0,0 +1,177 @@
+// Command htmlfmt formats, converts, extracts, or exports an HTML file or a
+// directory of them, for build pipelines that should not depend on the HTTP
+// server. It is a thin layer of flags and file I/O over pkg/htmlfmt.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/omariomari2/uncluster/internal/logging"
+)
+
+const usageText = `htmlfmt — format, convert, extract, or export HTML files
+
+Usage:
+  htmlfmt <command> [flags] <file.html|dir>...
+
+Commands:
+  format    Pretty-print each page
+  convert   Convert each page to a React component
+  extract   Split each page's CSS and JS out into files
+  export    Generate a React or EJS project from each page
+
+Directories are searched recursively for .html and .htm files, skipping
+hidden directories and node_modules. Results go under -out, mirroring each
+page's path below the directory it was found in, or beside the page with -w.
+format and convert print a single file's result to stdout when neither is
+given; extract and export write a directory named after each page.
+
+Examples:
+  htmlfmt format -w ./site
+  htmlfmt convert -language ts -out ./src/pages ./site
+  htmlfmt extract -out ./split landing.html
+  htmlfmt export -target ejs -out ./projects ./site
+
+Run htmlfmt <command> -h for the command's flags.
+`
+
+// A pageFunc processes one page and returns the file or directory it wrote,
+// or "" when the result went to stdout.
+type pageFunc func(p page, stdout io.Writer) (string, error)
+
+type command struct {
+	// stdout reports whether a single page's result can be printed instead
+	// of written to a file.
+	stdout bool
+	// setup registers the command's flags on fs. The function it returns is
+	// called once they are parsed, to validate them and build the pageFunc.
+	setup func(fs *flag.FlagSet) func() (pageFunc, error)
+}
+
+var commands = map[string]command{
+	"format":  {stdout: true, setup: formatCommand},
+	"convert": {stdout: true, setup: convertCommand},
+	"extract": {setup: extractCommand},
+	"export":  {setup: exportCommand},
+}
+
+func main() {
+	logging.Setup()
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run executes the command line args and returns the exit status: 0 on
+// success, 1 when a page failed, and 2 for usage errors.
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usageText)
+		return 2
+	}
+	switch args[0] {
+	case "-h", "-help", "--help", "help":
+		fmt.Fprint(stdout, usageText)
+		return 0
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "htmlfmt: unknown command %q (expected %s)\n", args[0], commandNames())
+		return 2
+	}
+
+	fs := flag.NewFlagSet("htmlfmt "+args[0], flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	outDir := fs.String("out", "", "write results under `dir`")
+	inPlace := fs.Bool("w", false, "write results beside each page (format overwrites it)")
+	prepare := cmd.setup(fs)
+	if err := fs.Parse(args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+
+	process, err := prepare()
+	if err != nil {
+		fmt.Fprintf(stderr, "htmlfmt: %v\n", err)
+		return 2
+	}
+	if fs.NArg() == 0 {
+		fmt.Fprintf(stderr, "htmlfmt: %s needs a file or directory\n", args[0])
+		return 2
+	}
+	if *outDir != "" && *inPlace {
+		fmt.Fprintln(stderr, "htmlfmt: -out and -w cannot be combined")
+		return 2
+	}
+
+	var skip string
+	if *outDir != "" {
+		if skip, err = filepath.Abs(*outDir); err != nil {
+			fmt.Fprintf(stderr, "htmlfmt: resolve output directory: %v\n", err)
+			return 1
+		}
+	}
+	pages, err := collectPages(fs.Args(), skip)
+	if err != nil {
+		fmt.Fprintf(stderr, "htmlfmt: %v\n", err)
+		return 1
+	}
+	if len(pages) == 0 {
+		fmt.Fprintln(stderr, "htmlfmt: no .html files found")
+		return 1
+	}
+
+	toStdout := *outDir == "" && !*inPlace
+	if toStdout && !cmd.stdout {
+		fmt.Fprintf(stderr, "htmlfmt: %s needs -out or -w\n", args[0])
+		return 2
+	}
+	if toStdout && (fs.NArg() > 1 || isDir(fs.Arg(0))) {
+		fmt.Fprintf(stderr, "htmlfmt: %s of a directory or several files needs -out or -w\n", args[0])
+		return 2
+	}
+
+	status := 0
+	for _, p := range pages {
+		if !toStdout {
+			p.dir = filepath.Dir(p.path)
+			if *outDir != "" {
+				p.dir = filepath.Join(*outDir, filepath.Dir(filepath.FromSlash(p.rel)))
+			}
+		}
+		raw, err := os.ReadFile(p.path)
+		if err != nil {
+			fmt.Fprintf(stderr, "htmlfmt: %v\n", err)
+			status = 1
+			continue
+		}
+		p.html = string(raw)
+
+		written, err := process(p, stdout)
+		if err != nil {
+			fmt.Fprintf(stderr, "htmlfmt: %s: %v\n", p.path, err)
+			status = 1
+			continue
+		}
+		if written != "" {
+			fmt.Fprintf(stdout, "%s -> %s\n", p.path, written)
+		}
+	}
+	return status
+}
+
+func commandNames() string {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testPage = `<html><head><style>.hero { color: red; }</style></head><body>
+<section class="hero"><h1>Hello</h1><br></section>
+</body></html>`
+
+// writeSite creates a directory of pages, including one in a hidden directory
+// that must be skipped, and returns its path.
+func writeSite(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, name := range []string{"landing.html", "blog/first-post.htm", ".cache/stale.html", "notes.txt"} {
+		if err := writeFile(filepath.Join(dir, filepath.FromSlash(name)), []byte(testPage)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func runCLI(t *testing.T, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := run(args, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestFormatDirectoryToOutputDir(t *testing.T) {
+	site := writeSite(t)
+	out := filepath.Join(t.TempDir(), "formatted")
+
+	code, stdout, stderr := runCLI(t, "format", "-void-style", "html", "-out", out, site)
+	if code != 0 {
+		t.Fatalf("exit %d: %s", code, stderr)
+	}
+	if got := strings.Count(stdout, " -> "); got != 2 {
+		t.Fatalf("expected 2 pages written, got %d:\n%s", got, stdout)
+	}
+	formatted := readFile(t, filepath.Join(out, "blog", "first-post.htm"))
+	if !strings.Contains(formatted, "\n\t<head>") || !strings.Contains(formatted, "<br>") {
+		t.Fatalf("expected formatted HTML with html void style, got:\n%s", formatted)
+	}
+	if _, err := os.Stat(filepath.Join(out, ".cache")); !os.IsNotExist(err) {
+		t.Fatalf("expected hidden directories to be skipped, stat returned %v", err)
+	}
+}
+
+func TestFormatInPlace(t *testing.T) {
+	site := writeSite(t)
+	path := filepath.Join(site, "landing.html")
+
+	if code, _, stderr := runCLI(t, "format", "-w", path); code != 0 {
+		t.Fatalf("exit %d: %s", code, stderr)
+	}
+	if formatted := readFile(t, path); formatted == testPage || !strings.Contains(formatted, "<br />") {
+		t.Fatalf("expected the page to be formatted in place, got:\n%s", formatted)
+	}
+}
+
+func TestConvertToStdoutAndOutputDir(t *testing.T) {
+	site := writeSite(t)
+
+	code, stdout, stderr := runCLI(t, "convert", filepath.Join(site, "blog", "first-post.htm"))
+	if code != 0 {
+		t.Fatalf("exit %d: %s", code, stderr)
+	}
+	if !strings.Contains(stdout, "function FirstPost()") {
+		t.Fatalf("expected the component named after the file on stdout, got:\n%s", stdout)
+	}
+
+	out := t.TempDir()
+	if code, _, stderr := runCLI(t, "convert", "-language", "ts", "-out", out, site); code != 0 {
+		t.Fatalf("exit %d: %s", code, stderr)
+	}
+	for _, name := range []string{"Landing.tsx", "blog/FirstPost.tsx"} {
+		if _, err := os.Stat(filepath.Join(out, filepath.FromSlash(name))); err != nil {
+			t.Fatalf("expected %s: %v", name, err)
+		}
+	}
+}
+
+func TestExtractAndExport(t *testing.T) {
+	site := writeSite(t)
+	out := t.TempDir()
+
+	if code, _, stderr := runCLI(t, "extract", "-out", out, filepath.Join(site, "landing.html")); code != 0 {
+		t.Fatalf("exit %d: %s", code, stderr)
+	}
+	if css := readFile(t, filepath.Join(out, "landing", "inline", "style-1.css")); !strings.Contains(css, ".hero") {
+		t.Fatalf("expected the inline style in its own file, got %q", css)
+	}
+	if index := readFile(t, filepath.Join(out, "landing", "index.html")); strings.Contains(index, "<style>") {
+		t.Fatalf("expected the style block to be extracted, got:\n%s", index)
+	}
+	if manifest := readFile(t, filepath.Join(out, "landing", "manifest.json")); !strings.Contains(manifest, `"inline/style-1.css"`) {
+		t.Fatalf("expected manifest.json to list the extracted files, got:\n%s", manifest)
+	}
+
+	if code, _, stderr := runCLI(t, "export", "-target", "ejs", "-out", out, site); code != 0 {
+		t.Fatalf("exit %d: %s", code, stderr)
+	}
+	for _, name := range []string{"landing/package.json", "blog/first-post/views/index.ejs"} {
+		if _, err := os.Stat(filepath.Join(out, filepath.FromSlash(name))); err != nil {
+			t.Fatalf("expected %s: %v", name, err)
+		}
+	}
+}
+
+func TestUsageErrors(t *testing.T) {
+	site := writeSite(t)
+	cases := [][]string{
+		{},
+		{"lint", site},
+		{"format", site},
+		{"format", "-out", t.TempDir(), "-w", site},
+		{"extract", filepath.Join(site, "landing.html")},
+		{"convert", "-language", "coffee", site},
+		{"export", "-target", "vue", "-out", t.TempDir(), site},
+	}
+	for _, args := range cases {
+		if code, _, stderr := runCLI(t, args...); code != 2 || stderr == "" {
+			t.Errorf("htmlfmt %s: expected exit 2 with a message, got %d and %q", strings.Join(args, " "), code, stderr)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// page is an HTML file to process.
+type page struct {
+	path string // the file as found, relative to the working directory or absolute
+	rel  string // slash-separated path below the directory it was found in, or its base name
+	html string
+	dir  string // directory its results go to; empty when they go to stdout
+}
+
+// stem returns the page's file name without its extension.
+func (p page) stem() string {
+	base := filepath.Base(p.path)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+// collectPages returns the .html and .htm files among paths, searching
+// directories recursively. Hidden directories, node_modules, and skip (the
+// absolute output directory, so earlier results are not picked up again) are
+// left out.
+func collectPages(paths []string, skip string) ([]page, error) {
+	var pages []page
+	for _, root := range paths {
+		info, err := os.Stat(root)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			pages = append(pages, page{path: root, rel: filepath.Base(root)})
+			continue
+		}
+
+		err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if path != root && skipDir(path, skip) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !isHTMLFile(path) {
+				return nil
+			}
+			rel, err := filepath.Rel(root, path)
+			if err != nil {
+				return err
+			}
+			pages = append(pages, page{path: path, rel: filepath.ToSlash(rel)})
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return pages, nil
+}
+
+func skipDir(path, skip string) bool {
+	name := filepath.Base(path)
+	if strings.HasPrefix(name, ".") || name == "node_modules" {
+		return true
+	}
+	if skip == "" {
+		return false
+	}
+	abs, err := filepath.Abs(path)
+	return err == nil && abs == skip
+}
+
+func isHTMLFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".html", ".htm":
+		return true
+	}
+	return false
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+func writeFile(path string, content []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, content, 0o644)
+}
+
+// writeTree writes files and assets, keyed by slash-separated paths, under
+// dir.
+func writeTree(dir string, files map[string]string, assets map[string][]byte) error {
+	for rel, content := range files {
+		if err := writeFile(filepath.Join(dir, filepath.FromSlash(rel)), []byte(content)); err != nil {
+			return err
+		}
+	}
+	for rel, content := range assets {
+		if err := writeFile(filepath.Join(dir, filepath.FromSlash(rel)), content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/omariomari2/uncluster/internal/converter"
 	"github.com/omariomari2/uncluster/internal/extractor"
 	"github.com/omariomari2/uncluster/internal/formatter"
+	"github.com/omariomari2/uncluster/internal/logging"
 	"github.com/omariomari2/uncluster/internal/nodejs"
 	"os"
 	"path/filepath"
@@ -91,6 +92,7 @@ func parseArgs() (inputFile, format, outDir, destDir string) {
 }
 
 func main() {
+	logging.Setup()
 	inputFile, format, outDir, destDir := parseArgs()
 
 	if inputFile == "" {
@@ -178,6 +180,9 @@ func runFormat(htmlContent, outDir string) {
 	if err != nil {
 		fail("format HTML", err)
 	}
+	if restructured, err := formatter.Restructured(htmlContent); err == nil && restructured {
+		fmt.Fprintln(os.Stderr, "warning: the HTML parser moved misplaced elements, so the formatted structure differs from the input")
+	}
 
 	if outDir == "" {
 		fmt.Print(formatted)
@@ -342,6 +347,7 @@ func runNodeJS(htmlContent, outDir string) {
 		JS:             extracted.JS,
 		ExternalCSS:    extracted.ExternalCSS,
 		ExternalJS:     extracted.ExternalJS,
+		Head:           extracted.Head,
 	}
 
 	projectFiles, err := nodejs.GenerateProject(config)
@@ -19,4 +19,5 @@ require (
 	github.com/valyala/fasthttp v1.51.0
 	github.com/valyala/tcplisten v1.0.0
 	golang.org/x/sys v0.15.0
+	golang.org/x/text v0.14.0
 )
@@ -0,0 +1,76 @@
+package analyzer
+
+import "strings"
+
+// Suggestion categories, in the order GroupSuggestions returns them.
+const (
+	CategoryNavigation = "navigation"
+	CategoryForms      = "forms"
+	CategoryButtons    = "buttons"
+	CategoryCards      = "cards"
+	CategoryLayout     = "layout"
+	CategoryOther      = "other"
+)
+
+// categoryKeywords maps each category to the pattern key words that place a
+// suggestion in it. Categories are checked in order, so a "nav-button" is
+// navigation rather than a button.
+var categoryKeywords = []struct {
+	category string
+	keywords map[string]bool
+}{
+	{CategoryNavigation, map[string]bool{"nav": true, "menu": true, "tab": true, "dropdown": true, "breadcrumb": true}},
+	{CategoryForms, map[string]bool{"form": true, "input": true, "field": true, "select": true}},
+	{CategoryButtons, map[string]bool{"button": true, "btn": true, "badge": true, "chip": true, "tag": true}},
+	{CategoryCards, map[string]bool{"card": true, "thumbnail": true, "avatar": true, "tile": true}},
+	{CategoryLayout, map[string]bool{"modal": true, "dialog": true, "popup": true, "accordion": true, "list": true, "grid": true, "container": true}},
+}
+
+// SuggestionGroup holds the suggestions that share a category.
+type SuggestionGroup struct {
+	Category    string                `json:"category"`
+	Suggestions []ComponentSuggestion `json:"suggestions"`
+}
+
+// categorize returns the category for a pattern key. Keywords match whole
+// words of the tag, classes, and id, split at hyphens and underscores, so a
+// "table" is not a tab and a "tagline" is not a tag.
+func categorize(patternKey string) string {
+	words := patternKeyWords(patternKey)
+	for _, entry := range categoryKeywords {
+		for _, word := range words {
+			if entry.keywords[word] {
+				return entry.category
+			}
+		}
+	}
+	return CategoryOther
+}
+
+// patternKeyWords splits a pattern key such as "li.nav_item#home-link" into
+// its lowercase words: li, nav, item, home, and link.
+func patternKeyWords(patternKey string) []string {
+	return strings.FieldsFunc(strings.ToLower(patternKey), func(r rune) bool {
+		return r == '.' || r == '#' || r == '-' || r == '_'
+	})
+}
+
+// GroupSuggestions groups suggestions by Category. Groups follow the category
+// order above and empty categories are left out.
+func GroupSuggestions(suggestions []ComponentSuggestion) []SuggestionGroup {
+	byCategory := make(map[string][]ComponentSuggestion)
+	for _, suggestion := range suggestions {
+		byCategory[suggestion.Category] = append(byCategory[suggestion.Category], suggestion)
+	}
+
+	var groups []SuggestionGroup
+	for _, entry := range categoryKeywords {
+		if len(byCategory[entry.category]) > 0 {
+			groups = append(groups, SuggestionGroup{Category: entry.category, Suggestions: byCategory[entry.category]})
+		}
+	}
+	if len(byCategory[CategoryOther]) > 0 {
+		groups = append(groups, SuggestionGroup{Category: CategoryOther, Suggestions: byCategory[CategoryOther]})
+	}
+	return groups
+}
@@ -3,8 +3,10 @@ package analyzer
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
 
+	"github.com/omariomari2/uncluster/internal/htmlparse"
 	"golang.org/x/net/html"
 )
 
@@ -16,18 +18,68 @@ type ComponentSuggestion struct {
 	Children    []string          `json:"children"`
 	Count       int               `json:"count"`
 	JSXCode     string            `json:"jsxCode"`
+	// Category is the kind of component (navigation, forms, buttons, cards,
+	// layout, or other), derived from the pattern's tag and classes.
+	Category string `json:"category"`
+
+	// SourceLine and SourceColumn locate the first occurrence's opening tag
+	// in the analyzed HTML (1-based). Zero when the element was implied by
+	// the parser rather than written in the input.
+	SourceLine   int `json:"sourceLine,omitempty"`
+	SourceColumn int `json:"sourceColumn,omitempty"`
+	// TextProps are the props JSXCode renders in place of text that differs
+	// between occurrences, such as title or price. Empty when the component
+	// takes its content as children instead.
+	TextProps []string `json:"textProps,omitempty"`
+
+	patternKey string
+	// shape is the first occurrence, which TextValues aligns others with.
+	shape *html.Node
+	slots []textSlot
+}
+
+// Matches reports whether n is an occurrence of the suggested pattern: the
+// same tag with the same classes and id.
+func (s ComponentSuggestion) Matches(n *html.Node) bool {
+	return s.patternKey != "" && n.Type == html.ElementNode && generatePatternKey(n) == s.patternKey
 }
 
 func AnalyzeComponents(htmlInput string) ([]ComponentSuggestion, error) {
-	doc, err := html.Parse(strings.NewReader(htmlInput))
+	return AnalyzeComponentsWithOptions(htmlInput, AnalyzeOptions{})
+}
+
+// AnalyzeComponentsWithOptions analyzes htmlInput like AnalyzeComponents,
+// applying opts.
+func AnalyzeComponentsWithOptions(htmlInput string, opts AnalyzeOptions) ([]ComponentSuggestion, error) {
+	doc, err := htmlparse.Parse(htmlInput)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse HTML: %w", err)
 	}
+	return AnalyzeDocument(htmlInput, doc, opts), nil
+}
 
+// AnalyzeDocument analyzes doc, already parsed from htmlInput, like
+// AnalyzeComponentsWithOptions, for callers that go on to convert the same
+// tree. htmlInput is only read to locate each suggestion's source line.
+func AnalyzeDocument(htmlInput string, doc *html.Node, opts AnalyzeOptions) []ComponentSuggestion {
 	elementPatterns := make(map[string]*ElementPattern)
-	collectPatterns(doc, elementPatterns)
+	collectPatterns(doc, elementPatterns, opts.Exclude)
+
+	suggestions := generateSuggestionsWithoutAI(elementPatterns)
 
-	return generateSuggestionsWithoutAI(elementPatterns), nil
+	positions := locateElements(htmlInput, doc)
+	for i := range suggestions {
+		pattern := elementPatterns[suggestions[i].patternKey]
+		if pattern == nil || len(pattern.Examples) == 0 {
+			continue
+		}
+		if pos, ok := positions[pattern.Examples[0]]; ok {
+			suggestions[i].SourceLine = pos.Line
+			suggestions[i].SourceColumn = pos.Column
+		}
+	}
+
+	return suggestions
 }
 
 type ElementPattern struct {
@@ -38,7 +90,13 @@ type ElementPattern struct {
 	Examples   []*html.Node
 }
 
-func collectPatterns(n *html.Node, patterns map[string]*ElementPattern) {
+// collectPatterns tallies the element patterns under n, skipping non-content
+// and excluded subtrees.
+func collectPatterns(n *html.Node, patterns map[string]*ElementPattern, exclude Exclusions) {
+	if htmlparse.IsNonContent(n) || exclude.Matches(n) {
+		return
+	}
+
 	if n.Type == html.ElementNode {
 		patternKey := generatePatternKey(n)
 
@@ -71,7 +129,7 @@ func collectPatterns(n *html.Node, patterns map[string]*ElementPattern) {
 	}
 
 	for c := n.FirstChild; c != nil; c = c.NextSibling {
-		collectPatterns(c, patterns)
+		collectPatterns(c, patterns, exclude)
 	}
 }
 
@@ -100,6 +158,12 @@ func getAttributeValue(n *html.Node, attrName string) string {
 	return ""
 }
 
+// PropName returns the prop a suggested component takes for the HTML
+// attribute attr, e.g. "className" for class and "dataKind" for data-kind.
+func PropName(attr string) string {
+	return convertToValidPropName(attr)
+}
+
 func convertToValidPropName(attr string) string {
 	if attr == "class" {
 		return "className"
@@ -151,57 +215,100 @@ func kebabToCamel(s string) string {
 	return result
 }
 
+// obviousPatterns are the pattern key fragments that make a repeated element
+// worth a component.
+var obviousPatterns = map[string]bool{
+	"card": true, "button": true, "btn": true,
+	"nav-item": true, "menu-item": true, "list-item": true,
+	"modal": true, "dialog": true, "popup": true,
+	"form-field": true, "input-group": true,
+	"tab": true, "accordion": true, "dropdown": true,
+	"badge": true, "tag": true, "chip": true,
+	"avatar": true, "thumbnail": true,
+	"alert": true, "toast": true, "notification": true,
+}
+
+// landmarkNames maps the HTML5 landmark elements, which are suggested even
+// when they appear once, to the component name they get. As in the EJS
+// extractor's isSectionBoundary, <main> is left out: it holds the page's
+// content rather than being one part of it.
+var landmarkNames = map[string]string{
+	"header":  "Header",
+	"nav":     "Navigation",
+	"footer":  "Footer",
+	"aside":   "Sidebar",
+	"article": "Article",
+}
+
 func generateSuggestionsWithoutAI(patterns map[string]*ElementPattern) []ComponentSuggestion {
 	var suggestions []ComponentSuggestion
 
-	obviousPatterns := map[string]bool{
-		"card": true, "button": true, "btn": true,
-		"nav-item": true, "menu-item": true, "list-item": true,
-		"modal": true, "dialog": true, "popup": true,
-		"form-field": true, "input-group": true,
-		"tab": true, "accordion": true, "dropdown": true,
-		"badge": true, "tag": true, "chip": true,
-		"avatar": true, "thumbnail": true,
-		"alert": true, "toast": true, "notification": true,
-	}
-
 	structuralElements := map[string]bool{
 		"html": true, "head": true, "body": true, "title": true,
 		"meta": true, "link": true, "script": true, "style": true,
 		"base": true, "noscript": true,
 	}
 
+	// Landmarks sharing a tag, such as a site header and a post header, are
+	// named after their class instead of all being called Header.
+	landmarkPatterns := make(map[string]int)
+	for patternKey, pattern := range patterns {
+		if _, ok := landmarkNames[pattern.TagName]; ok && !matchesObviousPattern(patternKey, obviousPatterns) {
+			landmarkPatterns[pattern.TagName]++
+		}
+	}
+
 	for patternKey, pattern := range patterns {
 		if structuralElements[pattern.TagName] {
 			continue
 		}
 
-		if !matchesObviousPattern(patternKey, obviousPatterns) {
-			continue
+		_, landmark := landmarkNames[pattern.TagName]
+		if !landmark {
+			if !matchesObviousPattern(patternKey, obviousPatterns) && !isCustomElement(pattern.TagName) {
+				continue
+			}
+			if pattern.Count < 3 || isStructuralElement(pattern.TagName) {
+				continue
+			}
 		}
 
-		if pattern.Count < 3 {
-			continue
+		var props []string
+		for _, attr := range sharedAttributes(pattern) {
+			props = append(props, convertToValidPropName(attr))
+		}
+		var slots []textSlot
+		if !isVoidElement(pattern.TagName) {
+			slots = findTextSlots(pattern.Examples, props)
 		}
 
-		if isStructuralElement(pattern.TagName) {
-			continue
+		name := generateComponentName(pattern.TagName, patternKey)
+		if landmark && landmarkPatterns[pattern.TagName] > 1 && name == landmarkNames[pattern.TagName] {
+			name = qualifiedLandmarkName(pattern.Examples[0], name)
 		}
 
 		suggestion := ComponentSuggestion{
-			Name:        generateComponentName(pattern.TagName, patternKey),
+			Name:        name,
 			Description: generateDescription(pattern),
 			TagName:     pattern.TagName,
 			Attributes:  make(map[string]string),
 			Children:    make([]string, 0),
 			Count:       pattern.Count,
-			JSXCode:     generateJSXCode(pattern),
+			JSXCode:     generateJSXCodeWithName(pattern, name, slots),
+			Category:    categorize(patternKey),
+			patternKey:  patternKey,
+			shape:       pattern.Examples[0],
+			slots:       slots,
+		}
+		for _, slot := range slots {
+			suggestion.TextProps = append(suggestion.TextProps, slot.prop)
+		}
+		if landmark && suggestion.Category == CategoryOther {
+			suggestion.Category = CategoryLayout
 		}
 
-		for attr, count := range pattern.Attributes {
-			if count >= pattern.Count/2 {
-				suggestion.Attributes[attr] = "{string}"
-			}
+		for _, attr := range sharedAttributes(pattern) {
+			suggestion.Attributes[attr] = "{string}"
 		}
 
 		for childTag, count := range pattern.Children {
@@ -209,13 +316,36 @@ func generateSuggestionsWithoutAI(patterns map[string]*ElementPattern) []Compone
 				suggestion.Children = append(suggestion.Children, childTag)
 			}
 		}
+		sort.Strings(suggestion.Children)
 
 		suggestions = append(suggestions, suggestion)
 	}
 
+	// Map iteration order is random; sort so the same input always yields
+	// the same response.
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].Count != suggestions[j].Count {
+			return suggestions[i].Count > suggestions[j].Count
+		}
+		return suggestions[i].patternKey < suggestions[j].patternKey
+	})
+
 	return suggestions
 }
 
+// sharedAttributes returns, sorted by name, the attributes present on at least
+// half of the pattern's occurrences. These become the component's props.
+func sharedAttributes(pattern *ElementPattern) []string {
+	var attrs []string
+	for attr, count := range pattern.Attributes {
+		if count >= pattern.Count/2 {
+			attrs = append(attrs, attr)
+		}
+	}
+	sort.Strings(attrs)
+	return attrs
+}
+
 func matchesObviousPattern(patternKey string, patterns map[string]bool) bool {
 	lowerKey := strings.ToLower(patternKey)
 	for pattern := range patterns {
@@ -235,7 +365,25 @@ func isStructuralElement(tagName string) bool {
 	return structural[tagName]
 }
 
+// isCustomElement reports whether tagName is a custom element such as
+// my-card. Repeated custom elements are always worth wrapping, and React
+// expects their class attribute as class rather than className.
+func isCustomElement(tagName string) bool {
+	return strings.Contains(tagName, "-")
+}
+
+// generateComponentName names the component for a pattern. Landmarks get
+// their role's name, such as Header or Sidebar, unless their classes name a
+// more specific pattern, like the nav-item of a repeated <nav class="nav-item">.
 func generateComponentName(tagName, patternKey string) string {
+	if isCustomElement(tagName) {
+		name := kebabToCamel(tagName)
+		return strings.ToUpper(name[:1]) + name[1:]
+	}
+	if name, ok := landmarkNames[tagName]; ok && !matchesObviousPattern(patternKey, obviousPatterns) {
+		return name
+	}
+
 	name := strings.Title(tagName)
 
 	if strings.Contains(patternKey, "card") {
@@ -255,6 +403,32 @@ func generateComponentName(tagName, patternKey string) string {
 	return name
 }
 
+// qualifiedLandmarkName names landmark n, whose tag other suggested patterns
+// share, after its first class or its id: <header class="post-header"> is a
+// PostHeader. name, the landmark's own name, is appended when the class does
+// not mention the tag, so <nav class="primary"> is a PrimaryNavigation. n keeps
+// name when it has neither or they don't make an identifier.
+func qualifiedLandmarkName(n *html.Node, name string) string {
+	label := getAttributeValue(n, "id")
+	if classes := strings.Fields(getAttributeValue(n, "class")); len(classes) > 0 {
+		label = classes[0]
+	}
+
+	var qualified strings.Builder
+	mentioned := false
+	for _, word := range strings.FieldsFunc(strings.ToLower(label), func(r rune) bool { return r == '-' || r == '_' }) {
+		mentioned = mentioned || word == n.Data || word == strings.ToLower(name)
+		qualified.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	if !mentioned {
+		qualified.WriteString(name)
+	}
+	if !isIdentifier(qualified.String()) {
+		return name
+	}
+	return qualified.String()
+}
+
 func generateDescription(pattern *ElementPattern) string {
 	desc := fmt.Sprintf("A reusable %s component", pattern.TagName)
 
@@ -273,55 +447,11 @@ func generateDescription(pattern *ElementPattern) string {
 	return desc
 }
 
-func generateJSXCode(pattern *ElementPattern) string {
+func generateJSXCode(pattern *ElementPattern, slots []textSlot) string {
 	if len(pattern.Examples) == 0 {
 		return ""
 	}
-
-	example := pattern.Examples[0]
-	var buf strings.Builder
-
-	componentName := generateComponentName(pattern.TagName, generatePatternKey(example))
-	buf.WriteString(fmt.Sprintf("const %s = ({ ", componentName))
-
-	props := []string{}
-	propMap := make(map[string]string)
-	for attr, count := range pattern.Attributes {
-		if count >= pattern.Count/2 {
-			propName := convertToValidPropName(attr)
-			props = append(props, propName)
-			propMap[attr] = propName
-		}
-	}
-
-	if len(props) > 0 {
-		buf.WriteString(strings.Join(props, ", "))
-	}
-
-	buf.WriteString(" }) => {\n")
-	buf.WriteString("\treturn (\n")
-
-	buf.WriteString(fmt.Sprintf("\t\t<%s", pattern.TagName))
-
-	for attr, count := range pattern.Attributes {
-		if count >= pattern.Count/2 {
-			propName := propMap[attr]
-			jsxAttr := attr
-			if attr == "class" {
-				jsxAttr = "className"
-			}
-			buf.WriteString(fmt.Sprintf(" %s={%s}", jsxAttr, propName))
-		}
-	}
-
-	buf.WriteString(">\n")
-	buf.WriteString("\t\t\t\n")
-	buf.WriteString(fmt.Sprintf("\t\t</%s>\n", pattern.TagName))
-	buf.WriteString("\t);\n")
-	buf.WriteString("};\n\n")
-	buf.WriteString("export default " + generateComponentName(pattern.TagName, generatePatternKey(example)) + ";")
-
-	return buf.String()
+	return generateJSXCodeWithName(pattern, generateComponentName(pattern.TagName, generatePatternKey(pattern.Examples[0])), slots)
 }
 
 func nodeToHTML(n *html.Node) string {
@@ -379,7 +509,11 @@ func isVoidElement(tagName string) bool {
 	return voidElements[strings.ToLower(tagName)]
 }
 
-func generateJSXCodeWithName(pattern *ElementPattern, componentName string) string {
+// generateJSXCodeWithName renders the component's source. When slots is
+// empty the occurrence's content is passed as children; otherwise the first
+// example's content is written out with each slot's text replaced by its
+// prop.
+func generateJSXCodeWithName(pattern *ElementPattern, componentName string, slots []textSlot) string {
 	if len(pattern.Examples) == 0 {
 		return ""
 	}
@@ -388,39 +522,52 @@ func generateJSXCodeWithName(pattern *ElementPattern, componentName string) stri
 
 	buf.WriteString(fmt.Sprintf("const %s = ({ ", componentName))
 
+	attrs := sharedAttributes(pattern)
 	props := []string{}
 	propMap := make(map[string]string)
-	for attr, count := range pattern.Attributes {
-		if count >= pattern.Count/2 {
-			propName := convertToValidPropName(attr)
-			props = append(props, propName)
-			propMap[attr] = propName
-		}
+	for _, attr := range attrs {
+		propName := convertToValidPropName(attr)
+		props = append(props, propName)
+		propMap[attr] = propName
 	}
 
-	if len(props) > 0 {
-		buf.WriteString(strings.Join(props, ", "))
+	// Occurrences keep their content as children, except void elements,
+	// which cannot have any, and components that render text props.
+	void := isVoidElement(pattern.TagName)
+	slotProps := make(map[string]string, len(slots))
+	for _, slot := range slots {
+		props = append(props, slot.prop)
+		slotProps[slotKey(slot.path)] = slot.prop
+	}
+	if !void && len(slots) == 0 {
+		props = append(props, "children")
 	}
+	buf.WriteString(strings.Join(props, ", "))
 
 	buf.WriteString(" }) => {\n")
 	buf.WriteString("\treturn (\n")
 
 	buf.WriteString(fmt.Sprintf("\t\t<%s", pattern.TagName))
 
-	for attr, count := range pattern.Attributes {
-		if count >= pattern.Count/2 {
-			propName := propMap[attr]
-			jsxAttr := attr
-			if attr == "class" {
-				jsxAttr = "className"
-			}
-			buf.WriteString(fmt.Sprintf(" %s={%s}", jsxAttr, propName))
+	for _, attr := range attrs {
+		jsxAttr := attr
+		if attr == "class" && !isCustomElement(pattern.TagName) {
+			jsxAttr = "className"
 		}
+		buf.WriteString(fmt.Sprintf(" %s={%s}", jsxAttr, propMap[attr]))
 	}
 
-	buf.WriteString(">\n")
-	buf.WriteString("\t\t\t\n")
-	buf.WriteString(fmt.Sprintf("\t\t</%s>\n", pattern.TagName))
+	if void {
+		buf.WriteString(" />\n")
+	} else {
+		buf.WriteString(">\n")
+		if len(slots) == 0 {
+			buf.WriteString("\t\t\t{children}\n")
+		} else {
+			writeSlottedContent(&buf, pattern.Examples[0], slotProps)
+		}
+		buf.WriteString(fmt.Sprintf("\t\t</%s>\n", pattern.TagName))
+	}
 	buf.WriteString("\t);\n")
 	buf.WriteString("};\n\n")
 	buf.WriteString("export default " + componentName + ";")
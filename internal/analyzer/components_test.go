@@ -0,0 +1,418 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+func TestAnalyzeComponentsReportsSourcePosition(t *testing.T) {
+	input := `<!doctype html>
+<html>
+<body>
+  <main>
+    <figure class="card"><img src="a.png"></figure>
+    <figure class="card"><img src="b.png"></figure>
+    <figure class="card"><img src="c.png"></figure>
+  </main>
+</body>
+</html>`
+
+	suggestions, err := AnalyzeComponents(input)
+	if err != nil {
+		t.Fatalf("AnalyzeComponents returned error: %v", err)
+	}
+	if len(suggestions) != 1 {
+		t.Fatalf("expected 1 suggestion, got %+v", suggestions)
+	}
+	if got := suggestions[0]; got.SourceLine != 5 || got.SourceColumn != 5 {
+		t.Fatalf("expected card at line 5, column 5, got line %d, column %d", got.SourceLine, got.SourceColumn)
+	}
+}
+
+func TestAnalyzeDocumentMatchesAnalyzeComponents(t *testing.T) {
+	input := `<main>
+  <figure class="card"><img src="a.png"></figure>
+  <figure class="card"><img src="b.png"></figure>
+  <figure class="card"><img src="c.png"></figure>
+</main>`
+	doc, err := html.Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := AnalyzeDocument(input, doc, AnalyzeOptions{})
+	want, err := AnalyzeComponents(input)
+	if err != nil {
+		t.Fatalf("AnalyzeComponents returned error: %v", err)
+	}
+	if len(got) != 1 || len(want) != 1 || got[0].Name != want[0].Name || got[0].SourceLine != want[0].SourceLine || got[0].JSXCode != want[0].JSXCode {
+		t.Fatalf("AnalyzeDocument = %+v, want %+v", got, want)
+	}
+	if !got[0].Matches(doc.FirstChild.LastChild.FirstChild.FirstChild.NextSibling) {
+		t.Fatalf("expected the suggestion to match the first item in doc")
+	}
+}
+
+func TestAnalyzeComponentsOnFragment(t *testing.T) {
+	input := `<li class="card">one</li>
+<li class="card">two</li>
+<li class="card">three</li>`
+
+	stats, err := GetPatternStats(input)
+	if err != nil {
+		t.Fatalf("GetPatternStats returned error: %v", err)
+	}
+	for _, stat := range stats {
+		switch stat.TagName {
+		case "html", "head", "body":
+			t.Fatalf("fragment should not produce implied %s patterns: %+v", stat.TagName, stats)
+		}
+	}
+	if len(stats) != 1 || stats[0].Key != "li.card" || stats[0].Count != 3 {
+		t.Fatalf("expected only the li.card pattern, got %+v", stats)
+	}
+
+	suggestions, err := AnalyzeComponents(input)
+	if err != nil {
+		t.Fatalf("AnalyzeComponents returned error: %v", err)
+	}
+	for _, suggestion := range suggestions {
+		if suggestion.SourceLine != 1 || suggestion.SourceColumn != 1 {
+			t.Fatalf("expected the first card at line 1, column 1, got %+v", suggestion)
+		}
+	}
+}
+
+func TestLocateElementsSkipsImpliedElements(t *testing.T) {
+	input := "<table><tr><td>a</td></tr></table>\n<p>after</p>"
+
+	doc := mustParse(t, input)
+	positions := locateElements(input, doc)
+	for n, pos := range positions {
+		switch n.Data {
+		case "tbody", "html", "head", "body":
+			t.Fatalf("implied <%s> should have no position, got %+v", n.Data, pos)
+		case "p":
+			if pos.Line != 2 || pos.Column != 1 {
+				t.Fatalf("expected <p> at 2:1, got %+v", pos)
+			}
+		}
+	}
+}
+
+func mustParse(t *testing.T, input string) *html.Node {
+	t.Helper()
+	doc, err := html.Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+	return doc
+}
+
+func TestAnalyzeComponentsGroupsByCategory(t *testing.T) {
+	input := `<nav class="nav-item">a</nav><nav class="nav-item">b</nav><nav class="nav-item">c</nav>
+<figure class="card">1</figure><figure class="card">2</figure><figure class="card">3</figure>
+<button class="btn">x</button><button class="btn">y</button><button class="btn">z</button>`
+
+	suggestions, err := AnalyzeComponents(input)
+	if err != nil {
+		t.Fatalf("AnalyzeComponents returned error: %v", err)
+	}
+
+	categories := make(map[string]string)
+	for _, suggestion := range suggestions {
+		categories[suggestion.TagName] = suggestion.Category
+	}
+	want := map[string]string{"nav": CategoryNavigation, "figure": CategoryCards, "button": CategoryButtons}
+	for tag, category := range want {
+		if categories[tag] != category {
+			t.Fatalf("expected %s suggestion in %q, got %v", tag, category, categories)
+		}
+	}
+
+	groups := GroupSuggestions(suggestions)
+	var order []string
+	for _, group := range groups {
+		order = append(order, group.Category)
+	}
+	if got := strings.Join(order, ","); got != "navigation,buttons,cards" {
+		t.Fatalf("unexpected group order %q", got)
+	}
+}
+
+func TestCategorizeMatchesWholeWords(t *testing.T) {
+	cases := map[string]string{
+		"li.nav-item":      CategoryNavigation,
+		"div.site_menu":    CategoryNavigation,
+		"button":           CategoryButtons,
+		"span.tag":         CategoryButtons,
+		"div.table-row":    CategoryOther,
+		"p.tagline":        CategoryOther,
+		"div.cardinal":     CategoryOther,
+		"div#product-card": CategoryCards,
+	}
+	for key, want := range cases {
+		if got := categorize(key); got != want {
+			t.Errorf("categorize(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestGenerateJSXCodeIsStable(t *testing.T) {
+	input := `<figure class="card" title="t" data-kind="k" role="group" aria-label="l">1</figure>
+<figure class="card" title="t" data-kind="k" role="group" aria-label="l">2</figure>
+<figure class="card" title="t" data-kind="k" role="group" aria-label="l">3</figure>`
+
+	first, err := AnalyzeComponents(input)
+	if err != nil {
+		t.Fatalf("AnalyzeComponents returned error: %v", err)
+	}
+	if len(first) == 0 {
+		t.Fatal("expected a suggestion")
+	}
+	if !strings.Contains(first[0].JSXCode, "<figure aria-label={ariaLabel} className={className} data-kind={dataKind}") {
+		t.Fatalf("expected attributes in sorted order, got:\n%s", first[0].JSXCode)
+	}
+
+	for i := 0; i < 20; i++ {
+		again, err := AnalyzeComponents(input)
+		if err != nil {
+			t.Fatalf("AnalyzeComponents returned error: %v", err)
+		}
+		if again[0].JSXCode != first[0].JSXCode {
+			t.Fatalf("JSX changed between runs:\n%s\nvs\n%s", first[0].JSXCode, again[0].JSXCode)
+		}
+	}
+}
+
+func TestAnalyzeComponentsSuggestsCustomElements(t *testing.T) {
+	input := `<my-card class="promo" variant="wide">1</my-card>
+<my-card class="promo" variant="wide">2</my-card>
+<my-card class="promo" variant="wide">3</my-card>`
+
+	suggestions, err := AnalyzeComponents(input)
+	if err != nil {
+		t.Fatalf("AnalyzeComponents returned error: %v", err)
+	}
+	if len(suggestions) != 1 {
+		t.Fatalf("expected one suggestion for my-card, got %+v", suggestions)
+	}
+	got := suggestions[0]
+	if got.Name != "MyCard" || got.TagName != "my-card" {
+		t.Fatalf("expected MyCard wrapping my-card, got %+v", got)
+	}
+	if !strings.Contains(got.JSXCode, "<my-card class={className} variant={variant}>") {
+		t.Fatalf("expected custom element attributes kept as-is, got:\n%s", got.JSXCode)
+	}
+}
+
+func TestAnalyzeComponentsSuggestsLandmarks(t *testing.T) {
+	input := `<body>
+<header class="site-header"><h1>Acme</h1></header>
+<nav><a href="/">Home</a><a href="/about">About</a></nav>
+<main><p>Welcome.</p></main>
+<footer><p>&copy; Acme</p></footer>
+</body>`
+
+	suggestions, err := AnalyzeComponents(input)
+	if err != nil {
+		t.Fatalf("AnalyzeComponents returned error: %v", err)
+	}
+	got := make(map[string]ComponentSuggestion)
+	for _, suggestion := range suggestions {
+		got[suggestion.TagName] = suggestion
+	}
+	if len(suggestions) != 3 {
+		t.Fatalf("expected header, nav, and footer suggestions, got %+v", suggestions)
+	}
+	want := map[string][2]string{
+		"header": {"Header", CategoryLayout},
+		"nav":    {"Navigation", CategoryNavigation},
+		"footer": {"Footer", CategoryLayout},
+	}
+	for tag, w := range want {
+		suggestion, ok := got[tag]
+		if !ok {
+			t.Fatalf("expected a %s suggestion, got %+v", tag, suggestions)
+		}
+		if suggestion.Name != w[0] || suggestion.Category != w[1] || suggestion.Count != 1 {
+			t.Fatalf("expected %s to be suggested once as %s in %s, got %+v", tag, w[0], w[1], suggestion)
+		}
+	}
+}
+
+func TestAnalyzeComponentsNamesLandmarksSharingATag(t *testing.T) {
+	input := `<body>
+<header class="site-header"><h1>Acme</h1></header>
+<nav class="main-nav"><a href="/">Home</a></nav>
+<article><header class="post-header"><h2>News</h2></header>
+<nav class="primary"><a href="/a">A</a></nav></article>
+</body>`
+
+	suggestions, err := AnalyzeComponents(input)
+	if err != nil {
+		t.Fatalf("AnalyzeComponents returned error: %v", err)
+	}
+	names := make(map[string]bool)
+	for _, suggestion := range suggestions {
+		if names[suggestion.Name] {
+			t.Fatalf("duplicate suggestion name %s in %+v", suggestion.Name, suggestions)
+		}
+		names[suggestion.Name] = true
+		if !strings.Contains(suggestion.JSXCode, "const "+suggestion.Name+" = ") {
+			t.Fatalf("expected the JSX to define %s, got:\n%s", suggestion.Name, suggestion.JSXCode)
+		}
+	}
+	for _, want := range []string{"SiteHeader", "PostHeader", "MainNav", "PrimaryNavigation", "Article"} {
+		if !names[want] {
+			t.Fatalf("expected a %s suggestion, got %v", want, names)
+		}
+	}
+}
+
+func TestAnalyzeComponentsSkipsSVGInternals(t *testing.T) {
+	icon := `<svg class="icon" viewBox="0 0 24 24"><path class="tag-path" d="M0 0h24v24H0z"></path><circle class="badge-dot" r="2"></circle></svg>`
+	input := `<main>
+<figure class="card">` + icon + `one</figure>
+<figure class="card">` + icon + `two</figure>
+<figure class="card">` + icon + `three</figure>
+</main><script>document.write('<span class="btn">x</span>')</script>`
+
+	suggestions, err := AnalyzeComponents(input)
+	if err != nil {
+		t.Fatalf("AnalyzeComponents returned error: %v", err)
+	}
+	if len(suggestions) != 1 || suggestions[0].TagName != "figure" {
+		t.Fatalf("expected only the card suggestion, got %+v", suggestions)
+	}
+
+	stats, err := GetPatternStats(input)
+	if err != nil {
+		t.Fatalf("GetPatternStats returned error: %v", err)
+	}
+	for _, stat := range stats {
+		switch stat.TagName {
+		case "svg", "path", "circle", "script":
+			t.Fatalf("expected %s to be skipped, got pattern %q", stat.TagName, stat.Key)
+		}
+	}
+}
+
+func TestGenerateJSXCodeTurnsDifferingTextIntoProps(t *testing.T) {
+	doc, err := html.Parse(strings.NewReader(`<main>
+<div class="card"><h3>First card</h3><p>Shared blurb</p><span class="price">$5</span></div>
+<div class="card"><h3>Second card</h3><p>Shared blurb</p><span class="price">$5</span></div>
+</main>`))
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+	patterns := make(map[string]*ElementPattern)
+	collectPatterns(doc, patterns, Exclusions{})
+	pattern := patterns["div.card"]
+
+	slots := findTextSlots(pattern.Examples, []string{"className"})
+	if len(slots) != 1 || slots[0].prop != "title" {
+		t.Fatalf("expected a title slot only, got %+v", slots)
+	}
+
+	code := generateJSXCode(pattern, slots)
+	for _, want := range []string{
+		"const DivCard = ({ className, title }) => {",
+		"\t\t\t<h3>{title}</h3>\n",
+		"\t\t\t<p>Shared blurb</p>\n",
+		"\t\t\t<span className=\"price\">$5</span>\n",
+	} {
+		if !strings.Contains(code, want) {
+			t.Fatalf("expected %q in generated JSX, got:\n%s", want, code)
+		}
+	}
+	if strings.Contains(code, "children") {
+		t.Fatalf("expected text props instead of children, got:\n%s", code)
+	}
+}
+
+func TestTextPropsAvoidReservedWords(t *testing.T) {
+	doc, err := html.Parse(strings.NewReader(`<main>
+<div class="card"><h3 class="default">First card</h3><span class="new">Yes</span></div>
+<div class="card"><h3 class="default">Second card</h3><span class="new">No</span></div>
+</main>`))
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+	patterns := make(map[string]*ElementPattern)
+	collectPatterns(doc, patterns, Exclusions{})
+	pattern := patterns["div.card"]
+
+	slots := findTextSlots(pattern.Examples, []string{"className"})
+	if len(slots) != 2 || slots[0].prop != "title" || slots[1].prop != "text" {
+		t.Fatalf("expected title and text slots, got %+v", slots)
+	}
+	if code := generateJSXCode(pattern, slots); !strings.Contains(code, "({ className, title, text })") {
+		t.Fatalf("expected reserved class names to fall back, got:\n%s", code)
+	}
+}
+
+func TestGenerateJSXCodeWithoutSlotsRendersChildrenOnly(t *testing.T) {
+	doc, err := html.Parse(strings.NewReader(`<main>
+<div class="card"><h3 tabindex="0">First {card}</h3><p style="color: red">Blurb</p></div>
+<div class="card"><h3 tabindex="0">Second {card}</h3><p style="color: red">Blurb</p></div>
+</main>`))
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+	patterns := make(map[string]*ElementPattern)
+	collectPatterns(doc, patterns, Exclusions{})
+	pattern := patterns["div.card"]
+
+	slots := findTextSlots(pattern.Examples, []string{"className"})
+	if slots != nil {
+		t.Fatalf("expected no slots for content with tabindex and style, got %+v", slots)
+	}
+	code := generateJSXCode(pattern, slots)
+	if !strings.Contains(code, "\t\t\t{children}\n") {
+		t.Fatalf("expected the content as children, got:\n%s", code)
+	}
+	for _, unwanted := range []string{"<h3", "tabindex", "style=", "Blurb"} {
+		if strings.Contains(code, unwanted) {
+			t.Fatalf("expected no example markup (%q) beside children, got:\n%s", unwanted, code)
+		}
+	}
+}
+
+func TestAnalyzeComponentsSkipsExcludedElements(t *testing.T) {
+	input := `<main>
+<figure class="card promo">1</figure><figure class="card promo">2</figure><figure class="card promo">3</figure>
+<div class="ad-slot"><figure class="card">a</figure><figure class="card">b</figure><figure class="card">c</figure></div>
+<span class="badge sr-only">x</span><span class="badge sr-only">y</span><span class="badge sr-only">z</span>
+</main>`
+
+	suggestions, err := AnalyzeComponents(input)
+	if err != nil {
+		t.Fatalf("AnalyzeComponents returned error: %v", err)
+	}
+	if len(suggestions) != 1 || suggestions[0].Count != 3 || suggestions[0].TagName != "figure" {
+		t.Fatalf("expected only the promo cards outside the ad slot, got %+v", suggestions)
+	}
+
+	suggestions, err = AnalyzeComponentsWithOptions(input, AnalyzeOptions{Exclude: Exclusions{Classes: []string{"promo"}}})
+	if err != nil {
+		t.Fatalf("AnalyzeComponentsWithOptions returned error: %v", err)
+	}
+	if len(suggestions) != 0 {
+		t.Fatalf("expected promo cards to be excluded too, got %+v", suggestions)
+	}
+}
+
+func TestExclusionsMatchWholeClassParts(t *testing.T) {
+	for class, want := range map[string]bool{
+		"ad": true, "ad-slot": true, "top-ad": true, "sr-only": true,
+		"header": false, "badge": false, "add-to-cart": false, "shadow": false,
+	} {
+		n := &html.Node{Type: html.ElementNode, Data: "div", Attr: []html.Attribute{{Key: "class", Val: class}}}
+		if got := (Exclusions{}).Matches(n); got != want {
+			t.Fatalf("class %q: expected excluded=%v, got %v", class, want, got)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package analyzer
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Exclusions selects elements that never become components or EJS partials.
+// Everything inside an excluded element is skipped too. The zero value
+// excludes DefaultExclusions.
+type Exclusions struct {
+	// Tags are tag names, such as "iframe".
+	Tags []string `json:"tags,omitempty"`
+	// Classes match a class that is the value or contains it as
+	// hyphen-separated parts: "ad" matches "ad", "ad-slot", and "top-ad" but
+	// not "header" or "add-to-cart".
+	Classes []string `json:"classes,omitempty"`
+	// DataAttributes are attribute names, such as "data-ad", whose presence
+	// excludes an element.
+	DataAttributes []string `json:"dataAttributes,omitempty"`
+	// NoDefaults drops DefaultExclusions instead of adding to them.
+	NoDefaults bool `json:"noDefaults,omitempty"`
+}
+
+// DefaultExclusions covers screen-reader-only helpers and ad and tracking
+// containers.
+var DefaultExclusions = Exclusions{
+	Classes:        []string{"sr-only", "visually-hidden", "ad", "ads", "advert", "tracking"},
+	DataAttributes: []string{"data-ad", "data-tracking"},
+}
+
+// Matches reports whether n is excluded.
+func (e Exclusions) Matches(n *html.Node) bool {
+	if n.Type != html.ElementNode {
+		return false
+	}
+	if !e.NoDefaults && DefaultExclusions.matches(n) {
+		return true
+	}
+	return e.matches(n)
+}
+
+func (e Exclusions) matches(n *html.Node) bool {
+	for _, tag := range e.Tags {
+		if strings.EqualFold(n.Data, tag) {
+			return true
+		}
+	}
+	for _, attr := range n.Attr {
+		for _, name := range e.DataAttributes {
+			if strings.EqualFold(attr.Key, name) {
+				return true
+			}
+		}
+	}
+	for _, class := range strings.Fields(strings.ToLower(getAttributeValue(n, "class"))) {
+		for _, excluded := range e.Classes {
+			if containsParts(class, strings.ToLower(excluded)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// containsParts reports whether the hyphen-separated parts of value appear as
+// a run in those of class.
+func containsParts(class, value string) bool {
+	if value == "" {
+		return false
+	}
+	return strings.Contains("-"+class+"-", "-"+value+"-")
+}
+
+// AnalyzeOptions tunes AnalyzeComponentsWithOptions. The zero value matches
+// AnalyzeComponents.
+type AnalyzeOptions struct {
+	// Exclude lists elements that are never suggested as components.
+	Exclude Exclusions
+}
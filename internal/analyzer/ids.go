@@ -0,0 +1,43 @@
+package analyzer
+
+import (
+	"fmt"
+
+	"github.com/omariomari2/uncluster/internal/htmlparse"
+	"golang.org/x/net/html"
+)
+
+// DuplicateIDs returns each id attribute value that more than one element of
+// htmlInput uses, with the number of elements using it. Duplicate ids are
+// common in pasted markup and make id selectors and getElementById match only
+// the first element; the HTML itself is left alone. The map is empty when
+// every id is unique.
+func DuplicateIDs(htmlInput string) (map[string]int, error) {
+	doc, err := htmlparse.Parse(htmlInput)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse HTML: %w", err)
+	}
+
+	counts := make(map[string]int)
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			for _, attr := range n.Attr {
+				if attr.Namespace == "" && attr.Key == "id" && attr.Val != "" {
+					counts[attr.Val]++
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+
+	for id, count := range counts {
+		if count < 2 {
+			delete(counts, id)
+		}
+	}
+	return counts, nil
+}
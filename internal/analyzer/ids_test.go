@@ -0,0 +1,17 @@
+package analyzer
+
+import "testing"
+
+func TestDuplicateIDs(t *testing.T) {
+	input := `<section id="hero"><h1 id="title">A</h1></section>
+<section id="hero"><h1 id="subtitle">B</h1></section>
+<div id="hero"></div><p id="">no id</p><p id="">no id</p>`
+
+	duplicates, err := DuplicateIDs(input)
+	if err != nil {
+		t.Fatalf("DuplicateIDs returned error: %v", err)
+	}
+	if len(duplicates) != 1 || duplicates["hero"] != 3 {
+		t.Fatalf("expected only hero, used 3 times, got %v", duplicates)
+	}
+}
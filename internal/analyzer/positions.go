@@ -0,0 +1,77 @@
+package analyzer
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+)
+
+// sourcePosition is the 1-based line and column of an element's opening tag
+// in the original input. Columns count characters, not bytes.
+type sourcePosition struct {
+	Line   int
+	Column int
+}
+
+// maxSkippedTags bounds how far locateElements looks ahead for an element's tag.
+const maxSkippedTags = 3
+
+type tagOffset struct {
+	name   string
+	offset int
+}
+
+// locateElements maps parsed element nodes back to the position of their
+// opening tag in htmlInput. html.Parse does not keep offsets, so the input is
+// tokenized separately and start tags are matched to elements in document
+// order by tag name. Elements the parser inserted on its own (an implied
+// <tbody>, for example) have no entry.
+func locateElements(htmlInput string, doc *html.Node) map[*html.Node]sourcePosition {
+	var tags []tagOffset
+	z := html.NewTokenizer(strings.NewReader(htmlInput))
+	offset := 0
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			break
+		}
+		raw := len(z.Raw())
+		if tt == html.StartTagToken || tt == html.SelfClosingTagToken {
+			name, _ := z.TagName()
+			tags = append(tags, tagOffset{name: strings.ToLower(string(name)), offset: offset})
+		}
+		offset += raw
+	}
+
+	positions := make(map[*html.Node]sourcePosition)
+	next := 0
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			// Look a few tags ahead to step over start tags the parser
+			// dropped (a second <body>, say). An element with no matching
+			// tag nearby was implied by the parser and gets no position.
+			for i := next; i < len(tags) && i <= next+maxSkippedTags; i++ {
+				if tags[i].name == n.Data {
+					positions[n] = offsetToPosition(htmlInput, tags[i].offset)
+					next = i + 1
+					break
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+
+	return positions
+}
+
+func offsetToPosition(input string, offset int) sourcePosition {
+	before := input[:offset]
+	line := strings.Count(before, "\n") + 1
+	lineStart := strings.LastIndex(before, "\n") + 1
+	return sourcePosition{Line: line, Column: utf8.RuneCountInString(before[lineStart:]) + 1}
+}
@@ -0,0 +1,61 @@
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/omariomari2/uncluster/internal/htmlparse"
+)
+
+// PatternStat is the raw data the analyzer collects for one element pattern
+// (a tag plus its class and id), before any suggestion filtering.
+type PatternStat struct {
+	Key        string         `json:"key"`
+	TagName    string         `json:"tagName"`
+	Count      int            `json:"count"`
+	Attributes map[string]int `json:"attributes"`
+	Children   map[string]int `json:"children"`
+	// Suggested reports whether AnalyzeComponents turns the pattern into a
+	// component suggestion.
+	Suggested bool `json:"suggested"`
+}
+
+// GetPatternStats returns every element pattern found in htmlInput, most
+// frequent first, leaving out DefaultExclusions as AnalyzeComponents does. It
+// is a cheap diagnostic for tuning the suggestion thresholds and never calls
+// an AI service.
+func GetPatternStats(htmlInput string) ([]PatternStat, error) {
+	doc, err := htmlparse.Parse(htmlInput)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse HTML: %w", err)
+	}
+
+	patterns := make(map[string]*ElementPattern)
+	collectPatterns(doc, patterns, Exclusions{})
+
+	suggested := make(map[string]bool)
+	for _, suggestion := range generateSuggestionsWithoutAI(patterns) {
+		suggested[suggestion.patternKey] = true
+	}
+
+	stats := make([]PatternStat, 0, len(patterns))
+	for key, pattern := range patterns {
+		stats = append(stats, PatternStat{
+			Key:        key,
+			TagName:    pattern.TagName,
+			Count:      pattern.Count,
+			Attributes: pattern.Attributes,
+			Children:   pattern.Children,
+			Suggested:  suggested[key],
+		})
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Count != stats[j].Count {
+			return stats[i].Count > stats[j].Count
+		}
+		return stats[i].Key < stats[j].Key
+	})
+
+	return stats, nil
+}
@@ -0,0 +1,35 @@
+package analyzer
+
+import "testing"
+
+func TestGetPatternStats(t *testing.T) {
+	input := `<main>
+<figure class="card"><img src="a.png"></figure>
+<figure class="card"><img src="b.png"></figure>
+<figure class="card"><img src="c.png"></figure>
+</main>`
+
+	stats, err := GetPatternStats(input)
+	if err != nil {
+		t.Fatalf("GetPatternStats returned error: %v", err)
+	}
+
+	byKey := make(map[string]PatternStat)
+	for _, stat := range stats {
+		byKey[stat.Key] = stat
+	}
+
+	card, ok := byKey["figure.card"]
+	if !ok {
+		t.Fatalf("expected figure.card in %+v", stats)
+	}
+	if card.Count != 3 || card.Attributes["class"] != 3 || card.Children["img"] != 3 || !card.Suggested {
+		t.Fatalf("unexpected figure.card stat: %+v", card)
+	}
+	if byKey["main"].Suggested {
+		t.Fatalf("main should not be marked as suggested")
+	}
+	if stats[0].Count < stats[len(stats)-1].Count {
+		t.Fatalf("expected stats sorted by count, got %+v", stats)
+	}
+}
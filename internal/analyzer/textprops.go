@@ -0,0 +1,314 @@
+package analyzer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/omariomari2/uncluster/internal/jsxtext"
+	"golang.org/x/net/html"
+)
+
+// textSlot is a piece of text that differs between a pattern's occurrences
+// and becomes a prop of the suggested component. path indexes contentNodes
+// from the occurrence down to the text node.
+type textSlot struct {
+	prop string
+	path []int
+}
+
+// contentNodes returns n's element and non-whitespace text children, the
+// nodes that are aligned between occurrences.
+func contentNodes(n *html.Node) []*html.Node {
+	var nodes []*html.Node
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		switch c.Type {
+		case html.ElementNode:
+			nodes = append(nodes, c)
+		case html.TextNode:
+			if strings.TrimSpace(c.Data) != "" {
+				nodes = append(nodes, c)
+			}
+		}
+	}
+	return nodes
+}
+
+// sameChildren reports whether a and b have aligned content: the same
+// elements with the same attributes, and text in the same places. Only the
+// text itself may differ.
+func sameChildren(a, b *html.Node) bool {
+	as, bs := contentNodes(a), contentNodes(b)
+	if len(as) != len(bs) {
+		return false
+	}
+	for i := range as {
+		if as[i].Type != bs[i].Type {
+			return false
+		}
+		if as[i].Type != html.ElementNode {
+			continue
+		}
+		if as[i].Data != bs[i].Data || !sameAttributes(as[i], bs[i]) || !sameChildren(as[i], bs[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func sameAttributes(a, b *html.Node) bool {
+	if len(a.Attr) != len(b.Attr) {
+		return false
+	}
+	for i := range a.Attr {
+		if a.Attr[i] != b.Attr[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// findTextSlots aligns the pattern's examples and returns a slot for each
+// text position whose content differs between them. It returns nil when the
+// examples don't share their inner structure, when an inner element has an
+// attribute the generated JSX can't carry as-is, or when all text matches;
+// the component then takes its content as children. taken holds the prop
+// names already in use.
+func findTextSlots(examples []*html.Node, taken []string) []textSlot {
+	if len(examples) < 2 {
+		return nil
+	}
+	for _, example := range examples[1:] {
+		if !sameChildren(examples[0], example) {
+			return nil
+		}
+	}
+	if !staticJSXAttributes(examples[0]) {
+		return nil
+	}
+
+	used := map[string]bool{"children": true}
+	for _, prop := range taken {
+		used[prop] = true
+	}
+
+	var slots []textSlot
+	var walk func(nodes [][]*html.Node, path []int)
+	walk = func(nodes [][]*html.Node, path []int) {
+		for i, n := range nodes[0] {
+			childPath := append(append([]int(nil), path...), i)
+			if n.Type == html.ElementNode {
+				children := make([][]*html.Node, len(nodes))
+				for j := range nodes {
+					children[j] = contentNodes(nodes[j][i])
+				}
+				walk(children, childPath)
+				continue
+			}
+			for j := range nodes[1:] {
+				if collapseSpace(nodes[j+1][i].Data) != collapseSpace(n.Data) {
+					slots = append(slots, textSlot{prop: uniqueProp(slotPropName(n.Parent, len(path) == 0), used), path: childPath})
+					break
+				}
+			}
+		}
+	}
+
+	roots := make([][]*html.Node, len(examples))
+	for i, example := range examples {
+		roots[i] = contentNodes(example)
+	}
+	walk(roots, nil)
+	return slots
+}
+
+// staticJSXAttributes reports whether every element below n has attributes
+// that can be written into JSX unchanged, apart from class and for. Anything
+// else, such as an inline style, an event handler, tabindex, or a boolean
+// attribute, would need converting.
+func staticJSXAttributes(n *html.Node) bool {
+	for _, c := range contentNodes(n) {
+		if c.Type != html.ElementNode {
+			continue
+		}
+		for _, attr := range c.Attr {
+			if attr.Namespace != "" || !staticJSXAttribute(attr.Key) {
+				return false
+			}
+		}
+		if !staticJSXAttributes(c) {
+			return false
+		}
+	}
+	return true
+}
+
+// jsxSameAttributes are the HTML attributes JSX spells the same way and
+// takes as strings.
+var jsxSameAttributes = map[string]bool{
+	"id": true, "href": true, "src": true, "alt": true, "title": true,
+	"type": true, "name": true, "role": true, "rel": true, "target": true,
+	"width": true, "height": true, "lang": true, "dir": true, "label": true,
+	"loading": true, "decoding": true, "sizes": true, "placeholder": true,
+}
+
+func staticJSXAttribute(key string) bool {
+	_, renamed := jsxAttributeNames[key]
+	return renamed || jsxSameAttributes[key] || strings.HasPrefix(key, "data-") || strings.HasPrefix(key, "aria-")
+}
+
+// slotPropName names the prop for text inside parent: after parent's first
+// class when it has one, "title" in headings, "description" in paragraphs,
+// and "label" for text directly inside the component's root.
+func slotPropName(parent *html.Node, root bool) string {
+	switch {
+	case root:
+		return "label"
+	case len(strings.Fields(getAttributeValue(parent, "class"))) > 0:
+		name := kebabToCamel(strings.Fields(getAttributeValue(parent, "class"))[0])
+		if isIdentifier(name) && !jsReservedWords[name] {
+			return name
+		}
+	}
+	switch parent.Data {
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		return "title"
+	case "p":
+		return "description"
+	default:
+		return "text"
+	}
+}
+
+func uniqueProp(name string, used map[string]bool) string {
+	unique := name
+	for i := 2; used[unique]; i++ {
+		unique = name + strconv.Itoa(i)
+	}
+	used[unique] = true
+	return unique
+}
+
+// jsReservedWords cannot be bound as a destructured prop in a module, so a
+// class named after one (class="new") falls back to the element-based name.
+var jsReservedWords = map[string]bool{
+	"arguments": true, "await": true, "break": true, "case": true, "catch": true,
+	"class": true, "const": true, "continue": true, "debugger": true, "default": true,
+	"delete": true, "do": true, "else": true, "enum": true, "eval": true,
+	"export": true, "extends": true, "false": true, "finally": true, "for": true,
+	"function": true, "if": true, "implements": true, "import": true, "in": true,
+	"instanceof": true, "interface": true, "let": true, "new": true, "null": true,
+	"package": true, "private": true, "protected": true, "public": true, "return": true,
+	"static": true, "super": true, "switch": true, "this": true, "throw": true,
+	"true": true, "try": true, "typeof": true, "var": true, "void": true,
+	"while": true, "with": true, "yield": true,
+}
+
+func isIdentifier(s string) bool {
+	for i, r := range s {
+		if !(r == '_' || r == '$' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || i > 0 && r >= '0' && r <= '9') {
+			return false
+		}
+	}
+	return s != ""
+}
+
+func collapseSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func slotKey(path []int) string {
+	return fmt.Sprint(path)
+}
+
+// TextValues returns the text occurrence n passes for each of the
+// suggestion's TextProps. ok is false when n's inner structure differs from
+// the analyzed examples, so it cannot be expressed as a usage of the
+// component.
+func (s ComponentSuggestion) TextValues(n *html.Node) (values map[string]string, ok bool) {
+	if len(s.slots) == 0 {
+		return nil, true
+	}
+	if !sameChildren(s.shape, n) {
+		return nil, false
+	}
+
+	values = make(map[string]string, len(s.slots))
+	for _, slot := range s.slots {
+		node := n
+		for _, i := range slot.path {
+			node = contentNodes(node)[i]
+		}
+		values[slot.prop] = collapseSpace(node.Data)
+	}
+	return values, true
+}
+
+// jsxAttributeNames maps the HTML attributes whose JSX name differs.
+var jsxAttributeNames = map[string]string{"class": "className", "for": "htmlFor"}
+
+// writeSlottedJSX writes n as JSX, replacing slotted text with the prop that
+// carries it. path is n's position in the occurrence.
+func writeSlottedJSX(buf *strings.Builder, n *html.Node, path []int, slots map[string]string) {
+	if n.Type == html.TextNode {
+		text := collapseSpace(n.Data)
+		if prop, ok := slots[slotKey(path)]; ok {
+			text = "{" + prop + "}"
+		} else {
+			text = jsxtext.Escape(text)
+		}
+		// Keep the spaces that separate the text from inline siblings.
+		if strings.TrimLeftFunc(n.Data, unicode.IsSpace) != n.Data {
+			text = " " + text
+		}
+		if strings.TrimRightFunc(n.Data, unicode.IsSpace) != n.Data {
+			text += " "
+		}
+		buf.WriteString(text)
+		return
+	}
+
+	buf.WriteString("<" + n.Data)
+	for _, attr := range n.Attr {
+		key := attr.Key
+		if name, ok := jsxAttributeNames[key]; ok && !isCustomElement(n.Data) {
+			key = name
+		}
+		buf.WriteString(fmt.Sprintf(" %s=\"%s\"", key, html.EscapeString(attr.Val)))
+	}
+	if isVoidElement(n.Data) {
+		buf.WriteString(" />")
+		return
+	}
+	buf.WriteString(">")
+	for i, c := range contentNodes(n) {
+		writeSlottedJSX(buf, c, append(append([]int(nil), path...), i), slots)
+	}
+	buf.WriteString("</" + n.Data + ">")
+}
+
+// writeSlottedContent writes the content of root, the first example, inside
+// the component's root element. Block content gets a line per child; content
+// with text at the top level stays on one line so JSX keeps its spacing.
+func writeSlottedContent(buf *strings.Builder, root *html.Node, slots map[string]string) {
+	children := contentNodes(root)
+	inline := false
+	for _, c := range children {
+		inline = inline || c.Type == html.TextNode
+	}
+
+	if inline {
+		var line strings.Builder
+		for i, c := range children {
+			writeSlottedJSX(&line, c, []int{i}, slots)
+		}
+		buf.WriteString("\t\t\t" + strings.TrimSpace(line.String()) + "\n")
+		return
+	}
+	for i, c := range children {
+		buf.WriteString("\t\t\t")
+		writeSlottedJSX(buf, c, []int{i}, slots)
+		buf.WriteString("\n")
+	}
+}
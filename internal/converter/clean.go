@@ -0,0 +1,54 @@
+package converter
+
+import (
+	"path"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// DefaultStripAttributes are the attributes Options.Clean removes: ones that
+// editors and browser extensions add to a page and that mean nothing to the
+// site itself. Framework attributes such as data-wf-* and data-v-* are not
+// included, since scripts and scoped styles can depend on them; list them in
+// Options.StripAttributes to drop them too.
+var DefaultStripAttributes = []string{
+	"contenteditable",
+	"data-gramm",
+	"data-gramm_editor",
+	"data-gr-*",
+	"data-new-gr-c-s-check-loaded",
+	"data-lt-installed",
+}
+
+// emptyDroppedAttributes are removed by Options.Clean when they have no value.
+var emptyDroppedAttributes = map[string]bool{"style": true, "class": true}
+
+// stripAttributes removes the attributes matching one of patterns from every
+// element under n, and empty style and class attributes when dropEmpty is set.
+// patterns use path.Match syntax and are lowercase.
+func stripAttributes(n *html.Node, patterns []string, dropEmpty bool) {
+	if n.Type == html.ElementNode {
+		attrs := n.Attr[:0]
+		for _, attr := range n.Attr {
+			if matchesAttributePattern(attr.Key, patterns) || dropEmpty && emptyDroppedAttributes[attr.Key] && strings.TrimSpace(attr.Val) == "" {
+				continue
+			}
+			attrs = append(attrs, attr)
+		}
+		n.Attr = attrs
+	}
+
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		stripAttributes(c, patterns, dropEmpty)
+	}
+}
+
+func matchesAttributePattern(key string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, key); ok {
+			return true
+		}
+	}
+	return false
+}
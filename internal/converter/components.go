@@ -0,0 +1,353 @@
+package converter
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/omariomari2/uncluster/internal/analyzer"
+	"golang.org/x/net/html"
+)
+
+// GeneratedComponent is one reusable component extracted from a page.
+type GeneratedComponent struct {
+	Name     string `json:"name"`     // PascalCase component name, unique within one conversion
+	Filename string `json:"filename"` // Name plus the source extension, e.g. "FigureCard.tsx"
+	Code     string `json:"code"`     // complete module source with a default export
+
+	// StyleModule is the source of Name + ".module.css", which Code imports,
+	// when the cssModules style strategy moved any styles out of the markup.
+	StyleModule string `json:"styleModule,omitempty"`
+}
+
+// Componentization is a page converted in one pass: a component per analyzer
+// suggestion, the suggestions, and the main component, which renders the rest
+// of the page and imports the components for the markup they replace.
+type Componentization struct {
+	Main        GeneratedComponent             `json:"main"`
+	Components  []GeneratedComponent           `json:"components"`
+	Suggestions []analyzer.ComponentSuggestion `json:"suggestions"`
+}
+
+// Componentize returns the suggested components (as ConvertToComponents
+// would) and a main component that uses them: every occurrence of a suggested
+// pattern becomes a usage such as
+// <NavItem className="nav-item" href="/">Home</NavItem>, passing the
+// attributes the component takes as props and the content as children. The
+// rest of the page converts as ConvertToComponent would, sharing opts. The
+// page is parsed once; the analyzer reads the tree before the converter
+// strips any attributes from it.
+func Componentize(htmlContent string, opts Options) (*Componentization, error) {
+	opts, err := opts.Normalize()
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := parseHTMLForJSX(htmlContent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse HTML: %w", err)
+	}
+	suggestions := analyzer.AnalyzeDocument(htmlContent, doc, analyzer.AnalyzeOptions{})
+
+	components := componentsFromSuggestions(suggestions, opts)
+	usages := make([]componentUsage, len(components))
+	for i := range components {
+		usages[i] = componentUsage{suggestion: suggestions[i], name: components[i].Name}
+	}
+
+	main, err := convertToComponent(doc, "", "", nil, nil, opts, usages)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Componentization{
+		Main:        main,
+		Components:  components,
+		Suggestions: suggestions,
+	}, nil
+}
+
+// componentUsage pairs a suggestion with the name of the component generated
+// for it.
+type componentUsage struct {
+	suggestion analyzer.ComponentSuggestion
+	name       string
+}
+
+// usageFor returns the usage whose pattern n is an occurrence of, or nil.
+// An occurrence whose content doesn't line up with a component's text props
+// is converted as plain markup instead.
+func (c *JSXConverter) usageFor(n *html.Node) *componentUsage {
+	for i := range c.usages {
+		if !c.usages[i].suggestion.Matches(n) {
+			continue
+		}
+		if _, ok := c.usages[i].suggestion.TextValues(n); ok {
+			return &c.usages[i]
+		}
+	}
+	return nil
+}
+
+// renderComponentUsage renders n as a usage of the component. Attributes the
+// component takes become props; others are dropped, since the component does
+// not render them. When the component has text props, n's text is passed
+// through them; otherwise children are converted as usual, so nested
+// occurrences of other patterns become usages too.
+func (c *JSXConverter) renderComponentUsage(buf *strings.Builder, n *html.Node, usage *componentUsage) {
+	used := false
+	for _, name := range c.usedComponents {
+		used = used || name == usage.name
+	}
+	if !used {
+		c.usedComponents = append(c.usedComponents, usage.name)
+	}
+
+	buf.WriteString("<" + usage.name)
+	for _, attr := range n.Attr {
+		if _, ok := usage.suggestion.Attributes[attr.Key]; !ok || attr.Namespace != "" {
+			continue
+		}
+		val := `"` + html.EscapeString(attr.Val) + `"`
+		if attr.Key == "style" {
+			val = c.convertStyleToObject(attr.Val)
+		}
+		buf.WriteString(fmt.Sprintf(" %s=%s", analyzer.PropName(attr.Key), val))
+	}
+
+	if values, _ := usage.suggestion.TextValues(n); values != nil {
+		for _, prop := range usage.suggestion.TextProps {
+			buf.WriteString(fmt.Sprintf(` %s="%s"`, prop, html.EscapeString(values[prop])))
+		}
+		buf.WriteString(" />")
+		return
+	}
+
+	if n.FirstChild == nil || voidElements[n.Data] {
+		buf.WriteString(" />")
+		return
+	}
+	buf.WriteString(">")
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		c.renderNodeAsJSX(buf, child)
+	}
+	buf.WriteString("</" + usage.name + ">")
+}
+
+// ConvertToComponents turns the analyzer's component suggestions into named
+// component modules, most frequent first. Names are PascalCase and
+// de-duplicated. opts.Language selects .tsx ("ts") or .jsx ("js") output and
+// opts.Indent the indentation; the other options do not apply.
+func ConvertToComponents(htmlContent string, opts Options) ([]GeneratedComponent, error) {
+	opts, err := opts.Normalize()
+	if err != nil {
+		return nil, err
+	}
+
+	suggestions, err := analyzer.AnalyzeComponents(htmlContent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to analyze HTML: %w", err)
+	}
+
+	return componentsFromSuggestions(suggestions, opts), nil
+}
+
+// componentsFromSuggestions generates a component per suggestion, sorting
+// suggestions most frequent first. opts must be normalized.
+func componentsFromSuggestions(suggestions []analyzer.ComponentSuggestion, opts Options) []GeneratedComponent {
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		if suggestions[i].Count != suggestions[j].Count {
+			return suggestions[i].Count > suggestions[j].Count
+		}
+		return suggestions[i].Name < suggestions[j].Name
+	})
+
+	typescript := opts.Language == "ts"
+	ext := ".jsx"
+	if typescript {
+		ext = ".tsx"
+	}
+
+	used := make(map[string]bool)
+	var components []GeneratedComponent
+	for _, suggestion := range suggestions {
+		base := componentIdentifier(suggestion.Name)
+		name := base
+		for i := 2; used[name]; i++ {
+			name = fmt.Sprintf("%s%d", base, i)
+		}
+		used[name] = true
+
+		var code string
+		if suggestion.JSXCode != "" {
+			code = strings.ReplaceAll(suggestion.JSXCode, suggestion.Name, name)
+			if typescript {
+				code = propsInterface(name, suggestion) + "\n" + strings.Replace(code, " }) => {", " }: "+name+"Props) => {", 1)
+			}
+			code = "import React from 'react'\n\n" + code + "\n"
+		} else {
+			code = placeholderComponent(name, suggestion, typescript)
+		}
+
+		components = append(components, GeneratedComponent{
+			Name:     name,
+			Filename: name + ext,
+			Code:     reindent(code, indentUnit(opts.Indent)),
+		})
+	}
+
+	return components
+}
+
+// propsInterface declares the props of the component generated from
+// suggestion, in the order its code destructures them: the attributes it
+// takes, optional strings since not every occurrence has them (style is the
+// object a usage passes), then its text props or children.
+func propsInterface(name string, suggestion analyzer.ComponentSuggestion) string {
+	attrs := make([]string, 0, len(suggestion.Attributes))
+	for attr := range suggestion.Attributes {
+		attrs = append(attrs, attr)
+	}
+	sort.Strings(attrs)
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("interface %sProps {\n", name))
+	for _, attr := range attrs {
+		propType := "string"
+		if attr == "style" {
+			propType = "React.CSSProperties"
+		}
+		b.WriteString(fmt.Sprintf("  %s?: %s\n", analyzer.PropName(attr), propType))
+	}
+	for _, prop := range suggestion.TextProps {
+		b.WriteString(fmt.Sprintf("  %s: string\n", prop))
+	}
+	if len(suggestion.TextProps) == 0 && !voidElements[suggestion.TagName] {
+		b.WriteString("  children?: React.ReactNode\n")
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// ComponentLibrary is the components suggested for a page, for converting
+// its sections with ConvertSectionWithComponents.
+type ComponentLibrary struct {
+	Components []GeneratedComponent
+	usages     []componentUsage
+}
+
+// NewComponentLibrary generates the page's suggested components as
+// ConvertToComponents does, keeping the limit most frequent (all when limit is
+// 0). A component named like one of reserved, such as the page's main
+// component, is numbered instead.
+func NewComponentLibrary(htmlContent string, opts Options, limit int, reserved ...string) (*ComponentLibrary, error) {
+	opts, err := opts.Normalize()
+	if err != nil {
+		return nil, err
+	}
+
+	suggestions, err := analyzer.AnalyzeComponents(htmlContent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to analyze HTML: %w", err)
+	}
+
+	components := componentsFromSuggestions(suggestions, opts)
+	if limit > 0 && len(components) > limit {
+		components = components[:limit]
+	}
+
+	taken := make(map[string]bool)
+	for _, name := range reserved {
+		taken[name] = true
+	}
+	for _, component := range components {
+		taken[component.Name] = true
+	}
+
+	lib := &ComponentLibrary{}
+	for i, component := range components {
+		for _, name := range reserved {
+			if component.Name != name {
+				continue
+			}
+			base := component.Name
+			for n := 2; taken[component.Name]; n++ {
+				component = component.Renamed(fmt.Sprintf("%s%d", base, n))
+			}
+			taken[component.Name] = true
+		}
+		lib.Components = append(lib.Components, component)
+		lib.usages = append(lib.usages, componentUsage{suggestion: suggestions[i], name: component.Name})
+	}
+	return lib, nil
+}
+
+// Renamed returns a copy of the component under a new name, updating the
+// identifier in its code and its filename.
+func (g GeneratedComponent) Renamed(name string) GeneratedComponent {
+	return GeneratedComponent{
+		Name:     name,
+		Filename: name + path.Ext(g.Filename),
+		Code:     strings.ReplaceAll(g.Code, g.Name, name),
+	}
+}
+
+func placeholderComponent(name string, suggestion analyzer.ComponentSuggestion, typescript bool) string {
+	jsx := fmt.Sprintf(`<div className="%s">
+  {/* %s */}
+</div>`, suggestion.TagName, suggestion.Description)
+
+	if !typescript {
+		return fmt.Sprintf(`import React from 'react'
+
+function %s(props) {
+  return (
+    <>
+      %s
+    </>
+  )
+}
+
+export default %s
+`, name, jsx, name)
+	}
+
+	return fmt.Sprintf(`import React from 'react'
+
+interface %sProps {
+}
+
+function %s(props: %sProps) {
+  return (
+    <>
+      %s
+    </>
+  )
+}
+
+export default %s
+`, name, name, name, jsx, name)
+}
+
+// componentIdentifier turns an analyzer suggestion name into a PascalCase
+// identifier that is safe to use as both a component and a file name.
+func componentIdentifier(name string) string {
+	parts := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	var b strings.Builder
+	for _, part := range parts {
+		b.WriteString(strings.ToUpper(part[:1]))
+		b.WriteString(part[1:])
+	}
+	id := b.String()
+	if id == "" {
+		return "Component"
+	}
+	if unicode.IsDigit(rune(id[0])) {
+		id = "Component" + id
+	}
+	return id
+}
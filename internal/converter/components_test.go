@@ -0,0 +1,162 @@
+package converter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConvertToComponentsNamesAndDeduplicates(t *testing.T) {
+	input := `<main>
+<figure class="card"><img src="a.png"></figure>
+<figure class="card"><img src="b.png"></figure>
+<figure class="card"><img src="c.png"></figure>
+<figure class="card"><img src="d.png"></figure>
+<figure class="card wide"><img src="e.png"></figure>
+<figure class="card wide"><img src="f.png"></figure>
+<figure class="card wide"><img src="g.png"></figure>
+</main>`
+
+	components, err := ConvertToComponents(input, Options{Language: "ts"})
+	if err != nil {
+		t.Fatalf("ConvertToComponents returned error: %v", err)
+	}
+	if len(components) != 2 {
+		t.Fatalf("expected 2 components, got %+v", components)
+	}
+
+	first, second := components[0], components[1]
+	if first.Name != "FigureCard" || first.Filename != "FigureCard.tsx" {
+		t.Fatalf("unexpected first component: %+v", first)
+	}
+	if second.Name != "FigureCard2" || second.Filename != "FigureCard2.tsx" {
+		t.Fatalf("expected duplicate name to be suffixed, got %+v", second)
+	}
+	if !strings.Contains(second.Code, "const FigureCard2 = ") || !strings.Contains(second.Code, "export default FigureCard2;") {
+		t.Fatalf("expected code to use the deduplicated name, got:\n%s", second.Code)
+	}
+
+	flat, err := AnalyzeAndConvert(input)
+	if err != nil {
+		t.Fatalf("AnalyzeAndConvert returned error: %v", err)
+	}
+	if len(flat) != 2 || flat[0] != first.Code {
+		t.Fatalf("expected AnalyzeAndConvert to flatten ConvertToComponents")
+	}
+}
+
+func TestComponentizeReplacesOccurrencesWithUsages(t *testing.T) {
+	input := `<main><h1>Shop</h1>
+<figure class="card" data-id="1"><img class="thumbnail" src="a.png"><button class="btn">Buy</button></figure>
+<figure class="card" data-id="2"><img class="thumbnail" src="b.png"><button class="btn">Buy</button></figure>
+<figure class="card" data-id="3"><img class="thumbnail" src="c.png"><button class="btn">Buy</button></figure>
+</main>`
+
+	result, err := Componentize(input, Options{})
+	if err != nil {
+		t.Fatalf("Componentize returned error: %v", err)
+	}
+	main := result.Main.Code
+	for _, want := range []string{
+		"import FigureCard from './FigureCard'",
+		"import ButtonButton from './ButtonButton'",
+		"<h1>Shop</h1>",
+		`<FigureCard className="card" dataId="1"><ImgComponent className="thumbnail" src="a.png" /><ButtonButton className="btn">Buy</ButtonButton></FigureCard>`,
+	} {
+		if !strings.Contains(main, want) {
+			t.Fatalf("expected %s in main component, got:\n%s", want, main)
+		}
+	}
+	if strings.Contains(main, "<figure") || strings.Contains(main, "<button") {
+		t.Fatalf("expected every occurrence to be replaced, got:\n%s", main)
+	}
+
+	for _, component := range result.Components {
+		if component.Name == "FigureCard" && !strings.Contains(component.Code, "{children}") {
+			t.Fatalf("expected FigureCard to render its children, got:\n%s", component.Code)
+		}
+	}
+}
+
+func TestComponentizePassesDifferingTextAsProps(t *testing.T) {
+	input := `<main>
+<figure class="card"><h3>Alpha</h3><p>Same</p></figure>
+<figure class="card"><h3>Beta &amp; Co</h3><p>Same</p></figure>
+<figure class="card"><h3>Gamma</h3><p>Same</p></figure>
+<figure class="card"><h3>Delta</h3><p>Same</p><a href="/more">More</a></figure>
+</main>`
+
+	result, err := Componentize(input, Options{})
+	if err != nil {
+		t.Fatalf("Componentize returned error: %v", err)
+	}
+	main := result.Main.Code
+	for _, want := range []string{
+		`<FigureCard className="card" title="Alpha" />`,
+		`<FigureCard className="card" title="Beta &amp; Co" />`,
+		`<figure className="card"><h3>Delta</h3>`,
+	} {
+		if !strings.Contains(main, want) {
+			t.Fatalf("expected %s in main component, got:\n%s", want, main)
+		}
+	}
+	if len(result.Components) != 1 || !strings.Contains(result.Components[0].Code, "<h3>{title}</h3>") {
+		t.Fatalf("expected FigureCard to render its title prop, got %+v", result.Components)
+	}
+}
+
+func TestComponentizeTypesPropsAndEscapesAttributes(t *testing.T) {
+	input := `<main>
+<button class="btn" style="color: red" title='Say "hi"'>Home</button>
+<button class="btn" style="color: red" title='Say "hi"'>Shop</button>
+<button class="btn" title="Plain">Help</button>
+</main>`
+
+	result, err := Componentize(input, Options{Language: "ts"})
+	if err != nil {
+		t.Fatalf("Componentize returned error: %v", err)
+	}
+	if len(result.Components) != 1 {
+		t.Fatalf("expected one component, got %+v", result.Components)
+	}
+	code := result.Components[0].Code
+	for _, want := range []string{
+		"interface ButtonButtonProps {\n  className?: string\n  style?: React.CSSProperties\n  title?: string\n  label: string\n}",
+		"({ className, style, title, label }: ButtonButtonProps) => {",
+	} {
+		if !strings.Contains(code, want) {
+			t.Fatalf("expected %q in component, got:\n%s", want, code)
+		}
+	}
+	if strings.Contains(code, "[prop: string]") {
+		t.Fatalf("expected a props interface instead of an index signature, got:\n%s", code)
+	}
+	if main := result.Main.Code; !strings.Contains(main, `title="Say &#34;hi&#34;"`) {
+		t.Fatalf("expected the quoted title to be escaped, got:\n%s", main)
+	}
+}
+
+func TestConvertToComponentsIndent(t *testing.T) {
+	input := `<main>
+<figure class="card"><img src="a.png"></figure>
+<figure class="card"><img src="b.png"></figure>
+<figure class="card"><img src="c.png"></figure>
+</main>`
+
+	for indent, want := range map[string]string{
+		"2":   "\n  return (\n    <figure className={className}>\n      {children}\n",
+		"4":   "\n    return (\n        <figure className={className}>\n",
+		"tab": "\n\treturn (\n\t\t<figure className={className}>\n",
+	} {
+		components, err := ConvertToComponents(input, Options{Language: "ts", Indent: indent})
+		if err != nil {
+			t.Fatalf("ConvertToComponents returned error: %v", err)
+		}
+		code := components[0].Code
+		if !strings.Contains(code, want) {
+			t.Fatalf("indent %s: expected %q in component, got:\n%s", indent, want, code)
+		}
+		if indent != "tab" && strings.Contains(code, "\t") {
+			t.Fatalf("indent %s: expected no tabs, got:\n%s", indent, code)
+		}
+	}
+}
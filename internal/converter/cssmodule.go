@@ -0,0 +1,78 @@
+package converter
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// cssModule collects inline style attributes as CSS Module classes. Elements
+// with identical declarations share a class.
+type cssModule struct {
+	rules   []string
+	byStyle map[string]string
+	count   int
+}
+
+func newCSSModule() *cssModule {
+	return &cssModule{byStyle: make(map[string]string)}
+}
+
+// classFor returns the class holding the declarations in style, adding a rule
+// named after tag (div1, p2, ...) the first time they are seen. It returns ""
+// when style has no declarations.
+func (m *cssModule) classFor(tag, style string) string {
+	var decls []string
+	for _, decl := range strings.Split(style, ";") {
+		parts := strings.SplitN(decl, ":", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			continue
+		}
+		decls = append(decls, fmt.Sprintf("  %s: %s;", strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])))
+	}
+	if len(decls) == 0 {
+		return ""
+	}
+
+	body := strings.Join(decls, "\n")
+	if name, ok := m.byStyle[body]; ok {
+		return name
+	}
+
+	m.count++
+	name := fmt.Sprintf("%s%d", componentIdentifier(tag), m.count)
+	name = strings.ToLower(name[:1]) + name[1:]
+	m.byStyle[body] = name
+	m.rules = append(m.rules, fmt.Sprintf(".%s {\n%s\n}\n", name, body))
+	return name
+}
+
+func (m *cssModule) empty() bool {
+	return len(m.rules) == 0
+}
+
+// String renders the module's stylesheet.
+func (m *cssModule) String() string {
+	return strings.Join(m.rules, "\n")
+}
+
+// moduleStyleClass moves n's style attribute into the CSS Module when one is
+// being collected. It returns the attributes to render and the module class
+// to add, or n.Attr unchanged and "" otherwise.
+func (c *JSXConverter) moduleStyleClass(n *html.Node) ([]html.Attribute, string) {
+	if c.styles == nil {
+		return n.Attr, ""
+	}
+
+	var attrs []html.Attribute
+	class := ""
+	for _, attr := range n.Attr {
+		if attr.Key == "style" && attr.Namespace == "" {
+			class = c.styles.classFor(n.Data, attr.Val)
+			continue
+		}
+		attrs = append(attrs, attr)
+	}
+	return attrs, class
+}
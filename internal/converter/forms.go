@@ -0,0 +1,107 @@
+package converter
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// readOnlyValueTypes are the input types whose value is a label or the value
+// submitted, which React does not treat as a controlled value.
+var readOnlyValueTypes = map[string]bool{
+	"button": true, "checkbox": true, "hidden": true, "image": true,
+	"radio": true, "reset": true, "submit": true,
+}
+
+// normalizeFormControls keeps the page's form controls uncontrolled, so React
+// does not warn about, and lock, fields that have a value but no onChange:
+// value and checked on inputs become defaultValue and defaultChecked, a
+// textarea's text becomes its defaultValue, and the selected option of a
+// single select becomes the select's defaultValue. Inputs with an onchange
+// handler, or that are readonly or disabled, keep value and checked, which
+// React accepts on them. name, action, method, and autocomplete are left as
+// they are.
+func normalizeFormControls(n *html.Node) {
+	if n.Type == html.ElementNode && n.Namespace == "" {
+		switch n.Data {
+		case "input":
+			if !hasFormAttribute(n, "onchange") && !hasFormAttribute(n, "readonly") && !hasFormAttribute(n, "disabled") {
+				renameFormAttribute(n, "checked", "defaultChecked")
+				if !readOnlyValueTypes[strings.ToLower(strings.TrimSpace(jsxGetAttr(n, "type")))] {
+					renameFormAttribute(n, "value", "defaultValue")
+				}
+			}
+		case "textarea":
+			text := jsxTextContent(n)
+			for c := n.FirstChild; c != nil; c = n.FirstChild {
+				n.RemoveChild(c)
+			}
+			if text != "" {
+				n.Attr = append(n.Attr, html.Attribute{Key: "defaultValue", Val: text})
+			}
+		case "select":
+			if !hasFormAttribute(n, "multiple") && !hasFormAttribute(n, "value") {
+				if option := selectedOption(n); option != nil {
+					value := jsxGetAttr(option, "value")
+					if !hasFormAttribute(option, "value") {
+						value = strings.TrimSpace(jsxTextContent(option))
+					}
+					removeFormAttribute(option, "selected")
+					n.Attr = append(n.Attr, html.Attribute{Key: "defaultValue", Val: value})
+				}
+			}
+		}
+	}
+
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		normalizeFormControls(c)
+	}
+}
+
+// selectedOption returns the last option under the select n that has the
+// selected attribute, which is the one a browser shows, or nil.
+func selectedOption(n *html.Node) *html.Node {
+	var selected *html.Node
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode {
+				continue
+			}
+			if c.Data == "option" && hasFormAttribute(c, "selected") {
+				selected = c
+			} else if c.Data == "optgroup" {
+				walk(c)
+			}
+		}
+	}
+	walk(n)
+	return selected
+}
+
+func hasFormAttribute(n *html.Node, key string) bool {
+	for _, attr := range n.Attr {
+		if attr.Key == key && attr.Namespace == "" {
+			return true
+		}
+	}
+	return false
+}
+
+func renameFormAttribute(n *html.Node, from, to string) {
+	for i, attr := range n.Attr {
+		if attr.Key == from && attr.Namespace == "" {
+			n.Attr[i].Key = to
+		}
+	}
+}
+
+func removeFormAttribute(n *html.Node, key string) {
+	attrs := n.Attr[:0]
+	for _, attr := range n.Attr {
+		if attr.Key != key || attr.Namespace != "" {
+			attrs = append(attrs, attr)
+		}
+	}
+	n.Attr = attrs
+}
@@ -0,0 +1,53 @@
+package converter
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite testdata/*/expected.tsx from the current output")
+
+// TestConvertToJSXGolden converts each testdata/<case>/input.html and compares
+// the result with expected.tsx. Run with -update to regenerate the files after
+// an intended change, then review the diff.
+func TestConvertToJSXGolden(t *testing.T) {
+	inputs, err := filepath.Glob(filepath.Join("testdata", "*", "input.html"))
+	if err != nil {
+		t.Fatalf("failed to list golden cases: %v", err)
+	}
+	if len(inputs) == 0 {
+		t.Fatal("no golden cases found under testdata")
+	}
+
+	for _, input := range inputs {
+		dir := filepath.Dir(input)
+		t.Run(filepath.Base(dir), func(t *testing.T) {
+			source, err := os.ReadFile(input)
+			if err != nil {
+				t.Fatalf("failed to read input: %v", err)
+			}
+			got, err := ConvertToJSX(string(source), "", "", nil, nil)
+			if err != nil {
+				t.Fatalf("ConvertToJSX returned error: %v", err)
+			}
+
+			expectedPath := filepath.Join(dir, "expected.tsx")
+			if *update {
+				if err := os.WriteFile(expectedPath, []byte(got), 0o644); err != nil {
+					t.Fatalf("failed to write %s: %v", expectedPath, err)
+				}
+				return
+			}
+
+			want, err := os.ReadFile(expectedPath)
+			if err != nil {
+				t.Fatalf("failed to read expected output (run with -update to create it): %v", err)
+			}
+			if got != string(want) {
+				t.Errorf("output differs from %s (run with -update to accept it)\n--- got ---\n%s\n--- want ---\n%s", expectedPath, got, want)
+			}
+		})
+	}
+}
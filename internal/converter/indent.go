@@ -0,0 +1,46 @@
+package converter
+
+import "strings"
+
+// DefaultIndent is the indentation of generated components: two spaces, as in
+// the .prettierrc and .eslintrc.json of the scaffolded projects.
+const DefaultIndent = "2"
+
+// indentUnit returns the string one level of indent option indent stands for.
+func indentUnit(indent string) string {
+	switch indent {
+	case "4":
+		return "    "
+	case "tab":
+		return "\t"
+	}
+	return "  "
+}
+
+// reindent rewrites the leading whitespace of every line of code in unit.
+// The converter indents with two spaces per level, but scripts and text
+// copied from the page keep the page's own indentation, often tabs. Each
+// leading tab and each pair of spaces counts as one level; a leftover odd
+// space is kept as is.
+func reindent(code, unit string) string {
+	lines := strings.Split(code, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed == "" {
+			lines[i] = ""
+			continue
+		}
+		levels, spaces := 0, 0
+		for _, r := range line[:len(line)-len(trimmed)] {
+			if r == '\t' {
+				levels += spaces/2 + 1
+				spaces %= 2
+			} else {
+				spaces++
+			}
+		}
+		levels += spaces / 2
+		lines[i] = strings.Repeat(unit, levels) + strings.Repeat(" ", spaces%2) + trimmed
+	}
+	return strings.Join(lines, "\n")
+}
@@ -2,8 +2,10 @@ package converter
 
 import (
 	"fmt"
-	"github.com/omariomari2/uncluster/internal/analyzer"
 	"github.com/omariomari2/uncluster/internal/fetcher"
+	"github.com/omariomari2/uncluster/internal/formatter"
+	"github.com/omariomari2/uncluster/internal/jsxtext"
+	"strconv"
 	"strings"
 
 	"golang.org/x/net/html"
@@ -12,50 +14,183 @@ import (
 type JSXConverter struct {
 	ExternalCSS []fetcher.FetchedResource
 	ExternalJS  []fetcher.FetchedResource
+
+	// keepShell renders html, head, and body instead of unwrapping them.
+	keepShell bool
+	// styles collects style attributes into a CSS Module instead of inline
+	// style objects when set.
+	styles *cssModule
+	// usages are the components to render occurrences of their pattern as,
+	// and usedComponents the names actually rendered, in order.
+	usages         []componentUsage
+	usedComponents []string
+	// strip are the attribute patterns removed before rendering, and
+	// dropEmpty removes empty style and class attributes too.
+	strip     []string
+	dropEmpty bool
 }
 
 func ConvertToJSX(html, css, js string, externalCSS []fetcher.FetchedResource, externalJS []fetcher.FetchedResource) (string, error) {
+	return ConvertToJSXWithOptions(html, css, js, externalCSS, externalJS, Options{})
+}
+
+// ConvertToJSXWithOptions is ConvertToJSX with control over the component
+// name, language, wrapper element, and props generation. With the cssModules
+// style strategy use ConvertToComponent, which also returns the CSS Module.
+func ConvertToJSXWithOptions(htmlContent, css, js string, externalCSS []fetcher.FetchedResource, externalJS []fetcher.FetchedResource, opts Options) (string, error) {
+	component, err := ConvertToComponent(htmlContent, css, js, externalCSS, externalJS, opts)
+	if err != nil {
+		return "", err
+	}
+	return component.Code, nil
+}
+
+// ConvertToComponent converts htmlContent like ConvertToJSXWithOptions and
+// returns the component with its file name and, for the cssModules style
+// strategy, the CSS Module it imports.
+func ConvertToComponent(htmlContent, css, js string, externalCSS []fetcher.FetchedResource, externalJS []fetcher.FetchedResource, opts Options) (GeneratedComponent, error) {
+	opts, err := opts.Normalize()
+	if err != nil {
+		return GeneratedComponent{}, err
+	}
+	doc, err := parseHTMLForJSX(htmlContent)
+	if err != nil {
+		return GeneratedComponent{}, fmt.Errorf("failed to convert HTML to JSX: failed to parse HTML: %w", err)
+	}
+	return convertToComponent(doc, css, js, externalCSS, externalJS, opts, nil)
+}
+
+// convertToComponent implements ConvertToComponent for normalized opts and
+// doc, the page parsed with parseHTMLForJSX, rendering occurrences of the
+// usages' patterns as those components. It strips doc's attributes as opts
+// ask.
+func convertToComponent(doc *html.Node, css, js string, externalCSS []fetcher.FetchedResource, externalJS []fetcher.FetchedResource, opts Options, usages []componentUsage) (GeneratedComponent, error) {
 	converter := &JSXConverter{
 		ExternalCSS: externalCSS,
 		ExternalJS:  externalJS,
+		keepShell:   opts.KeepDocumentShell,
+		usages:      usages,
+		strip:       opts.StripAttributes,
+		dropEmpty:   opts.Clean,
+	}
+	if opts.Clean {
+		converter.strip = append(append([]string(nil), DefaultStripAttributes...), opts.StripAttributes...)
+	}
+	if opts.StyleStrategy == "cssModules" {
+		converter.styles = newCSSModule()
 	}
+	stripAttributes(doc, converter.strip, converter.dropEmpty)
 
-	jsx, err := converter.convertHTMLToJSX(html)
-	if err != nil {
-		return "", fmt.Errorf("failed to convert HTML to JSX: %w", err)
+	cssImports, stylesDecl := "", ""
+	if opts.SingleFile && !opts.StripStyles {
+		stylesDecl = inlineStylesDeclaration(css, externalCSS)
+	} else if !opts.StripStyles {
+		cssImports = converter.generateCSSImports(css)
+	}
+	typescript := opts.Language == "ts"
+	var script pageScript
+	if !opts.StripScripts {
+		script = converter.generatePageScript(js, typescript)
+		if moduleImports := converter.generateModuleImports(); moduleImports != "" {
+			cssImports = strings.TrimLeft(cssImports+"\n"+moduleImports, "\n")
+		}
+	}
+	reactImport := "import React from 'react'"
+	if script.effect != "" {
+		reactImport = "import React, { useEffect } from 'react'"
+	}
+
+	result := GeneratedComponent{Name: opts.ComponentName, Filename: opts.ComponentName + ".jsx"}
+	if typescript {
+		result.Filename = opts.ComponentName + ".tsx"
+	}
+
+	if opts.GenerateProps && !opts.KeepDocumentShell {
+		body := findBodyNode(doc)
+		if pattern := detectListPattern(body); pattern != nil {
+			component := buildListComponent(opts.ComponentName, pattern, converter, body, typescript, true, script)
+			component = strings.Replace(component, "import React from 'react'\n", reactImport+"\n"+cssImports+"\n", 1)
+			if stylesDecl != "" {
+				component = addStyleElement(component, opts.ComponentName, stylesDecl)
+			}
+			result.Code = reindent(component, indentUnit(opts.Indent))
+			return result, nil
+		}
+	}
+
+	var jsx strings.Builder
+	converter.renderNodeAsJSX(&jsx, doc)
+
+	for _, name := range converter.usedComponents {
+		cssImports = strings.TrimLeft(cssImports+"\n"+fmt.Sprintf("import %s from './%s'", name, name), "\n")
+	}
+	if converter.styles != nil && !converter.styles.empty() {
+		result.StyleModule = converter.styles.String()
+		cssImports = strings.TrimLeft(cssImports+"\n"+fmt.Sprintf("import styles from './%s.module.css'", opts.ComponentName), "\n")
+	}
+
+	openTag, closeTag := "<>", "</>"
+	switch opts.WrapperMode {
+	case "div":
+		openTag, closeTag = "<div>", "</div>"
+	case "none":
+		if countJSXRoots(doc) == 1 {
+			openTag, closeTag = "", ""
+		}
+	}
+
+	doctypeComment := ""
+	if opts.KeepDocumentShell {
+		openTag, closeTag = "", ""
+		if doctype := findDoctype(doc); doctype != "" {
+			doctypeComment = fmt.Sprintf("// Original document type: %s\n", doctype)
+		}
+	}
+
+	if stylesDecl != "" && openTag == "" {
+		openTag, closeTag = "<>", "</>"
 	}
 
-	cssImports := converter.generateCSSImports(css)
-	jsCode := converter.generateJSCode(js)
+	returnType := ""
+	if typescript {
+		returnType = ": React.JSX.Element"
+	}
 
-	component := fmt.Sprintf(`import React from 'react'
+	component := fmt.Sprintf(`%s
 %s
 
-function MainComponent() {
-  return (
-    <>
+%s%sfunction %s()%s {
+%s  return (
+    %s
       %s
-    </>
+    %s
   )
 }
 
-%s
-
-export default MainComponent
-`, cssImports, jsx, jsCode)
+export default %s
+`, reactImport, cssImports, doctypeComment, script.declarations, opts.ComponentName, returnType, script.effect, openTag, jsx.String(), closeTag, opts.ComponentName)
+	if stylesDecl != "" {
+		component = addStyleElement(component, opts.ComponentName, stylesDecl)
+	}
 
-	return component, nil
+	result.Code = reindent(component, indentUnit(opts.Indent))
+	return result, nil
 }
 
-func (c *JSXConverter) convertHTMLToJSX(htmlContent string) (string, error) {
-	doc, err := html.Parse(strings.NewReader(htmlContent))
-	if err != nil {
-		return "", fmt.Errorf("failed to parse HTML: %w", err)
+// findDoctype returns the rendered DOCTYPE of doc, or "" if it has none.
+func findDoctype(doc *html.Node) string {
+	for n := doc.FirstChild; n != nil; n = n.NextSibling {
+		if n.Type == html.DoctypeNode {
+			return formatter.Doctype(n)
+		}
 	}
+	return ""
+}
 
-	var buf strings.Builder
-	c.renderNodeAsJSX(&buf, doc)
-	return buf.String(), nil
+// countJSXRoots returns the number of top-level elements the converter will
+// emit for doc.
+func countJSXRoots(doc *html.Node) int {
+	return len(nonSkippedChildren(findBodyNode(doc)))
 }
 
 func (c *JSXConverter) renderNodeAsJSX(buf *strings.Builder, n *html.Node) {
@@ -73,6 +208,14 @@ func (c *JSXConverter) renderNodeAsJSX(buf *strings.Builder, n *html.Node) {
 	}
 }
 
+// jsxBooleanAttributes are the JSX attributes written as {true} whenever they
+// are present, whatever their value.
+var jsxBooleanAttributes = map[string]bool{
+	"checked": true, "defaultChecked": true, "disabled": true, "selected": true,
+	"readOnly": true, "required": true, "multiple": true, "autoFocus": true,
+	"noValidate": true, "formNoValidate": true,
+}
+
 var jsxAttributeMap = map[string]string{
 	// HTML
 	"class":           "className",
@@ -80,6 +223,13 @@ var jsxAttributeMap = map[string]string{
 	"tabindex":        "tabIndex",
 	"readonly":        "readOnly",
 	"maxlength":       "maxLength",
+	"minlength":       "minLength",
+	"novalidate":      "noValidate",
+	"formnovalidate":  "formNoValidate",
+	"formmethod":      "formMethod",
+	"formenctype":     "formEncType",
+	"formtarget":      "formTarget",
+	"accept-charset":  "acceptCharset",
 	"cellpadding":     "cellPadding",
 	"cellspacing":     "cellSpacing",
 	"colspan":         "colSpan",
@@ -98,6 +248,7 @@ var jsxAttributeMap = map[string]string{
 	"hreflang":        "hrefLang",
 	"inputmode":       "inputMode",
 	"usemap":          "useMap",
+	"srcset":          "srcSet",
 	// SVG presentation
 	"fill-rule":                    "fillRule",
 	"clip-rule":                    "clipRule",
@@ -217,8 +368,32 @@ var skipElements = map[string]bool{
 	"style": true, "script": true,
 }
 
+// skipsElement reports whether n is document scaffolding the converter leaves
+// out. An SVG's own title and style are part of the drawing and are kept.
+func skipsElement(n *html.Node) bool {
+	return skipElements[n.Data] && n.Namespace != "svg"
+}
+
+// selfCloses reports whether n is written as <tag />: HTML void elements, and
+// SVG elements without children such as <path d="..." />.
+func selfCloses(n *html.Node) bool {
+	return voidElements[n.Data] || (n.Namespace == "svg" && n.FirstChild == nil)
+}
+
+// keepsShellElement reports whether tag is part of the document shell that
+// KeepDocumentShell renders rather than skips. Inline styles and scripts are
+// always left to the CSS and JS output.
+func (c *JSXConverter) keepsShellElement(tag string) bool {
+	return c.keepShell && tag != "style" && tag != "script"
+}
+
 func (c *JSXConverter) renderElementAsJSX(buf *strings.Builder, n *html.Node) {
-	if skipElements[n.Data] {
+	if usage := c.usageFor(n); usage != nil {
+		c.renderComponentUsage(buf, n, usage)
+		return
+	}
+
+	if skipsElement(n) && !c.keepsShellElement(n.Data) {
 		if n.Data == "html" || n.Data == "body" {
 			for child := n.FirstChild; child != nil; child = child.NextSibling {
 				c.renderNodeAsJSX(buf, child)
@@ -230,14 +405,22 @@ func (c *JSXConverter) renderElementAsJSX(buf *strings.Builder, n *html.Node) {
 	buf.WriteString("<")
 	buf.WriteString(n.Data)
 
-	for _, attr := range n.Attr {
-		key, val := c.convertAttribute(attr)
+	attrs, moduleClass := c.moduleStyleClass(n)
+	for _, attr := range attrs {
+		key, val := c.convertAttribute(n.Data, attr)
+		if key == classAttributeName(n.Data) && moduleClass != "" {
+			val = fmt.Sprintf("{`%s ${styles.%s}`}", attr.Val, moduleClass)
+			moduleClass = ""
+		}
 		if key != "" && val != "" {
 			buf.WriteString(fmt.Sprintf(" %s=%s", key, val))
 		}
 	}
+	if moduleClass != "" {
+		buf.WriteString(fmt.Sprintf(" %s={styles.%s}", classAttributeName(n.Data), moduleClass))
+	}
 
-	if voidElements[n.Data] {
+	if selfCloses(n) {
 		buf.WriteString(" />")
 		return
 	}
@@ -253,7 +436,23 @@ func (c *JSXConverter) renderElementAsJSX(buf *strings.Builder, n *html.Node) {
 	buf.WriteString(">")
 }
 
-func (c *JSXConverter) convertAttribute(attr html.Attribute) (string, string) {
+// isCustomElement reports whether tag is a custom element name such as
+// my-widget. React passes attributes on custom elements through to the DOM
+// as strings, so they must not be renamed or converted.
+func isCustomElement(tag string) bool {
+	return strings.Contains(tag, "-")
+}
+
+// classAttributeName is the JSX attribute that sets the class on tag. React
+// only maps className to class for built-in elements.
+func classAttributeName(tag string) string {
+	if isCustomElement(tag) {
+		return "class"
+	}
+	return "className"
+}
+
+func (c *JSXConverter) convertAttribute(tag string, attr html.Attribute) (string, string) {
 	key := attr.Key
 	val := attr.Val
 
@@ -261,11 +460,25 @@ func (c *JSXConverter) convertAttribute(attr html.Attribute) (string, string) {
 	if attr.Namespace == "xlink" && key == "href" {
 		return "href", fmt.Sprintf(`"%s"`, val)
 	}
+	// React spells the XML namespace attributes in camelCase.
+	if attr.Namespace == "xml" && (key == "lang" || key == "space") {
+		return "xml" + strings.ToUpper(key[:1]) + key[1:], fmt.Sprintf(`"%s"`, val)
+	}
+	if attr.Namespace == "xmlns" && key == "xlink" {
+		return "xmlnsXlink", fmt.Sprintf(`"%s"`, val)
+	}
 	// Drop namespace attributes that React doesn't need
 	if attr.Namespace != "" {
 		return "", ""
 	}
 
+	if isCustomElement(tag) {
+		if key == "style" {
+			return "style", c.convertStyleToObject(val)
+		}
+		return key, fmt.Sprintf(`"%s"`, val)
+	}
+
 	if jsxKey, ok := jsxAttributeMap[key]; ok {
 		key = jsxKey
 	}
@@ -279,11 +492,15 @@ func (c *JSXConverter) convertAttribute(attr html.Attribute) (string, string) {
 		return "style", c.convertStyleToObject(val)
 	}
 
-	if key == "checked" || key == "disabled" || key == "selected" {
-		if val == key || val == "true" {
-			return key, "{true}"
-		}
-		return key, "{false}"
+	if jsxBooleanAttributes[key] {
+		// A boolean attribute is on whenever it is present: in HTML even
+		// disabled="false" disables the control.
+		return key, "{true}"
+	}
+
+	// Text copied from a textarea may span lines and contain quotes.
+	if key == "defaultValue" && strings.ContainsAny(val, "\"\n") {
+		return key, "{" + strconv.Quote(val) + "}"
 	}
 
 	return key, fmt.Sprintf(`"%s"`, val)
@@ -310,7 +527,7 @@ func (c *JSXConverter) convertStyleToObject(style string) string {
 		jsxStyles = append(jsxStyles, fmt.Sprintf("%s: '%s'", key, value))
 	}
 
-	return fmt.Sprintf("{%s}", strings.Join(jsxStyles, ", "))
+	return fmt.Sprintf("{{%s}}", strings.Join(jsxStyles, ", "))
 }
 
 func (c *JSXConverter) kebabToCamel(s string) string {
@@ -330,9 +547,9 @@ func (c *JSXConverter) kebabToCamel(s string) string {
 }
 
 func (c *JSXConverter) renderTextAsJSX(buf *strings.Builder, n *html.Node) {
-	text := n.Data
+	text := jsxtext.Escape(n.Data)
 
-	if strings.Contains(text, "<!--") && strings.Contains(text, "-->") {
+	if strings.Contains(text, "&lt;!--") && strings.Contains(text, "--&gt;") {
 		text = convertHTMLCommentsInText(text)
 	}
 
@@ -342,22 +559,25 @@ func (c *JSXConverter) renderTextAsJSX(buf *strings.Builder, n *html.Node) {
 	}
 }
 
+// convertHTMLCommentsInText turns comment markup left in escaped text
+// (&lt;!-- ... --&gt;) into JSX comments.
 func convertHTMLCommentsInText(text string) string {
+	const open, close = "&lt;!--", "--&gt;"
 	result := text
 	start := 0
 	for {
-		commentStart := strings.Index(result[start:], "<!--")
+		commentStart := strings.Index(result[start:], open)
 		if commentStart == -1 {
 			break
 		}
 		commentStart += start
-		commentEnd := strings.Index(result[commentStart:], "-->")
+		commentEnd := strings.Index(result[commentStart:], close)
 		if commentEnd == -1 {
 			break
 		}
-		commentEnd += commentStart + 3
+		commentEnd += commentStart + len(close)
 
-		commentContent := result[commentStart+4 : commentEnd-3]
+		commentContent := result[commentStart+len(open) : commentEnd-len(close)]
 
 		jsxComment := "{/*" + commentContent + "*/}"
 		result = result[:commentStart] + jsxComment + result[commentEnd:]
@@ -380,7 +600,7 @@ func (c *JSXConverter) generateCSSImports(css string) string {
 	}
 
 	for _, cssFile := range c.ExternalCSS {
-		if cssFile.Error == nil {
+		if cssFile.Error == nil && cssFile.Filename != "" {
 			imports = append(imports, fmt.Sprintf(`import '../styles/external/%s'`, cssFile.Filename))
 		}
 	}
@@ -388,24 +608,16 @@ func (c *JSXConverter) generateCSSImports(css string) string {
 	return strings.Join(imports, "\n")
 }
 
-func (c *JSXConverter) generateJSCode(js string) string {
-	var jsCode strings.Builder
-
-	if js != "" {
-		jsCode.WriteString("\n")
-		jsCode.WriteString(js)
-		jsCode.WriteString("\n")
-	}
-
+// generateModuleImports imports the external ES module scripts for their side
+// effects. Their import and export statements can't run inside useEffect.
+func (c *JSXConverter) generateModuleImports() string {
+	var imports []string
 	for _, jsFile := range c.ExternalJS {
-		if jsFile.Error == nil {
-			jsCode.WriteString("\n")
-			jsCode.WriteString(jsFile.Content)
-			jsCode.WriteString("\n")
+		if jsFile.Error == nil && jsFile.Filename != "" && jsFile.IsModule() {
+			imports = append(imports, fmt.Sprintf(`import '../scripts/external/%s'`, jsFile.Filename))
 		}
 	}
-
-	return jsCode.String()
+	return strings.Join(imports, "\n")
 }
 
 // =============================================================
@@ -414,22 +626,50 @@ func (c *JSXConverter) generateJSCode(js string) string {
 // =============================================================
 
 // ConvertSectionToTSX converts an HTML fragment into a standalone TSX component.
-// It produces properly indented JSX, adds (): JSX.Element return type, removes
-// unnecessary Fragment wrappers, and extracts repeated list patterns into typed
-// interfaces with data arrays.
+// It produces properly indented JSX, adds a React.JSX.Element return type,
+// removes unnecessary Fragment wrappers, and extracts repeated list patterns
+// into typed interfaces with data arrays.
 func ConvertSectionToTSX(htmlFragment, componentName string) (string, error) {
-	c := &JSXConverter{}
+	return convertSection(htmlFragment, componentName, true, nil)
+}
 
-	doc, err := html.Parse(strings.NewReader(htmlFragment))
+// ConvertSectionToJSX is the plain JavaScript counterpart of ConvertSectionToTSX:
+// the same markup, without the return type annotation or item interfaces.
+func ConvertSectionToJSX(htmlFragment, componentName string) (string, error) {
+	return convertSection(htmlFragment, componentName, false, nil)
+}
+
+// ConvertSectionWithComponents converts a section as ConvertSectionToTSX does,
+// or as ConvertSectionToJSX when typescript is false, rendering occurrences of
+// lib's components as usages. The components it renders are imported from the
+// section's own folder.
+func ConvertSectionWithComponents(htmlFragment, componentName string, typescript bool, lib *ComponentLibrary) (string, error) {
+	var usages []componentUsage
+	if lib != nil {
+		usages = lib.usages
+	}
+	return convertSection(htmlFragment, componentName, typescript, usages)
+}
+
+func convertSection(htmlFragment, componentName string, typescript bool, usages []componentUsage) (string, error) {
+	c := &JSXConverter{usages: usages}
+
+	doc, err := parseHTMLForJSX(htmlFragment)
 	if err != nil {
 		return "", fmt.Errorf("failed to convert section %q to JSX: %w", componentName, err)
 	}
 
 	body := findBodyNode(doc)
 
-	// Detect repeated list patterns and generate typed component.
-	if pattern := detectListPattern(body); pattern != nil {
-		return buildListComponentTSX(componentName, pattern, c, body), nil
+	// Detect repeated list patterns and generate typed component, unless the
+	// items are occurrences of a suggested component, which renders them.
+	if pattern := detectListPattern(body); pattern != nil && c.usageFor(pattern.Items[0]) == nil {
+		return buildListComponent(componentName, pattern, c, body, typescript, false, pageScript{}), nil
+	}
+
+	returnType := ""
+	if typescript {
+		returnType = ": React.JSX.Element"
 	}
 
 	roots := nonSkippedChildren(body)
@@ -445,25 +685,25 @@ func ConvertSectionToTSX(htmlFragment, componentName string) (string, error) {
 	if len(roots) == 1 {
 		c.renderElementIndented(&jsxBuf, roots[0], 2)
 		jsx := strings.TrimRight(jsxBuf.String(), "\n")
-		return fmt.Sprintf(`import React from 'react'
+		return fmt.Sprintf(`import React from 'react'%s
 
-%sfunction %s(): JSX.Element {
+%sfunction %s()%s {
   return (
 %s
   )
 }
 
 export default %s
-`, handlerComment, componentName, jsx, componentName), nil
+`, c.componentImports(), handlerComment, componentName, returnType, jsx, componentName), nil
 	}
 
 	for _, root := range roots {
 		c.renderElementIndented(&jsxBuf, root, 3)
 	}
 	jsx := strings.TrimRight(jsxBuf.String(), "\n")
-	return fmt.Sprintf(`import React from 'react'
+	return fmt.Sprintf(`import React from 'react'%s
 
-%sfunction %s(): JSX.Element {
+%sfunction %s()%s {
   return (
     <>
 %s
@@ -472,7 +712,17 @@ export default %s
 }
 
 export default %s
-`, handlerComment, componentName, jsx, componentName), nil
+`, c.componentImports(), handlerComment, componentName, returnType, jsx, componentName), nil
+}
+
+// componentImports returns an import line, each preceded by a newline, for
+// every component the converter rendered a usage of.
+func (c *JSXConverter) componentImports() string {
+	var imports strings.Builder
+	for _, name := range c.usedComponents {
+		imports.WriteString(fmt.Sprintf("\nimport %s from './%s'", name, name))
+	}
+	return imports.String()
 }
 
 // collectHandlerNames walks the node tree and returns the distinct function
@@ -540,7 +790,7 @@ func (c *JSXConverter) renderNodeIndented(buf *strings.Builder, n *html.Node, de
 	case html.TextNode:
 		trimmed := strings.TrimSpace(n.Data)
 		if trimmed != "" {
-			buf.WriteString(strings.Repeat("  ", depth) + trimmed + "\n")
+			buf.WriteString(strings.Repeat("  ", depth) + jsxtext.Escape(trimmed) + "\n")
 		}
 	case html.CommentNode:
 		trimmed := strings.TrimSpace(n.Data)
@@ -553,7 +803,7 @@ func (c *JSXConverter) renderNodeIndented(buf *strings.Builder, n *html.Node, de
 // hasElemChild returns true if n has at least one non-skipped element child.
 func hasElemChild(n *html.Node) bool {
 	for child := n.FirstChild; child != nil; child = child.NextSibling {
-		if child.Type == html.ElementNode && !skipElements[child.Data] {
+		if child.Type == html.ElementNode && !skipsElement(child) {
 			return true
 		}
 	}
@@ -568,7 +818,7 @@ func isInlineContent(n *html.Node) bool {
 		if child.Type != html.ElementNode {
 			continue
 		}
-		if skipElements[child.Data] {
+		if skipsElement(child) {
 			continue
 		}
 		if !inlineElements[child.Data] && !voidElements[child.Data] {
@@ -607,20 +857,24 @@ func (c *JSXConverter) renderChildrenInline(buf *strings.Builder, n *html.Node)
 		case html.TextNode:
 			t := normalizeInlineText(child.Data)
 			if t != "" {
-				buf.WriteString(t)
+				buf.WriteString(jsxtext.Escape(t))
 			}
 		case html.ElementNode:
-			if skipElements[child.Data] {
+			if usage := c.usageFor(child); usage != nil {
+				c.renderComponentUsage(buf, child, usage)
+				continue
+			}
+			if skipsElement(child) {
 				continue
 			}
 			buf.WriteString("<" + child.Data)
 			for _, attr := range child.Attr {
-				key, val := c.convertAttribute(attr)
+				key, val := c.convertAttribute(child.Data, attr)
 				if key != "" && val != "" {
 					buf.WriteString(fmt.Sprintf(" %s=%s", key, val))
 				}
 			}
-			if voidElements[child.Data] {
+			if selfCloses(child) {
 				buf.WriteString(" />")
 				continue
 			}
@@ -637,7 +891,14 @@ func (c *JSXConverter) renderChildrenInline(buf *strings.Builder, n *html.Node)
 }
 
 func (c *JSXConverter) renderElementIndented(buf *strings.Builder, n *html.Node, depth int) {
-	if skipElements[n.Data] {
+	if usage := c.usageFor(n); usage != nil {
+		buf.WriteString(strings.Repeat("  ", depth))
+		c.renderComponentUsage(buf, n, usage)
+		buf.WriteString("\n")
+		return
+	}
+
+	if skipsElement(n) {
 		if n.Data == "html" || n.Data == "body" {
 			for child := n.FirstChild; child != nil; child = child.NextSibling {
 				c.renderNodeIndented(buf, child, depth)
@@ -650,13 +911,13 @@ func (c *JSXConverter) renderElementIndented(buf *strings.Builder, n *html.Node,
 	buf.WriteString(indent + "<" + n.Data)
 
 	for _, attr := range n.Attr {
-		key, val := c.convertAttribute(attr)
+		key, val := c.convertAttribute(n.Data, attr)
 		if key != "" && val != "" {
 			buf.WriteString(fmt.Sprintf(" %s=%s", key, val))
 		}
 	}
 
-	if voidElements[n.Data] {
+	if selfCloses(n) {
 		buf.WriteString(" />\n")
 		return
 	}
@@ -682,7 +943,7 @@ func (c *JSXConverter) renderElementIndented(buf *strings.Builder, n *html.Node,
 				textBuf.WriteString(strings.TrimSpace(child.Data))
 			}
 		}
-		buf.WriteString(">" + textBuf.String() + "</" + n.Data + ">\n")
+		buf.WriteString(">" + jsxtext.Escape(textBuf.String()) + "</" + n.Data + ">\n")
 	}
 }
 
@@ -712,7 +973,7 @@ func nonSkippedChildren(n *html.Node) []*html.Node {
 	}
 	var result []*html.Node
 	for child := n.FirstChild; child != nil; child = child.NextSibling {
-		if child.Type == html.ElementNode && !skipElements[child.Data] {
+		if child.Type == html.ElementNode && !skipsElement(child) {
 			result = append(result, child)
 		}
 	}
@@ -801,6 +1062,9 @@ type listPattern struct {
 	Wrapper *html.Node
 	Items   []*html.Node
 	Fields  []listField
+	// KeyField names the field used as the React key of each mapped item, or
+	// is empty when the items carry nothing unique and the index is used.
+	KeyField string
 }
 
 func detectListPattern(body *html.Node) *listPattern {
@@ -819,7 +1083,8 @@ func findListInSubtree(n *html.Node, depth int) *listPattern {
 	if len(items) >= 2 {
 		fields := extractListFields(items)
 		if len(fields) > 0 {
-			return &listPattern{Wrapper: n, Items: items, Fields: fields}
+			fields, keyField := addListKeyField(items, fields)
+			return &listPattern{Wrapper: n, Items: items, Fields: fields, KeyField: keyField}
 		}
 	}
 
@@ -1025,11 +1290,49 @@ func extractListFields(items []*html.Node) []listField {
 	return fields
 }
 
+// addListKeyField picks a stable React key for the list items. An id or data-id
+// on the item elements is added as a field of its own; otherwise an href field
+// is reused when every item links somewhere different. It returns an empty key
+// when nothing is unique, leaving the array index as the key.
+func addListKeyField(items []*html.Node, fields []listField) ([]listField, string) {
+	for _, attr := range []struct{ key, name string }{{"id", "id"}, {"data-id", "dataId"}} {
+		values := make([]string, len(items))
+		for i, item := range items {
+			values[i] = jsxGetAttr(item, attr.key)
+		}
+		if uniqueNonEmpty(values) {
+			return append([]listField{{Name: attr.name, TSType: "string", Values: values}}, fields...), attr.name
+		}
+	}
+
+	for _, field := range fields {
+		if field.Name == "href" && uniqueNonEmpty(field.Values) {
+			return fields, field.Name
+		}
+	}
+	return fields, ""
+}
+
+func uniqueNonEmpty(values []string) bool {
+	seen := make(map[string]bool, len(values))
+	for _, v := range values {
+		if v == "" || v == "#" || seen[v] {
+			return false
+		}
+		seen[v] = true
+	}
+	return true
+}
+
 // =============================================================
 // List component TSX builder
 // =============================================================
 
-func buildListComponentTSX(componentName string, pattern *listPattern, c *JSXConverter, body *html.Node) string {
+// buildListComponent renders a component that maps over the detected list
+// items. With itemsProp the data becomes the default value of an items prop
+// rather than a module-level constant. script's declarations go before the
+// component and its effect at the top of it.
+func buildListComponent(componentName string, pattern *listPattern, c *JSXConverter, body *html.Node, typescript, itemsProp bool, script pageScript) string {
 	typeName := componentName + "Item"
 
 	// value → field reference (without braces) for substitution
@@ -1046,15 +1349,25 @@ func buildListComponentTSX(componentName string, pattern *listPattern, c *JSXCon
 
 	// TypeScript interface
 	var iface strings.Builder
-	iface.WriteString(fmt.Sprintf("interface %s {\n", typeName))
-	for _, f := range pattern.Fields {
-		iface.WriteString(fmt.Sprintf("  %s: %s\n", f.Name, f.TSType))
+	if typescript {
+		iface.WriteString(fmt.Sprintf("interface %s {\n", typeName))
+		for _, f := range pattern.Fields {
+			iface.WriteString(fmt.Sprintf("  %s: %s\n", f.Name, f.TSType))
+		}
+		iface.WriteString("}\n")
 	}
-	iface.WriteString("}\n")
 
 	// Data array
+	dataName := "items"
+	if itemsProp {
+		dataName = "defaultItems"
+	}
 	var data strings.Builder
-	data.WriteString(fmt.Sprintf("const items: %s[] = [\n", typeName))
+	if typescript {
+		data.WriteString(fmt.Sprintf("const %s: %s[] = [\n", dataName, typeName))
+	} else {
+		data.WriteString(fmt.Sprintf("const %s = [\n", dataName))
+	}
 	for i := range pattern.Items {
 		data.WriteString("  {\n")
 		for _, f := range pattern.Fields {
@@ -1084,16 +1397,29 @@ func buildListComponentTSX(componentName string, pattern *listPattern, c *JSXCon
 		returnExpr = fmt.Sprintf("(\n    <>\n%s\n    </>)", bodyJSX)
 	}
 
+	returnType := ""
+	if typescript {
+		returnType = ": React.JSX.Element"
+	}
+
+	params := ""
+	if itemsProp {
+		params = "{ items = defaultItems }"
+		if typescript {
+			params += fmt.Sprintf(": { items?: %s[] }", typeName)
+		}
+	}
+
 	return fmt.Sprintf(`import React from 'react'
 
 %s
 %s
-function %s(): JSX.Element {
-  return %s
+%sfunction %s(%s)%s {
+%s  return %s
 }
 
 export default %s
-`, iface.String(), data.String(), componentName, returnExpr, componentName)
+`, iface.String(), data.String(), script.declarations, componentName, params, returnType, script.effect, returnExpr, componentName)
 }
 
 // renderWithListMap renders the tree normally but replaces the list wrapper's
@@ -1106,7 +1432,7 @@ func (c *JSXConverter) renderWithListMap(
 	if n == nil || n.Type != html.ElementNode {
 		return
 	}
-	if skipElements[n.Data] {
+	if skipsElement(n) {
 		if n.Data == "html" || n.Data == "body" {
 			for child := n.FirstChild; child != nil; child = child.NextSibling {
 				c.renderWithListMap(buf, child, depth, pattern, fieldSubs)
@@ -1118,13 +1444,13 @@ func (c *JSXConverter) renderWithListMap(
 	indent := strings.Repeat("  ", depth)
 	buf.WriteString(indent + "<" + n.Data)
 	for _, attr := range n.Attr {
-		key, val := c.convertAttribute(attr)
+		key, val := c.convertAttribute(n.Data, attr)
 		if key != "" && val != "" {
 			buf.WriteString(fmt.Sprintf(" %s=%s", key, val))
 		}
 	}
 
-	if voidElements[n.Data] {
+	if selfCloses(n) {
 		buf.WriteString(" />\n")
 		return
 	}
@@ -1135,7 +1461,11 @@ func (c *JSXConverter) renderWithListMap(
 		buf.WriteString(">\n")
 		mapIndent := strings.Repeat("  ", depth+1)
 		buf.WriteString(mapIndent + "{items.map((item, index) => (\n")
-		c.renderElemWithSubs(buf, pattern.Items[0], depth+2, fieldSubs, true)
+		key := "index"
+		if pattern.KeyField != "" {
+			key = "item." + pattern.KeyField
+		}
+		c.renderElemWithSubs(buf, pattern.Items[0], depth+2, fieldSubs, key)
 		buf.WriteString(mapIndent + "))}\n")
 		buf.WriteString(indent + "</" + n.Data + ">\n")
 		return
@@ -1154,13 +1484,14 @@ func (c *JSXConverter) renderWithListMap(
 				textBuf.WriteString(strings.TrimSpace(child.Data))
 			}
 		}
-		buf.WriteString(">" + textBuf.String() + "</" + n.Data + ">\n")
+		buf.WriteString(">" + jsxtext.Escape(textBuf.String()) + "</" + n.Data + ">\n")
 	}
 }
 
 // renderElemWithSubs renders an item element substituting dynamic field values.
-func (c *JSXConverter) renderElemWithSubs(buf *strings.Builder, n *html.Node, depth int, fieldSubs map[string]string, isRoot bool) {
-	if n == nil || n.Type != html.ElementNode || skipElements[n.Data] {
+// A non-empty keyExpr is emitted as the element's React key prop.
+func (c *JSXConverter) renderElemWithSubs(buf *strings.Builder, n *html.Node, depth int, fieldSubs map[string]string, keyExpr string) {
+	if n == nil || n.Type != html.ElementNode || skipsElement(n) {
 		return
 	}
 
@@ -1175,11 +1506,11 @@ func (c *JSXConverter) renderElemWithSubs(buf *strings.Builder, n *html.Node, de
 	}
 
 	// Add key prop at the root item level.
-	if isRoot {
-		buf.WriteString(" key={index}")
+	if keyExpr != "" {
+		buf.WriteString(" key={" + keyExpr + "}")
 	}
 
-	if voidElements[n.Data] {
+	if selfCloses(n) {
 		buf.WriteString(" />\n")
 		return
 	}
@@ -1201,7 +1532,7 @@ func (c *JSXConverter) renderElemWithSubs(buf *strings.Builder, n *html.Node, de
 		if ref, ok := fieldSubs[text]; ok {
 			buf.WriteString(">{" + ref + "}</" + n.Data + ">\n")
 		} else {
-			buf.WriteString(">" + text + "</" + n.Data + ">\n")
+			buf.WriteString(">" + jsxtext.Escape(text) + "</" + n.Data + ">\n")
 		}
 	}
 }
@@ -1209,7 +1540,7 @@ func (c *JSXConverter) renderElemWithSubs(buf *strings.Builder, n *html.Node, de
 func (c *JSXConverter) renderNodeWithSubs(buf *strings.Builder, n *html.Node, depth int, fieldSubs map[string]string) {
 	switch n.Type {
 	case html.ElementNode:
-		c.renderElemWithSubs(buf, n, depth, fieldSubs, false)
+		c.renderElemWithSubs(buf, n, depth, fieldSubs, "")
 	case html.TextNode:
 		trimmed := strings.TrimSpace(n.Data)
 		if trimmed == "" {
@@ -1219,7 +1550,7 @@ func (c *JSXConverter) renderNodeWithSubs(buf *strings.Builder, n *html.Node, de
 		if ref, ok := fieldSubs[trimmed]; ok {
 			buf.WriteString(indent + "{" + ref + "}\n")
 		} else {
-			buf.WriteString(indent + trimmed + "\n")
+			buf.WriteString(indent + jsxtext.Escape(trimmed) + "\n")
 		}
 	}
 }
@@ -1241,11 +1572,8 @@ func (c *JSXConverter) convertAttrWithSubs(attr html.Attribute, fieldSubs map[st
 		return "style", c.convertStyleWithSubs(rawVal, fieldSubs)
 	}
 
-	if key == "checked" || key == "disabled" || key == "selected" {
-		if rawVal == key || rawVal == "true" {
-			return key, "{true}"
-		}
-		return key, "{false}"
+	if jsxBooleanAttributes[key] {
+		return key, "{true}"
 	}
 
 	if ref, ok := fieldSubs[rawVal]; ok {
@@ -1292,7 +1620,7 @@ func (c *JSXConverter) convertStyleWithSubs(style string, fieldSubs map[string]s
 		substituted := false
 		for origVal, ref := range fieldSubs {
 			if cssVal == origVal {
-				jsxStyles = append(jsxStyles, fmt.Sprintf("%s: {%s}", camelKey, ref))
+				jsxStyles = append(jsxStyles, fmt.Sprintf("%s: %s", camelKey, ref))
 				substituted = true
 				break
 			}
@@ -1302,51 +1630,20 @@ func (c *JSXConverter) convertStyleWithSubs(style string, fieldSubs map[string]s
 		}
 	}
 
-	return fmt.Sprintf("{%s}", strings.Join(jsxStyles, ", "))
+	return fmt.Sprintf("{{%s}}", strings.Join(jsxStyles, ", "))
 }
 
+// AnalyzeAndConvert returns the code of each component from
+// ConvertToComponents in TypeScript.
 func AnalyzeAndConvert(html string) ([]string, error) {
-	suggestions, err := analyzer.AnalyzeComponents(html)
+	generated, err := ConvertToComponents(html, Options{Language: "ts"})
 	if err != nil {
-		return nil, fmt.Errorf("failed to analyze HTML: %w", err)
+		return nil, err
 	}
 
-	var components []string
-
-	for _, suggestion := range suggestions {
-		componentName := suggestion.Name
-		componentName = strings.Title(strings.ReplaceAll(componentName, "-", " "))
-		componentName = strings.ReplaceAll(componentName, " ", "")
-
-		if suggestion.JSXCode != "" {
-			component := fmt.Sprintf(`import React from 'react'
-
-%s`, suggestion.JSXCode)
-			components = append(components, component)
-			continue
-		}
-
-		jsx := fmt.Sprintf(`<div className="%s">
-  {/* %s */}
-</div>`, suggestion.TagName, suggestion.Description)
-
-		component := fmt.Sprintf(`import React from 'react'
-
-interface %sProps {
-}
-
-function %s(props: %sProps) {
-  return (
-    <>
-      %s
-    </>
-  )
-}
-
-export default %s
-`, componentName, componentName, componentName, jsx, componentName)
-
-		components = append(components, component)
+	components := make([]string, 0, len(generated))
+	for _, component := range generated {
+		components = append(components, component.Code)
 	}
 
 	return components, nil
@@ -0,0 +1,160 @@
+package converter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/omariomari2/uncluster/internal/fetcher"
+)
+
+func TestListMapEmitsReactKeys(t *testing.T) {
+	cases := map[string]struct {
+		input, key string
+	}{
+		"index": {
+			input: `<ul><li><h3>Fast</h3><p>Quick</p></li><li><h3>Small</h3><p>Tiny</p></li><li><h3>Safe</h3><p>Typed</p></li></ul>`,
+			key:   "key={index}",
+		},
+		"id": {
+			input: `<ul><li id="fast"><h3>Fast</h3></li><li id="small"><h3>Small</h3></li><li id="safe"><h3>Safe</h3></li></ul>`,
+			key:   "key={item.id}",
+		},
+		"data-id": {
+			input: `<ul><li data-id="1"><h3>Fast</h3></li><li data-id="2"><h3>Small</h3></li><li data-id="3"><h3>Safe</h3></li></ul>`,
+			key:   "key={item.dataId}",
+		},
+		"href": {
+			input: `<ul><li><a href="/fast">Fast</a></li><li><a href="/small">Small</a></li><li><a href="/safe">Safe</a></li></ul>`,
+			key:   "key={item.href}",
+		},
+		"duplicate href": {
+			input: `<ul><li><a href="#">Fast</a></li><li><a href="#">Small</a></li><li><a href="#">Safe</a></li></ul>`,
+			key:   "key={index}",
+		},
+	}
+	for name, tc := range cases {
+		out, err := ConvertToJSXWithOptions(tc.input, "", "", nil, nil, Options{Language: "ts", GenerateProps: true})
+		if err != nil {
+			t.Fatalf("%s: ConvertToJSXWithOptions returned error: %v", name, err)
+		}
+		if !strings.Contains(out, "items.map(") || !strings.Contains(out, tc.key) {
+			t.Fatalf("%s: expected mapped items with %s, got:\n%s", name, tc.key, out)
+		}
+	}
+}
+
+func TestConvertPassesCustomElementsThrough(t *testing.T) {
+	input := `<section><my-card class="featured" tabindex="0" disabled variant="wide">One</my-card>` +
+		`<my-card class="featured" tabindex="0" disabled variant="wide">Two</my-card></section>`
+
+	out, err := ConvertToJSXWithOptions(input, "", "", nil, nil, Options{})
+	if err != nil {
+		t.Fatalf("ConvertToJSXWithOptions returned error: %v", err)
+	}
+	if !strings.Contains(out, `<my-card class="featured" tabindex="0" disabled="" variant="wide">`) {
+		t.Fatalf("expected custom element attributes to pass through unchanged, got:\n%s", out)
+	}
+	if strings.Contains(out, "<my-card className") || strings.Contains(out, "<my-card />") {
+		t.Fatalf("custom element was rewritten, got:\n%s", out)
+	}
+}
+
+func TestConvertDropsDocumentShell(t *testing.T) {
+	input := "<!doctype html><html lang=\"en\"><head><title>Page title</title><meta name=\"description\" content=\"desc\"></head>" +
+		"<body\n  class=\"home page\"\n  data-theme=\"dark\"><p>Type &lt;body&gt; or {name} here</p></body></html>"
+
+	out, err := ConvertToJSXWithOptions(input, "", "", nil, nil, Options{})
+	if err != nil {
+		t.Fatalf("ConvertToJSXWithOptions returned error: %v", err)
+	}
+	for _, unwanted := range []string{"Page title", "description", "data-theme", "<body", "<html", "{name}"} {
+		if strings.Contains(out, unwanted) {
+			t.Fatalf("expected %q to be dropped or escaped, got:\n%s", unwanted, out)
+		}
+	}
+	if !strings.Contains(out, "<p>Type &lt;body&gt; or &#123;name&#125; here</p>") {
+		t.Fatalf("expected escaped body text, got:\n%s", out)
+	}
+}
+
+func TestConvertKeepsSrcsetAndSelfClosesOnce(t *testing.T) {
+	input := `<picture><img srcset="a.jpg 1x, b.jpg 2x" title="a > b" alt="photo"></picture><img src="c.jpg" /><input type="text">`
+
+	out, err := ConvertToJSXWithOptions(input, "", "", nil, nil, Options{})
+	if err != nil {
+		t.Fatalf("ConvertToJSXWithOptions returned error: %v", err)
+	}
+	if !strings.Contains(out, `<img srcSet="a.jpg 1x, b.jpg 2x" title="a > b" alt="photo" />`) {
+		t.Fatalf("expected srcset to survive as srcSet, got:\n%s", out)
+	}
+	if !strings.Contains(out, `<img src="c.jpg" />`) || strings.Contains(out, "/ />") || strings.Contains(out, "</img>") {
+		t.Fatalf("expected img to be self-closed exactly once, got:\n%s", out)
+	}
+	if !strings.Contains(out, `<input type="text" />`) {
+		t.Fatalf("expected input to be self-closed, got:\n%s", out)
+	}
+}
+
+func TestConvertImportsModuleScripts(t *testing.T) {
+	externalJS := []fetcher.FetchedResource{
+		{Filename: "app.js", Content: "import { start } from './start.js'\nstart()", ScriptType: "module"},
+		{Filename: "legacy.js", Content: "legacy()"},
+	}
+	jsx, err := ConvertToJSX(`<p>Hi</p>`, "", "", nil, externalJS)
+	if err != nil {
+		t.Fatalf("ConvertToJSX returned error: %v", err)
+	}
+
+	if !strings.Contains(jsx, "import '../scripts/external/app.js'") {
+		t.Fatalf("expected the module script to be imported, got:\n%s", jsx)
+	}
+	if strings.Contains(jsx, "import { start }") {
+		t.Fatalf("module code should not be inlined into useEffect, got:\n%s", jsx)
+	}
+	if !strings.Contains(jsx, "useEffect(() => {\n    legacy()\n  }, [])") {
+		t.Fatalf("expected the classic script in useEffect, got:\n%s", jsx)
+	}
+}
+
+func TestConvertKeepsScriptDeclarationsAtModuleScope(t *testing.T) {
+	js := `
+    const button = document.querySelector('#go');
+    let count = 0
+    function go() {
+      count++
+      button.textContent = ` + "`Clicked ${count}`" + `
+    }
+    if (count === 0) {
+      go()
+    } else {
+      reset()
+    }
+`
+	jsx, err := ConvertToJSX(`<button id="go" onclick="go()">Go</button>`, "", js, nil, nil)
+	if err != nil {
+		t.Fatalf("ConvertToJSX returned error: %v", err)
+	}
+
+	component := strings.Index(jsx, "function MainComponent()")
+	for _, want := range []string{"let button\n", "let count\n", "function go() {\n  count++\n"} {
+		if i := strings.Index(jsx, want); i < 0 || i > component {
+			t.Fatalf("expected %q at module scope, before the component, got:\n%s", want, jsx)
+		}
+	}
+	effect := "  useEffect(() => {\n    button = document.querySelector('#go')\n    count = 0\n    if (count === 0) {\n      go()\n    } else {\n      reset()\n    }\n  }, [])"
+	if !strings.Contains(jsx, effect) {
+		t.Fatalf("expected the remaining statements in useEffect, got:\n%s", jsx)
+	}
+	if !strings.Contains(jsx, "onClick={() => { go() }}") {
+		t.Fatalf("expected the handler to call go, got:\n%s", jsx)
+	}
+}
+
+func TestSplitStatements(t *testing.T) {
+	js := "a = 1; b = '2;3'\nc = x\n  .then(y)\nd++\n// note\nf(`${g; h}`)\nfor (;;) { break }\nk = {\n  l: 1,\n}"
+	want := []string{"a = 1;", "b = '2;3'", "c = x\n  .then(y)", "d++", "// note\nf(`${g; h}`)", "for (;;) { break }", "k = {\n  l: 1,\n}"}
+	got := splitStatements(js)
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("splitStatements:\ngot  %q\nwant %q", got, want)
+	}
+}
@@ -0,0 +1,157 @@
+package converter
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Options tunes ConvertToJSXWithOptions. The zero value matches ConvertToJSX.
+type Options struct {
+	// ComponentName is the generated function name. Defaults to "MainComponent".
+	ComponentName string
+	// Language is "js" (default) or "ts". TypeScript adds a React.JSX.Element
+	// return type and typed list data.
+	Language string
+	// WrapperMode is how the converted markup is wrapped: "fragment" (default),
+	// "div", or "none". "none" falls back to a fragment when the markup has
+	// more than one root element.
+	WrapperMode string
+	// GenerateProps turns a detected repeated list (cards, menu items, ...) into
+	// an items prop that defaults to the data extracted from the page.
+	GenerateProps bool
+	// KeepDocumentShell renders the <html>, <head>, and <body> elements (with
+	// attributes such as lang) instead of unwrapping them, and records the
+	// original DOCTYPE in a comment, since JSX cannot express one. Use it for
+	// root layouts that own the whole document.
+	KeepDocumentShell bool
+	// StyleStrategy is how style attributes are converted: "inline" (default)
+	// renders JSX style objects, "cssModules" moves them into a CSS Module
+	// (see ConvertToComponent) referenced through className. Repeated-list
+	// output from GenerateProps keeps inline styles, since they can vary per
+	// item. "styledComponents" is reserved and not implemented yet.
+	StyleStrategy string
+	// StripScripts drops the js argument and external JS. By default they are
+	// kept and run in a useEffect hook, since top-level code that touches the
+	// DOM runs before React has rendered the markup.
+	StripScripts bool
+	// StripStyles drops the imports for the css argument and external CSS.
+	StripStyles bool
+	// Clean removes editor-injected attributes before converting: those in
+	// DefaultStripAttributes, plus empty style and class attributes.
+	Clean bool
+	// StripAttributes lists more attributes to remove, with or without Clean.
+	// Names may use * wildcards, so "data-wf-*" removes data-wf-id and
+	// data-wf-page.
+	StripAttributes []string
+	// Indent is the indentation of the generated code: "2" (default) or "4"
+	// spaces, or "tab". Indentation copied from the page, such as a
+	// tab-indented script, is rewritten to match.
+	Indent string
+	// SingleFile makes the component self-contained for sharing: the css
+	// argument and the downloaded external CSS go into a styles constant
+	// rendered as a <style> element, instead of stylesheet imports. It cannot
+	// be combined with KeepDocumentShell or the cssModules style strategy.
+	SingleFile bool
+}
+
+// DefaultComponentName is the generated function name when none is given.
+const DefaultComponentName = "MainComponent"
+
+var componentNamePattern = regexp.MustCompile(`^[A-Z][A-Za-z0-9_$]*$`)
+
+// ValidateComponentName reports whether name can be used as a React component
+// name: a legal JavaScript identifier that starts with an uppercase letter, so
+// JSX treats it as a component rather than an HTML tag.
+func ValidateComponentName(name string) error {
+	if !componentNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid componentName %q (expected a JavaScript identifier starting with an uppercase letter, such as MainComponent)", name)
+	}
+	return nil
+}
+
+// ComponentNameFrom derives a component name from free text such as a project
+// name or host ("my-site.example" becomes "MySiteExample"). It returns
+// DefaultComponentName when text has no usable letters.
+func ComponentNameFrom(text string) string {
+	var b strings.Builder
+	for _, word := range strings.FieldsFunc(text, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+	}) {
+		b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	name := b.String()
+	if ValidateComponentName(name) != nil {
+		return DefaultComponentName
+	}
+	return name
+}
+
+// Normalize fills in defaults and validates every option. The error lists the
+// accepted values for the offending option.
+func (o Options) Normalize() (Options, error) {
+	o.ComponentName = strings.TrimSpace(o.ComponentName)
+	if o.ComponentName == "" {
+		o.ComponentName = DefaultComponentName
+	} else if err := ValidateComponentName(o.ComponentName); err != nil {
+		return o, err
+	}
+
+	o.Language = strings.ToLower(strings.TrimSpace(o.Language))
+	switch o.Language {
+	case "":
+		o.Language = "js"
+	case "js", "ts":
+	default:
+		return o, fmt.Errorf("invalid language %q (expected js or ts)", o.Language)
+	}
+
+	o.WrapperMode = strings.ToLower(strings.TrimSpace(o.WrapperMode))
+	switch o.WrapperMode {
+	case "":
+		o.WrapperMode = "fragment"
+	case "fragment", "div", "none":
+	default:
+		return o, fmt.Errorf("invalid wrapperMode %q (expected fragment, div, or none)", o.WrapperMode)
+	}
+
+	switch strings.ToLower(strings.TrimSpace(o.StyleStrategy)) {
+	case "", "inline":
+		o.StyleStrategy = "inline"
+	case "cssmodules":
+		o.StyleStrategy = "cssModules"
+	case "styledcomponents":
+		return o, fmt.Errorf("styleStrategy %q is not implemented yet (use inline or cssModules)", o.StyleStrategy)
+	default:
+		return o, fmt.Errorf("invalid styleStrategy %q (expected inline or cssModules)", o.StyleStrategy)
+	}
+
+	var patterns []string
+	for _, pattern := range o.StripAttributes {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if _, err := path.Match(pattern, ""); err != nil || pattern == "" {
+			return o, fmt.Errorf("invalid stripAttributes entry %q (expected an attribute name, optionally with * wildcards such as data-wf-*)", pattern)
+		}
+		patterns = append(patterns, pattern)
+	}
+	o.StripAttributes = patterns
+
+	o.Indent = strings.ToLower(strings.TrimSpace(o.Indent))
+	switch o.Indent {
+	case "":
+		o.Indent = DefaultIndent
+	case "2", "4", "tab":
+	default:
+		return o, fmt.Errorf("invalid indent %q (expected 2, 4, or tab)", o.Indent)
+	}
+
+	if o.SingleFile && o.KeepDocumentShell {
+		return o, fmt.Errorf("singleFile cannot be combined with keepDocumentShell")
+	}
+	if o.SingleFile && o.StyleStrategy == "cssModules" {
+		return o, fmt.Errorf("singleFile cannot be combined with the cssModules style strategy")
+	}
+
+	return o, nil
+}
@@ -0,0 +1,266 @@
+package converter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/omariomari2/uncluster/internal/fetcher"
+)
+
+func TestConvertToJSXWithOptions(t *testing.T) {
+	input := `<section class="hero"><h1>Hello</h1></section>`
+
+	defaultOut, err := ConvertToJSX(input, "", "", nil, nil)
+	if err != nil {
+		t.Fatalf("ConvertToJSX returned error: %v", err)
+	}
+	if !strings.Contains(defaultOut, "function MainComponent() {") || !strings.Contains(defaultOut, "<>") {
+		t.Fatalf("expected default fragment-wrapped MainComponent, got:\n%s", defaultOut)
+	}
+
+	out, err := ConvertToJSXWithOptions(input, "", "", nil, nil, Options{
+		ComponentName: "Hero",
+		Language:      "ts",
+		WrapperMode:   "none",
+	})
+	if err != nil {
+		t.Fatalf("ConvertToJSXWithOptions returned error: %v", err)
+	}
+	if !strings.Contains(out, "function Hero(): React.JSX.Element {") || strings.Contains(out, "<>") || !strings.Contains(out, "export default Hero") {
+		t.Fatalf("unexpected output:\n%s", out)
+	}
+
+	out, err = ConvertToJSXWithOptions(input, "", "", nil, nil, Options{WrapperMode: "div"})
+	if err != nil {
+		t.Fatalf("ConvertToJSXWithOptions returned error: %v", err)
+	}
+	if !strings.Contains(out, "<div>") {
+		t.Fatalf("expected div wrapper, got:\n%s", out)
+	}
+}
+
+func TestConvertToJSXWithOptionsGenerateProps(t *testing.T) {
+	input := `<ul class="features">
+<li class="feature"><h3>Fast</h3><p>Quick builds</p></li>
+<li class="feature"><h3>Small</h3><p>Tiny bundles</p></li>
+<li class="feature"><h3>Safe</h3><p>Typed output</p></li>
+</ul>`
+
+	out, err := ConvertToJSXWithOptions(input, "", "", nil, nil, Options{ComponentName: "Features", Language: "ts", GenerateProps: true})
+	if err != nil {
+		t.Fatalf("ConvertToJSXWithOptions returned error: %v", err)
+	}
+	if !strings.Contains(out, "function Features({ items = defaultItems }: { items?: FeaturesItem[] })") {
+		t.Fatalf("expected an items prop, got:\n%s", out)
+	}
+	if !strings.Contains(out, "items.map(") {
+		t.Fatalf("expected items to be mapped, got:\n%s", out)
+	}
+}
+
+func TestOptionsNormalizeRejectsInvalidValues(t *testing.T) {
+	cases := map[string]Options{
+		"expected js or ts":                 {Language: "python"},
+		"expected fragment, div, or none":   {WrapperMode: "span"},
+		"starting with an uppercase letter": {ComponentName: "my-component"},
+		"JavaScript identifier":             {ComponentName: "Main Component"},
+		"expected inline or cssModules":     {StyleStrategy: "tailwind"},
+		"expected 2, 4, or tab":             {Indent: "3"},
+		"combined with keepDocumentShell":   {SingleFile: true, KeepDocumentShell: true},
+		"combined with the cssModules":      {SingleFile: true, StyleStrategy: "cssModules"},
+	}
+	for want, opts := range cases {
+		_, err := opts.Normalize()
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("expected error containing %q, got %v", want, err)
+		}
+	}
+}
+
+func TestComponentNameFrom(t *testing.T) {
+	cases := map[string]string{
+		"my-site.example": "MySiteExample",
+		"landing_page":    "LandingPage",
+		"404-page":        DefaultComponentName,
+		"  ":              DefaultComponentName,
+	}
+	for text, want := range cases {
+		if got := ComponentNameFrom(text); got != want {
+			t.Fatalf("ComponentNameFrom(%q) = %q, want %q", text, got, want)
+		}
+	}
+}
+
+func TestConvertToJSXWithOptionsKeepDocumentShell(t *testing.T) {
+	cases := map[string]string{
+		"html5":  `<!DOCTYPE html>`,
+		"xhtml1": `<!DOCTYPE html PUBLIC "-//W3C//DTD XHTML 1.0 Strict//EN" "http://www.w3.org/TR/xhtml1/DTD/xhtml1-strict.dtd">`,
+	}
+	for name, doctype := range cases {
+		input := doctype + `<html lang="en"><head><title>Docs</title><style>p{}</style></head><body class="page"><p>hi</p></body></html>`
+
+		out, err := ConvertToJSXWithOptions(input, "", "", nil, nil, Options{KeepDocumentShell: true})
+		if err != nil {
+			t.Fatalf("%s: ConvertToJSXWithOptions returned error: %v", name, err)
+		}
+		for _, want := range []string{
+			"// Original document type: " + doctype,
+			`<html lang="en">`,
+			"<title>Docs</title>",
+			`<body className="page">`,
+		} {
+			if !strings.Contains(out, want) {
+				t.Fatalf("%s: expected %q in output, got:\n%s", name, want, out)
+			}
+		}
+		if strings.Contains(out, "<style>") || strings.Contains(out, "<>") {
+			t.Fatalf("%s: expected no style element or fragment wrapper, got:\n%s", name, out)
+		}
+	}
+}
+
+func TestConvertToComponentCSSModules(t *testing.T) {
+	input := `<div class="hero" style="color: red; margin: 0"><p style="color:red;margin:0">a</p><span style="font-weight: bold">b</span></div>`
+
+	component, err := ConvertToComponent(input, "", "", nil, nil, Options{ComponentName: "Hero", StyleStrategy: "cssModules"})
+	if err != nil {
+		t.Fatalf("ConvertToComponent returned error: %v", err)
+	}
+	if component.Filename != "Hero.jsx" {
+		t.Fatalf("unexpected filename %q", component.Filename)
+	}
+	for _, want := range []string{
+		"import styles from './Hero.module.css'",
+		"<div className={`hero ${styles.div1}`}>",
+		"<p className={styles.div1}>",
+		"<span className={styles.span2}>",
+	} {
+		if !strings.Contains(component.Code, want) {
+			t.Fatalf("expected %q in component, got:\n%s", want, component.Code)
+		}
+	}
+	if strings.Contains(component.Code, "style=") {
+		t.Fatalf("expected no inline styles, got:\n%s", component.Code)
+	}
+	for _, want := range []string{".div1 {\n  color: red;\n  margin: 0;\n}", ".span2 {\n  font-weight: bold;\n}"} {
+		if !strings.Contains(component.StyleModule, want) {
+			t.Fatalf("expected %q in CSS Module, got:\n%s", want, component.StyleModule)
+		}
+	}
+
+	if _, err := (Options{StyleStrategy: "styledComponents"}).Normalize(); err == nil || !strings.Contains(err.Error(), "not implemented") {
+		t.Fatalf("expected styledComponents to be rejected as not implemented, got %v", err)
+	}
+}
+
+func TestConvertToJSXWithOptionsScriptsAndStyles(t *testing.T) {
+	js := "document.querySelector('.menu').classList.add('ready')"
+
+	out, err := ConvertToJSXWithOptions(`<div class="menu">Menu</div>`, "p { color: red; }", js, nil, nil, Options{})
+	if err != nil {
+		t.Fatalf("ConvertToJSXWithOptions returned error: %v", err)
+	}
+	if !strings.Contains(out, "import React, { useEffect } from 'react'") {
+		t.Fatalf("expected useEffect to be imported, got:\n%s", out)
+	}
+	effect := strings.Index(out, "useEffect(() => {\n    "+js+"\n  }, [])")
+	if effect == -1 || effect > strings.Index(out, "return (") {
+		t.Fatalf("expected inline JS inside a useEffect before the return, got:\n%s", out)
+	}
+	if !strings.Contains(out, "import '../styles/main.css'") {
+		t.Fatalf("expected the stylesheet import, got:\n%s", out)
+	}
+
+	out, err = ConvertToJSXWithOptions(`<div class="menu">Menu</div>`, "p { color: red; }", js, nil, nil, Options{StripScripts: true, StripStyles: true})
+	if err != nil {
+		t.Fatalf("ConvertToJSXWithOptions returned error: %v", err)
+	}
+	if strings.Contains(out, "useEffect") || strings.Contains(out, "querySelector") || strings.Contains(out, "main.css") {
+		t.Fatalf("expected scripts and styles to be stripped, got:\n%s", out)
+	}
+}
+
+func TestConvertToJSXWithOptionsStripsEditorAttributes(t *testing.T) {
+	input := `<div data-wf-id="abc" data-testid="hero" contenteditable="true" style=""><p class="" data-wf-page="1">Hi</p></div>`
+
+	out, err := ConvertToJSXWithOptions(input, "", "", nil, nil, Options{
+		Clean:           true,
+		StripAttributes: []string{"data-wf-*"},
+	})
+	if err != nil {
+		t.Fatalf("ConvertToJSXWithOptions returned error: %v", err)
+	}
+	for _, removed := range []string{"data-wf-id", "data-wf-page", "contentEditable", "contenteditable", "style=", "className"} {
+		if strings.Contains(out, removed) {
+			t.Fatalf("expected %s to be removed, got:\n%s", removed, out)
+		}
+	}
+	if !strings.Contains(out, `data-testid="hero"`) {
+		t.Fatalf("expected data-testid to survive, got:\n%s", out)
+	}
+
+	out, err = ConvertToJSX(input, "", "", nil, nil)
+	if err != nil {
+		t.Fatalf("ConvertToJSX returned error: %v", err)
+	}
+	if !strings.Contains(out, "data-wf-id") {
+		t.Fatalf("expected attributes to be kept without cleaning, got:\n%s", out)
+	}
+
+	if _, err := (Options{StripAttributes: []string{"data-["}}).Normalize(); err == nil {
+		t.Fatalf("expected a malformed pattern to be rejected")
+	}
+}
+
+func TestConvertToJSXWithOptionsIndent(t *testing.T) {
+	input := "<div class=\"menu\">\n\t<p>First line\n\t\tsecond line</p>\n</div>"
+	js := "if (ready) {\n\tstart()\n}"
+
+	out, err := ConvertToJSXWithOptions(input, "", js, nil, nil, Options{Indent: "2"})
+	if err != nil {
+		t.Fatalf("ConvertToJSXWithOptions returned error: %v", err)
+	}
+	if strings.Contains(out, "\t") {
+		t.Fatalf("expected no tabs in 2-space mode, got:\n%s", out)
+	}
+	if !strings.Contains(out, "    if (ready) {\n      start()\n    }") {
+		t.Fatalf("expected the script's tab indentation to become spaces, got:\n%s", out)
+	}
+
+	out, err = ConvertToJSXWithOptions(input, "", js, nil, nil, Options{Indent: "tab"})
+	if err != nil {
+		t.Fatalf("ConvertToJSXWithOptions returned error: %v", err)
+	}
+	if !strings.Contains(out, "\treturn (\n\t\t<>") || strings.Contains(out, "  ") {
+		t.Fatalf("expected tab indentation throughout, got:\n%s", out)
+	}
+}
+
+func TestConvertToJSXWithOptionsSingleFile(t *testing.T) {
+	input := `<section class="hero"><h1>Hello</h1></section>`
+	css := ".hero { background: url(`bg.png`); }"
+	externalCSS := []fetcher.FetchedResource{{URL: "https://cdn.example.com/reset.css", Filename: "reset.css", Content: "* { margin: 0; }"}}
+
+	out, err := ConvertToJSXWithOptions(input, css, "", externalCSS, nil, Options{SingleFile: true, WrapperMode: "none"})
+	if err != nil {
+		t.Fatalf("ConvertToJSXWithOptions returned error: %v", err)
+	}
+	if strings.Contains(out, "import '") {
+		t.Fatalf("expected no stylesheet imports, got:\n%s", out)
+	}
+	if !strings.Contains(out, "const styles = `\n.hero { background: url(\\`bg.png\\`); }\n\n* { margin: 0; }\n`") {
+		t.Fatalf("expected the CSS inline in a styles constant, got:\n%s", out)
+	}
+	if !strings.Contains(out, "    <>\n      <style>{styles}</style>\n      <section className=\"hero\">") {
+		t.Fatalf("expected a style element ahead of the markup, got:\n%s", out)
+	}
+
+	items := `<ul class="features"><li class="feature"><h3>Fast</h3></li><li class="feature"><h3>Small</h3></li><li class="feature"><h3>Safe</h3></li></ul>`
+	out, err = ConvertToJSXWithOptions(items, css, "", nil, nil, Options{SingleFile: true, GenerateProps: true})
+	if err != nil {
+		t.Fatalf("ConvertToJSXWithOptions returned error: %v", err)
+	}
+	if !strings.Contains(out, "const styles = `") || !strings.Contains(out, "    <>\n      <style>{styles}</style>\n      <ul") {
+		t.Fatalf("expected the list component to render the styles too, got:\n%s", out)
+	}
+}
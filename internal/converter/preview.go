@@ -0,0 +1,301 @@
+package converter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/omariomari2/uncluster/internal/formatter"
+	"golang.org/x/net/html"
+)
+
+// htmlAttributeNames maps the JSX attribute names the converter writes back to
+// their HTML names.
+var htmlAttributeNames = func() map[string]string {
+	names := map[string]string{
+		"xmlLang":    "xml:lang",
+		"xmlSpace":   "xml:space",
+		"xmlnsXlink": "xmlns:xlink",
+		// Form controls are converted to uncontrolled ones.
+		"defaultValue":   "value",
+		"defaultChecked": "checked",
+	}
+	for htmlName, jsxName := range jsxAttributeMap {
+		names[jsxName] = htmlName
+	}
+	for htmlName, jsxName := range jsxEventMap {
+		names[jsxName] = htmlName
+	}
+	return names
+}()
+
+// PreviewHTML renders a component produced by the converter back to
+// formatted HTML, so the round trip can be compared with the original page.
+// It is a structural reverse of the converter's transforms, not a React
+// runtime: the markup is taken from the component's last return statement,
+// attributes get their HTML names back, style objects become style strings,
+// event handlers their code, and JSX comments HTML comments. Any other
+// expression is kept as text, and component usages as custom elements. A list
+// rendered with .map, as GenerateProps writes, cannot be expanded without
+// evaluating the props, so it is an error.
+func PreviewHTML(code string) (string, error) {
+	start := strings.LastIndex(code, "return (")
+	if start == -1 {
+		return "", fmt.Errorf("no return statement with JSX markup found")
+	}
+
+	root := &html.Node{Type: html.ElementNode, Data: "div"}
+	p := &jsxParser{src: code, pos: start + len("return (")}
+	if err := p.parseChildren(root, ""); err != nil {
+		return "", err
+	}
+
+	var buf strings.Builder
+	for c := root.FirstChild; c != nil; c = c.NextSibling {
+		if err := html.Render(&buf, c); err != nil {
+			return "", err
+		}
+	}
+	return formatter.Format(buf.String())
+}
+
+// jsxParser reads the JSX subset the converter emits.
+type jsxParser struct {
+	src string
+	pos int
+}
+
+func (p *jsxParser) rest() string {
+	return p.src[p.pos:]
+}
+
+// parseChildren appends the nodes up to the closing tag of closing to parent.
+// The closing tag of a fragment is "</>"; at the top level, where parent is
+// the preview root and closing is "", it stops at the return's closing paren.
+func (p *jsxParser) parseChildren(parent *html.Node, closing string) error {
+	top := parent.Parent == nil && closing == ""
+	for {
+		rest := p.rest()
+		switch {
+		case rest == "":
+			if top {
+				return nil
+			}
+			return fmt.Errorf("unclosed <%s>", strings.TrimSuffix(strings.TrimPrefix(closing, "</"), ">"))
+		case strings.HasPrefix(rest, "</"):
+			end := strings.IndexByte(rest, '>')
+			if end == -1 {
+				return fmt.Errorf("unterminated closing tag at offset %d", p.pos)
+			}
+			tag := strings.TrimSpace(rest[:end+1])
+			if tag != closing {
+				return fmt.Errorf("unexpected %s at offset %d", tag, p.pos)
+			}
+			p.pos += end + 1
+			return nil
+		case strings.HasPrefix(rest, "<>"):
+			p.pos += 2
+			if err := p.parseChildren(parent, "</>"); err != nil {
+				return err
+			}
+		case strings.HasPrefix(rest, "<"):
+			if err := p.parseElement(parent); err != nil {
+				return err
+			}
+		case strings.HasPrefix(rest, "{"):
+			offset := p.pos
+			expr, err := p.readExpression()
+			if err != nil {
+				return err
+			}
+			if jsxMapExpression.MatchString(expr) {
+				return fmt.Errorf("cannot preview the list rendered with .map at offset %d", offset)
+			}
+			parent.AppendChild(expressionNode(expr))
+		case top && strings.HasPrefix(strings.TrimSpace(rest), ")"):
+			return nil
+		default:
+			end := strings.IndexAny(rest, "<{")
+			if end == -1 {
+				end = len(rest)
+			}
+			text := rest[:end]
+			if top {
+				if paren := strings.IndexByte(text, ')'); paren != -1 {
+					text = text[:paren]
+					end = paren
+				}
+			}
+			p.pos += end
+			if strings.TrimSpace(text) != "" {
+				parent.AppendChild(&html.Node{Type: html.TextNode, Data: html.UnescapeString(text)})
+			}
+		}
+	}
+}
+
+var jsxTagName = regexp.MustCompile(`^<([A-Za-z][A-Za-z0-9_.:-]*)`)
+
+var jsxAttributeName = regexp.MustCompile(`^[A-Za-z_:][A-Za-z0-9_.:-]*`)
+
+func (p *jsxParser) parseElement(parent *html.Node) error {
+	m := jsxTagName.FindStringSubmatch(p.rest())
+	if m == nil {
+		return fmt.Errorf("invalid tag at offset %d", p.pos)
+	}
+	p.pos += len(m[0])
+	n := &html.Node{Type: html.ElementNode, Data: m[1]}
+	parent.AppendChild(n)
+
+	for {
+		p.pos += len(p.rest()) - len(strings.TrimLeft(p.rest(), " \t\r\n"))
+		rest := p.rest()
+		switch {
+		case strings.HasPrefix(rest, "/>"):
+			p.pos += 2
+			return nil
+		case strings.HasPrefix(rest, ">"):
+			p.pos++
+			return p.parseChildren(n, "</"+n.Data+">")
+		}
+
+		name := jsxAttributeName.FindString(rest)
+		if name == "" {
+			return fmt.Errorf("invalid attribute in <%s> at offset %d", n.Data, p.pos)
+		}
+		p.pos += len(name)
+
+		value, hasValue := "", false
+		if strings.HasPrefix(p.rest(), "=") {
+			p.pos++
+			var err error
+			if value, err = p.readAttributeValue(); err != nil {
+				return err
+			}
+			hasValue = true
+		}
+		if attr, ok := htmlAttribute(name, value, hasValue); ok {
+			n.Attr = append(n.Attr, attr)
+		}
+	}
+}
+
+// readAttributeValue reads a quoted string or a braced expression, returning
+// strings unquoted and expressions with their braces.
+func (p *jsxParser) readAttributeValue() (string, error) {
+	rest := p.rest()
+	if rest == "" {
+		return "", fmt.Errorf("missing attribute value at offset %d", p.pos)
+	}
+	if quote := rest[0]; quote == '"' || quote == '\'' {
+		end := strings.IndexByte(rest[1:], quote)
+		if end == -1 {
+			return "", fmt.Errorf("unterminated attribute value at offset %d", p.pos)
+		}
+		p.pos += end + 2
+		return html.UnescapeString(rest[1 : end+1]), nil
+	}
+	if rest[0] == '{' {
+		return p.readExpression()
+	}
+	return "", fmt.Errorf("invalid attribute value at offset %d", p.pos)
+}
+
+// readExpression reads a braced expression, skipping over braces inside
+// string and template literals.
+func (p *jsxParser) readExpression() (string, error) {
+	rest := p.rest()
+	depth := 0
+	for i := 0; i < len(rest); i++ {
+		switch c := rest[i]; c {
+		case '\'', '"', '`':
+			for i++; i < len(rest) && rest[i] != c; i++ {
+				if rest[i] == '\\' {
+					i++
+				}
+			}
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				p.pos += i + 1
+				return rest[:i+1], nil
+			}
+		}
+	}
+	return "", fmt.Errorf("unterminated expression at offset %d", p.pos)
+}
+
+var jsxMapExpression = regexp.MustCompile(`^\{\s*[A-Za-z_$][\w$.]*\.map\(`)
+
+var jsxStringLiteral = regexp.MustCompile(`^\{\s*(?:'([^']*)'|"([^"]*)")\s*\}$`)
+
+// expressionNode turns a child expression into a comment for {/* */}, text
+// for a string literal such as {' '}, and its source text otherwise.
+func expressionNode(expr string) *html.Node {
+	inner := strings.TrimSpace(expr[1 : len(expr)-1])
+	if strings.HasPrefix(inner, "/*") && strings.HasSuffix(inner, "*/") {
+		return &html.Node{Type: html.CommentNode, Data: inner[2 : len(inner)-2]}
+	}
+	if m := jsxStringLiteral.FindStringSubmatch(expr); m != nil {
+		return &html.Node{Type: html.TextNode, Data: m[1] + m[2]}
+	}
+	return &html.Node{Type: html.TextNode, Data: expr}
+}
+
+var (
+	jsxStyleEntry    = regexp.MustCompile(`([A-Za-z$_][A-Za-z0-9$_]*|'[^']*')\s*:\s*'([^']*)'`)
+	jsxEventHandler  = regexp.MustCompile(`^\{\s*\(\)\s*=>\s*\{\s*(.*?)\s*\}\s*\}$`)
+	jsxTemplateClass = regexp.MustCompile("^\\{`(.*)`\\}$")
+	jsxTemplateExpr  = regexp.MustCompile(`\$\{[^}]*\}`)
+)
+
+// htmlAttribute reverses convertAttribute for one JSX attribute. ok is false
+// for attributes that render nothing, such as disabled={false}.
+func htmlAttribute(name, value string, hasValue bool) (attr html.Attribute, ok bool) {
+	key := name
+	if htmlName, found := htmlAttributeNames[name]; found {
+		key = htmlName
+	}
+	if !hasValue {
+		return html.Attribute{Key: key}, true
+	}
+	if !strings.HasPrefix(value, "{") {
+		return html.Attribute{Key: key, Val: value}, true
+	}
+
+	switch {
+	case value == "{true}":
+		return html.Attribute{Key: key}, true
+	case value == "{false}":
+		return html.Attribute{}, false
+	case key == "style" && strings.HasPrefix(value, "{{"):
+		var decls []string
+		for _, m := range jsxStyleEntry.FindAllStringSubmatch(value, -1) {
+			decls = append(decls, camelToKebab(strings.Trim(m[1], "'"))+": "+m[2])
+		}
+		return html.Attribute{Key: key, Val: strings.Join(decls, "; ")}, true
+	}
+	if m := jsxEventHandler.FindStringSubmatch(value); m != nil {
+		return html.Attribute{Key: key, Val: m[1]}, true
+	}
+	if m := jsxTemplateClass.FindStringSubmatch(value); m != nil {
+		return html.Attribute{Key: key, Val: strings.Join(strings.Fields(jsxTemplateExpr.ReplaceAllString(m[1], "")), " ")}, true
+	}
+	return html.Attribute{Key: key, Val: value}, true
+}
+
+// camelToKebab reverses kebabToCamel: marginTop becomes margin-top and
+// WebkitTransform -webkit-transform.
+func camelToKebab(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r >= 'A' && r <= 'Z' {
+			b.WriteByte('-')
+			r += 'a' - 'A'
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
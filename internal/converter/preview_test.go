@@ -0,0 +1,51 @@
+package converter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPreviewHTMLReversesConverterTransforms(t *testing.T) {
+	input := `<div class="hero" style="margin-top: 4px; color: red"><label for="email">Email</label><input id="email" readonly><!-- note --><button onclick="go()">Go</button><svg viewBox="0 0 10 10"><path fill-rule="evenodd" d="M0"/></svg></div>`
+
+	code, err := ConvertToJSX(input, "", "", nil, nil)
+	if err != nil {
+		t.Fatalf("ConvertToJSX returned error: %v", err)
+	}
+	preview, err := PreviewHTML(code)
+	if err != nil {
+		t.Fatalf("PreviewHTML returned error: %v", err)
+	}
+
+	for _, want := range []string{
+		`<div class="hero" style="margin-top: 4px; color: red">`,
+		`<label for="email">Email</label>`,
+		`readonly`,
+		`<!-- note -->`,
+		`<button onclick="go()">Go</button>`,
+		`<svg viewBox="0 0 10 10">`,
+		`fill-rule="evenodd"`,
+	} {
+		if !strings.Contains(preview, want) {
+			t.Fatalf("expected preview to contain %q, got:\n%s", want, preview)
+		}
+	}
+	for _, unwanted := range []string{"className", "htmlFor", "React", "<>"} {
+		if strings.Contains(preview, unwanted) {
+			t.Fatalf("expected no %q in preview, got:\n%s", unwanted, preview)
+		}
+	}
+}
+
+func TestPreviewHTMLRejectsCodeWithoutMarkup(t *testing.T) {
+	if _, err := PreviewHTML("export default 1"); err == nil {
+		t.Fatalf("expected an error for code without a return statement")
+	}
+	if _, err := PreviewHTML("function A() {\n  return (\n    <div><span>\n  )\n}"); err == nil {
+		t.Fatalf("expected an error for unclosed elements")
+	}
+	list := "function A({ items = [] }) {\n  return (\n    <ul>\n      {items.map((item, index) => (\n        <li key={index}>{item.text}</li>\n      ))}\n    </ul>\n  )\n}"
+	if _, err := PreviewHTML(list); err == nil {
+		t.Fatalf("expected an error for a list rendered with .map")
+	}
+}
@@ -0,0 +1,311 @@
+package converter
+
+import (
+	"regexp"
+	"strings"
+)
+
+// pageScript is the page's classic scripts split for a component. Function
+// and class declarations, and the names of top-level variables, live at
+// module scope, where the event handlers in the markup can reach them. The
+// remaining statements run in a useEffect hook once the component's markup is
+// in the DOM, assigning those variables as they go.
+type pageScript struct {
+	declarations string // module-level code, ending in a blank line when not empty
+	effect       string // the useEffect hook, ending in a blank line when not empty
+}
+
+var (
+	jsFunctionDeclaration = regexp.MustCompile(`^(?:async\s+)?function\s*\*?\s*[A-Za-z_$][\w$]*\s*\(`)
+	jsClassDeclaration    = regexp.MustCompile(`^class\s+[A-Za-z_$][\w$]*[\s{]`)
+	jsVariableDeclaration = regexp.MustCompile(`^(?:var|let|const)\s+`)
+	// jsDeclarator matches a declarator that binds a single name, such as
+	// "count" or "button = document.querySelector('button')".
+	jsDeclarator = regexp.MustCompile(`^([A-Za-z_$][\w$]*)\s*(?:=\s*([\s\S]+))?$`)
+)
+
+// generatePageScript splits js and the external classic scripts into a
+// pageScript. Each script's top-level statements keep their order in the
+// effect; it is "" when no statement is left for it.
+func (c *JSXConverter) generatePageScript(js string, typescript bool) pageScript {
+	scripts := []string{js}
+	for _, jsFile := range c.ExternalJS {
+		if jsFile.Error == nil && !jsFile.IsModule() {
+			scripts = append(scripts, jsFile.Content)
+		}
+	}
+
+	var declarations []string
+	var body strings.Builder
+	for _, script := range scripts {
+		var effect []string
+		for _, statement := range splitStatements(dedent(script)) {
+			hoisted, rest := splitDeclaration(statement, typescript)
+			if hoisted != "" {
+				declarations = append(declarations, hoisted)
+			}
+			if rest != "" {
+				effect = append(effect, rest)
+			}
+		}
+		if len(effect) == 0 {
+			continue
+		}
+		if body.Len() > 0 {
+			body.WriteString("\n")
+		}
+		for _, line := range strings.Split(strings.Join(effect, "\n"), "\n") {
+			body.WriteString(strings.TrimRight("    "+line, " \t") + "\n")
+		}
+	}
+
+	var script pageScript
+	if len(declarations) > 0 {
+		script.declarations = strings.Join(declarations, "\n\n") + "\n\n"
+	}
+	if body.Len() > 0 {
+		script.effect = "  useEffect(() => {\n" + body.String() + "  }, [])\n\n"
+	}
+	return script
+}
+
+// splitDeclaration returns the module-level part of a top-level statement
+// and the part left to run in the effect. Function and class declarations
+// move to module scope whole. A variable declaration leaves its names there
+// and its initializers in the effect, where the DOM they may query exists;
+// destructuring declarations stay in the effect as written.
+func splitDeclaration(statement string, typescript bool) (hoisted, rest string) {
+	code := stripLeadingComments(statement)
+	comments := strings.TrimSpace(statement[:len(statement)-len(code)])
+	if jsFunctionDeclaration.MatchString(code) || jsClassDeclaration.MatchString(code) {
+		return statement, ""
+	}
+	keyword := jsVariableDeclaration.FindString(code)
+	if keyword == "" {
+		return "", statement
+	}
+
+	var names, assignments []string
+	for _, declarator := range splitTopLevel(strings.TrimSuffix(code[len(keyword):], ";"), ',') {
+		m := jsDeclarator.FindStringSubmatch(strings.TrimSpace(declarator))
+		if m == nil {
+			return "", statement
+		}
+		name := m[1]
+		if typescript {
+			name += ": any"
+		}
+		names = append(names, name)
+		if m[2] != "" {
+			assignments = append(assignments, m[1]+" = "+strings.TrimSpace(m[2]))
+		}
+	}
+
+	hoisted = "let " + strings.Join(names, ", ")
+	if len(assignments) > 0 {
+		rest = strings.Join(assignments, ", ")
+		if comments != "" {
+			rest = comments + "\n" + rest
+		}
+	} else if comments != "" {
+		hoisted = comments + "\n" + hoisted
+	}
+	return hoisted, rest
+}
+
+// splitStatements splits js into its top-level statements. A statement ends
+// at a semicolon outside brackets, or at a line break when neither the line
+// nor the next one reads as a continuation, as with automatic semicolon
+// insertion. Comments stay with the statement that follows them.
+func splitStatements(js string) []string {
+	var statements []string
+	start := 0
+	var last, beforeLast byte
+	end := func(i int) {
+		if statement := strings.TrimSpace(js[start:i]); statement != "" {
+			statements = append(statements, statement)
+		}
+		start = i
+		last, beforeLast = 0, 0
+	}
+
+	scanJS(js, func(i, depth int) {
+		ch := js[i]
+		switch {
+		case depth == 0 && ch == ';':
+			end(i + 1)
+		case depth == 0 && ch == '\n':
+			if endsStatement(last, beforeLast) && !continuesStatement(js[i+1:]) {
+				end(i)
+			}
+		case !isSpace(rune(ch)):
+			last, beforeLast = ch, last
+		}
+	})
+	end(len(js))
+	return statements
+}
+
+// endsStatement reports whether a line whose last two code characters are
+// beforeLast and last can end a statement: it is not empty and doesn't stop
+// at an operator, apart from ++ and --.
+func endsStatement(last, beforeLast byte) bool {
+	if last == 0 {
+		return false
+	}
+	if (last == '+' || last == '-') && beforeLast == last {
+		return true
+	}
+	return strings.IndexByte("=([{,:?+-*/%&|^!~<>.", last) < 0
+}
+
+// continuesStatement reports whether rest, the code after a line break,
+// carries on the statement before it, as ".then(...)" or "else {" do.
+func continuesStatement(rest string) bool {
+	rest = strings.TrimLeft(rest, " \t\r\n")
+	if rest == "" || strings.HasPrefix(rest, "//") || strings.HasPrefix(rest, "/*") {
+		return false
+	}
+	if strings.IndexByte(".,?:+-*/%&|^=)]}", rest[0]) >= 0 {
+		return !strings.HasPrefix(rest, "++") && !strings.HasPrefix(rest, "--")
+	}
+	for _, word := range []string{"else", "catch", "finally"} {
+		if strings.HasPrefix(rest, word) && (len(rest) == len(word) || !isIdentifierByte(rest[len(word)])) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitTopLevel splits s at each sep outside brackets, strings, and comments.
+func splitTopLevel(s string, sep byte) []string {
+	var parts []string
+	start := 0
+	scanJS(s, func(i, depth int) {
+		if depth == 0 && s[i] == sep {
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	})
+	return append(parts, s[start:])
+}
+
+// scanJS calls visit with the index and bracket depth of each character of js
+// outside comments, and of the closing quote of each string and template
+// literal. A bracket is visited at the depth outside it. Regular expression
+// literals are not recognized, so one holding a quote or an unbalanced
+// bracket throws the scan off; telling them from division needs a parser.
+func scanJS(js string, visit func(i, depth int)) {
+	depth := 0
+	for i := 0; i < len(js); i++ {
+		ch := js[i]
+		switch {
+		case ch == '/' && strings.HasPrefix(js[i:], "//"):
+			for i+1 < len(js) && js[i+1] != '\n' {
+				i++
+			}
+		case ch == '/' && strings.HasPrefix(js[i:], "/*"):
+			end := strings.Index(js[i+2:], "*/")
+			if end < 0 {
+				return
+			}
+			i += end + 3
+		case ch == '\'' || ch == '"' || ch == '`':
+			i = SkipJSString(js, i)
+			if i < len(js) {
+				visit(i, depth)
+			}
+		default:
+			if strings.IndexByte(")]}", ch) >= 0 && depth > 0 {
+				depth--
+			}
+			visit(i, depth)
+			if strings.IndexByte("([{", ch) >= 0 {
+				depth++
+			}
+		}
+	}
+}
+
+// SkipJSString returns the index of the quote closing the string or template
+// literal opened at js[i], skipping escapes and ${...} substitutions. An
+// unterminated string ends before the line break, a template at the end of js.
+func SkipJSString(js string, i int) int {
+	quote := js[i]
+	for j := i + 1; j < len(js); j++ {
+		switch {
+		case js[j] == '\\':
+			j++
+		case js[j] == quote:
+			return j
+		case js[j] == '\n' && quote != '`':
+			return j - 1
+		case quote == '`' && strings.HasPrefix(js[j:], "${"):
+			depth := 0
+			for j++; j < len(js); j++ {
+				if c := js[j]; c == '\'' || c == '"' || c == '`' {
+					j = SkipJSString(js, j)
+				} else if c == '{' {
+					depth++
+				} else if c == '}' {
+					if depth--; depth == 0 {
+						break
+					}
+				}
+			}
+		}
+	}
+	return len(js)
+}
+
+// stripLeadingComments returns s from its first character of code.
+func stripLeadingComments(s string) string {
+	for {
+		s = strings.TrimLeft(s, " \t\r\n")
+		switch {
+		case strings.HasPrefix(s, "//"):
+			end := strings.IndexByte(s, '\n')
+			if end < 0 {
+				return ""
+			}
+			s = s[end:]
+		case strings.HasPrefix(s, "/*"):
+			end := strings.Index(s, "*/")
+			if end < 0 {
+				return ""
+			}
+			s = s[end+2:]
+		default:
+			return s
+		}
+	}
+}
+
+// dedent removes the indentation all of js's non-blank lines share, which a
+// script keeps from its place in the page.
+func dedent(js string) string {
+	lines := strings.Split(js, "\n")
+	prefix := ""
+	first := true
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		if first {
+			prefix, first = indent, false
+			continue
+		}
+		for !strings.HasPrefix(indent, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	for i, line := range lines {
+		lines[i] = strings.TrimPrefix(line, prefix)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func isIdentifierByte(b byte) bool {
+	return b == '_' || b == '$' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b >= '0' && b <= '9'
+}
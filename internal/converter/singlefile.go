@@ -0,0 +1,61 @@
+package converter
+
+import (
+	"strings"
+
+	"github.com/omariomari2/uncluster/internal/fetcher"
+)
+
+// styleElement renders the single-file stylesheet held in the styles constant.
+const styleElement = "<style>{styles}</style>"
+
+// inlineStylesDeclaration returns a styles constant holding css and the
+// downloaded external stylesheets, in the order generateCSSImports imports
+// them, as a template literal. It returns "" when there is no CSS.
+func inlineStylesDeclaration(css string, externalCSS []fetcher.FetchedResource) string {
+	var sheets []string
+	if strings.TrimSpace(css) != "" {
+		sheets = append(sheets, strings.TrimSpace(css))
+	}
+	for _, cssFile := range externalCSS {
+		if cssFile.Error == nil && cssFile.Filename != "" && strings.TrimSpace(cssFile.Content) != "" {
+			sheets = append(sheets, strings.TrimSpace(cssFile.Content))
+		}
+	}
+	if len(sheets) == 0 {
+		return ""
+	}
+
+	escaped := strings.NewReplacer("\\", "\\\\", "`", "\\`", "${", "\\${").Replace(strings.Join(sheets, "\n\n"))
+	return "const styles = `\n" + escaped + "\n`\n"
+}
+
+// addStyleElement puts the styles constant above function name and renders it
+// first in the component's returned markup, adding a fragment around a single
+// root element.
+func addStyleElement(code, name, decl string) string {
+	code = strings.Replace(code, "\nfunction "+name+"(", "\n"+decl+"\nfunction "+name+"(", 1)
+
+	const open = "  return (\n"
+	start := strings.Index(code, open)
+	if start == -1 {
+		return code
+	}
+	start += len(open)
+	if rest := code[start:]; strings.HasPrefix(rest, "    <>\n") {
+		return code[:start] + "    <>\n      " + styleElement + "\n" + rest[len("    <>\n"):]
+	}
+
+	end := strings.Index(code[start:], "\n  )\n")
+	if end == -1 {
+		return code
+	}
+	end += start
+	lines := strings.Split(code[start:end], "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = "  " + line
+		}
+	}
+	return code[:start] + "    <>\n      " + styleElement + "\n" + strings.Join(lines, "\n") + "\n    </>" + code[end:]
+}
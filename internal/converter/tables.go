@@ -0,0 +1,84 @@
+package converter
+
+import (
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// strayTableMarkup matches input whose first tag is table-internal (a row,
+// cell, or row group) with no enclosing <table>. html.Parse would drop those
+// tags and keep only their text, so such input is wrapped in a table first.
+var strayTableMarkup = regexp.MustCompile(`(?is)^\s*(?:<!--.*?-->\s*)*<(tr|td|th|thead|tbody|tfoot|caption|colgroup|col)[\s>/]`)
+
+// parseHTMLForJSX parses markup for conversion, keeping table structure that
+// React expects: stray rows end up inside <table><tbody>, and presentational
+// cell attributes React does not support are turned into styles. Form
+// controls are made uncontrolled; see normalizeFormControls.
+func parseHTMLForJSX(htmlContent string) (*html.Node, error) {
+	if strayTableMarkup.MatchString(htmlContent) {
+		htmlContent = "<table>" + htmlContent + "</table>"
+	}
+
+	doc, err := html.Parse(strings.NewReader(htmlContent))
+	if err != nil {
+		return nil, err
+	}
+
+	normalizeTableAttributes(doc)
+	normalizeFormControls(doc)
+	return doc, nil
+}
+
+// tableStyleAttributes maps legacy presentational attributes on table
+// elements to the CSS property that replaces them.
+var tableStyleAttributes = map[string]string{
+	"valign":  "vertical-align",
+	"bgcolor": "background-color",
+	"align":   "text-align",
+}
+
+var tableElements = map[string]bool{
+	"table": true, "thead": true, "tbody": true, "tfoot": true,
+	"tr": true, "td": true, "th": true, "col": true, "colgroup": true,
+}
+
+// normalizeTableAttributes moves valign, bgcolor, and align on table
+// elements into the style attribute. cellpadding, cellspacing, colspan,
+// rowspan, and scope are left alone; React supports them directly.
+func normalizeTableAttributes(n *html.Node) {
+	if n.Type == html.ElementNode && tableElements[n.Data] {
+		var styles []string
+		attrs := n.Attr[:0]
+		for _, attr := range n.Attr {
+			prop, ok := tableStyleAttributes[attr.Key]
+			// align on <table> positions the table itself, not its text.
+			if ok && !(attr.Key == "align" && n.Data == "table") && attr.Namespace == "" {
+				styles = append(styles, prop+": "+strings.TrimSpace(attr.Val))
+				continue
+			}
+			attrs = append(attrs, attr)
+		}
+		n.Attr = attrs
+
+		if len(styles) > 0 {
+			merged := strings.Join(styles, "; ")
+			for i, attr := range n.Attr {
+				if attr.Key == "style" {
+					existing := strings.TrimRight(strings.TrimSpace(attr.Val), ";")
+					if existing != "" {
+						merged = existing + "; " + merged
+					}
+					n.Attr = append(n.Attr[:i], n.Attr[i+1:]...)
+					break
+				}
+			}
+			n.Attr = append(n.Attr, html.Attribute{Key: "style", Val: merged})
+		}
+	}
+
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		normalizeTableAttributes(c)
+	}
+}
@@ -0,0 +1,41 @@
+package converter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConvertHeaderlessTableKeepsReactStructure(t *testing.T) {
+	input := `<tr><th scope="row" valign="top">Plan</th><td align="right" bgcolor="#eee" style="color: red">$10</td></tr>
+<tr><th scope="row">Seats</th><td colspan="2">5</td></tr>`
+
+	out, err := ConvertSectionToTSX(input, "Pricing")
+	if err != nil {
+		t.Fatalf("ConvertSectionToTSX returned error: %v", err)
+	}
+
+	for _, want := range []string{
+		"<table>",
+		"<tbody>",
+		`<th scope="row" style={{verticalAlign: 'top'}}>Plan</th>`,
+		`<td style={{color: 'red', textAlign: 'right', backgroundColor: '#eee'}}>$10</td>`,
+		`<td colSpan="2">5</td>`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in output, got:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "valign") || strings.Contains(out, "bgcolor") {
+		t.Fatalf("expected presentational attributes to be converted, got:\n%s", out)
+	}
+}
+
+func TestConvertTableKeepsCellPaddingOnTable(t *testing.T) {
+	out, err := ConvertToJSX(`<table cellpadding="4" cellspacing="0" align="center"><tr><td>a</td></tr></table>`, "", "", nil, nil)
+	if err != nil {
+		t.Fatalf("ConvertToJSX returned error: %v", err)
+	}
+	if !strings.Contains(out, `<table cellPadding="4" cellSpacing="0" align="center"><tbody><tr><td>a</td></tr></tbody></table>`) {
+		t.Fatalf("unexpected table output:\n%s", out)
+	}
+}
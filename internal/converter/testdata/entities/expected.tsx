@@ -0,0 +1,12 @@
+import React from 'react'
+
+
+function MainComponent() {
+  return (
+    <>
+      <p className="legal">© 2024 Acme Inc. — &lt;all&gt; rights &#123;reserved&#125; & more</p>
+    </>
+  )
+}
+
+export default MainComponent
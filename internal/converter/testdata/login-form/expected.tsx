@@ -0,0 +1,12 @@
+import React from 'react'
+
+
+function MainComponent() {
+  return (
+    <>
+      <form className="login" action="/login" method="post" noValidate={true}><label htmlFor="email">Email</label><input id="email" type="email" name="email" defaultValue="ada@example.com" autoComplete="username" required={true} /><label htmlFor="password">Password</label><input id="password" type="password" name="password" autoComplete="current-password" minLength="8" /><label><input type="checkbox" name="remember" value="yes" defaultChecked={true} />Remember me</label><select name="region" defaultValue="us"><option value="eu">Europe</option><option value="us">United States</option></select><textarea name="note" defaultValue={"Say \"hi\""}></textarea><input type="search" name="q" value="locked" readOnly={true} /><button type="reset" disabled={true}>Clear</button><button type="submit">Sign in</button></form>
+    </>
+  )
+}
+
+export default MainComponent
@@ -0,0 +1,12 @@
+import React from 'react'
+
+
+function MainComponent() {
+  return (
+    <>
+      <section style={{backgroundColor: '#fff', marginTop: '10px', WebkitTransition: 'opacity 0.2s'}}><label htmlFor="email" className="field-label" style={{fontWeight: 'bold'}}>Email</label><input id="email" type="email" tabIndex="1" readOnly={true} /></section>
+    </>
+  )
+}
+
+export default MainComponent
@@ -0,0 +1,12 @@
+import React from 'react'
+
+
+function MainComponent() {
+  return (
+    <>
+      <button className="icon-button" aria-label="Download"><svg xmlns="http://www.w3.org/2000/svg" xmlnsXlink="http://www.w3.org/1999/xlink" className="icon" viewBox="0 0 24 24" width="20" height="20" aria-hidden="true" focusable="false" xmlSpace="preserve"><title>Download</title><defs><linearGradient id="grad" x1="0" y1="0" x2="0" y2="1" gradientUnits="objectBoundingBox"><stop offset="0" stopColor="#4f46e5" stopOpacity="1" /><stop offset="1" stopColor="#7c3aed" /></linearGradient><clipPath id="clip"><rect width="24" height="24" rx="4" /></clipPath></defs><g clipPath="url(#clip)" fill="url(#grad)" fillRule="evenodd" clipRule="evenodd"><path d="M12 3v12m0 0l-4-4m4 4l4-4" stroke="#fff" strokeWidth="2" strokeLinecap="round" strokeLinejoin="round" /><use href="#base" x="0" y="18" /></g></svg>Download</button>
+    </>
+  )
+}
+
+export default MainComponent
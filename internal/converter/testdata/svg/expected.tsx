@@ -0,0 +1,12 @@
+import React from 'react'
+
+
+function MainComponent() {
+  return (
+    <>
+      <div className="icon-wrap"><svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 24 24" width="24" height="24" fill="none" stroke="currentColor" strokeWidth="2" strokeLinecap="round"><path d="M5 12h14" /><circle cx="12" cy="12" r="10" /></svg></div>
+    </>
+  )
+}
+
+export default MainComponent
@@ -0,0 +1,12 @@
+import React from 'react'
+
+
+function MainComponent() {
+  return (
+    <>
+      <table className="pricing" cellPadding="4"><tbody><tr><td colSpan="2">Plan</td><td>Price</td></tr><tr><td>Basic</td><td>Monthly</td><td>$5</td></tr></tbody></table>
+    </>
+  )
+}
+
+export default MainComponent
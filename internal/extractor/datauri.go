@@ -0,0 +1,88 @@
+package extractor
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// defaultDataURIThreshold is the size, in bytes, above which a data URI is
+// moved into its own file. Smaller ones cost less inline than a request.
+const defaultDataURIThreshold = 4 * 1024
+
+// dataURIExtensions maps the media types commonly inlined as data URIs to a
+// file extension.
+var dataURIExtensions = map[string]string{
+	"image/png":     ".png",
+	"image/jpeg":    ".jpg",
+	"image/gif":     ".gif",
+	"image/webp":    ".webp",
+	"image/avif":    ".avif",
+	"image/svg+xml": ".svg",
+	"image/x-icon":  ".ico",
+}
+
+// externalizeDataURIs writes src and poster data URIs longer than threshold
+// bytes to assets/data-image-N.ext and points the attribute at the file.
+// Identical URIs share one file. Data URIs that cannot be decoded are left
+// inline.
+func externalizeDataURIs(doc *html.Node, threshold int) []LocalAsset {
+	var assets []LocalAsset
+	pathByURI := make(map[string]string)
+
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			for i, attr := range n.Attr {
+				if (attr.Key != "src" && attr.Key != "poster") || len(attr.Val) <= threshold || !strings.HasPrefix(attr.Val, "data:") {
+					continue
+				}
+				local, ok := pathByURI[attr.Val]
+				if !ok {
+					if content, mimeType, err := decodeDataURI(attr.Val); err == nil {
+						ext := dataURIExtensions[mimeType]
+						if ext == "" {
+							ext = ".bin"
+						}
+						local = fmt.Sprintf("assets/data-image-%d%s", len(assets)+1, ext)
+						assets = append(assets, LocalAsset{Path: local, Content: content, MIME: mimeType})
+					}
+					pathByURI[attr.Val] = local
+				}
+				if local != "" {
+					n.Attr[i].Val = local
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+
+	return assets
+}
+
+// decodeDataURI returns the payload and media type of a data: URI.
+func decodeDataURI(uri string) ([]byte, string, error) {
+	header, payload, ok := strings.Cut(strings.TrimPrefix(uri, "data:"), ",")
+	if !ok {
+		return nil, "", fmt.Errorf("data URI has no payload")
+	}
+
+	params := strings.Split(header, ";")
+	mimeType := strings.ToLower(strings.TrimSpace(params[0]))
+	if mimeType == "" {
+		mimeType = "text/plain"
+	}
+
+	if strings.EqualFold(params[len(params)-1], "base64") {
+		content, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(payload), ""))
+		return content, mimeType, err
+	}
+	content, err := url.PathUnescape(payload)
+	return []byte(content), mimeType, err
+}
@@ -0,0 +1,129 @@
+package extractor
+
+import (
+	"strings"
+
+	"github.com/omariomari2/uncluster/internal/fetcher"
+	"golang.org/x/net/html"
+)
+
+// cssSegment is a top-level piece of a stylesheet with the whitespace before
+// it: a rule or at-rule block, a statement such as @import, or a comment.
+type cssSegment struct {
+	text  string
+	block string // the trimmed block, for rule blocks only
+}
+
+// splitCSS splits css into top-level segments without parsing selectors or
+// declarations. Braces inside strings and comments are skipped.
+func splitCSS(css string) []cssSegment {
+	var segments []cssSegment
+	start, depth := 0, 0
+	for i := 0; i < len(css); i++ {
+		switch c := css[i]; {
+		case c == '/' && i+1 < len(css) && css[i+1] == '*':
+			if end := strings.Index(css[i+2:], "*/"); end == -1 {
+				i = len(css) - 1
+			} else {
+				i += end + 3
+			}
+			if depth == 0 {
+				segments = append(segments, cssSegment{text: css[start : i+1]})
+				start = i + 1
+			}
+		case c == '"' || c == '\'':
+			for i++; i < len(css) && css[i] != c; i++ {
+				if css[i] == '\\' {
+					i++
+				}
+			}
+		case c == '{':
+			depth++
+		case c == '}' && depth > 0:
+			depth--
+			if depth == 0 {
+				text := css[start : i+1]
+				segments = append(segments, cssSegment{text: text, block: strings.TrimSpace(text)})
+				start = i + 1
+			}
+		case c == ';' && depth == 0:
+			segments = append(segments, cssSegment{text: css[start : i+1]})
+			start = i + 1
+		}
+	}
+	if start < len(css) {
+		segments = append(segments, cssSegment{text: css[start:]})
+	}
+	return segments
+}
+
+// dedupeCSS removes rule blocks that appear again, byte for byte, later in
+// the page's stylesheets: the inline blocks and the downloaded external
+// sheets, taken in the order doc links them. Only the last copy is kept, the
+// one that wins the cascade, so the page renders the same. Statements such as
+// @import, comments, and blocks that differ in any way are left alone.
+func dedupeCSS(doc *html.Node, inlineCSS []InlineResource, externalCSS []fetcher.FetchedResource) {
+	sheets := make(map[string]*string)
+	for i := range inlineCSS {
+		sheets[inlineCSS[i].Path] = &inlineCSS[i].Content
+	}
+	for i := range externalCSS {
+		if externalCSS[i].Error == nil && externalCSS[i].Filename != "" {
+			sheets["external/css/"+externalCSS[i].Filename] = &externalCSS[i].Content
+		}
+	}
+
+	var ordered []*string
+	seen := make(map[*string]bool)
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.Data == "link" {
+			if sheet, ok := sheets[getAttribute(n, "href")]; ok && !seen[sheet] {
+				seen[sheet] = true
+				ordered = append(ordered, sheet)
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+
+	split := make([][]cssSegment, len(ordered))
+	last := make(map[string][2]int)
+	for i, sheet := range ordered {
+		split[i] = splitCSS(*sheet)
+		for j, segment := range split[i] {
+			if segment.block != "" {
+				last[segment.block] = [2]int{i, j}
+			}
+		}
+	}
+
+	for i, sheet := range ordered {
+		var kept strings.Builder
+		removed := false
+		for j, segment := range split[i] {
+			if segment.block != "" && last[segment.block] != [2]int{i, j} {
+				removed = true
+				continue
+			}
+			kept.WriteString(segment.text)
+		}
+		if removed {
+			*sheet = strings.TrimLeft(kept.String(), "\n")
+		}
+	}
+}
+
+// pooledCSS joins the inline stylesheets the way extraction pools them.
+func pooledCSS(inlineCSS []InlineResource) string {
+	var css strings.Builder
+	for _, resource := range inlineCSS {
+		css.WriteString(resource.Content)
+		if !strings.HasSuffix(resource.Content, "\n") {
+			css.WriteString("\n")
+		}
+	}
+	return css.String()
+}
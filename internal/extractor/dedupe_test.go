@@ -0,0 +1,62 @@
+package extractor
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/omariomari2/uncluster/internal/fetcher"
+)
+
+func TestSplitCSSKeepsNestedBlocksWhole(t *testing.T) {
+	css := "@import url(\"a.css\");\n/* { not a block } */\n.a { content: \"}\"; }\n@media (min-width: 600px) {\n  .b { color: red; }\n}\n"
+	var blocks []string
+	for _, segment := range splitCSS(css) {
+		if segment.block != "" {
+			blocks = append(blocks, segment.block)
+		}
+	}
+	want := []string{`.a { content: "}"; }`, "@media (min-width: 600px) {\n  .b { color: red; }\n}"}
+	if len(blocks) != len(want) || blocks[0] != want[0] || blocks[1] != want[1] {
+		t.Fatalf("expected blocks %q, got %q", want, blocks)
+	}
+}
+
+func TestExtractWithOptionsDedupesCSS(t *testing.T) {
+	defer fetcher.SetPolicy(fetcher.SetPolicy(fetcher.Policy{AllowPrivate: true}))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(".btn { color: blue; }\n.card { padding: 1rem; }\n"))
+	}))
+	defer server.Close()
+
+	input := `<html><head><style>.card { padding: 1rem; }
+.hero { margin: 0; }</style><link rel="stylesheet" href="` + server.URL + `/site.css"><style>.btn { color: blue; }</style></head><body></body></html>`
+
+	plain, err := ExtractWithOptions(input, ExtractOptions{})
+	if err != nil {
+		t.Fatalf("ExtractWithOptions returned error: %v", err)
+	}
+	if strings.Count(plain.CSS+plain.ExternalCSS[0].Content, ".card") != 2 {
+		t.Fatalf("expected duplicates to be kept without DedupeCSS")
+	}
+
+	deduped, err := ExtractWithOptions(input, ExtractOptions{DedupeCSS: true})
+	if err != nil {
+		t.Fatalf("ExtractWithOptions returned error: %v", err)
+	}
+	// .card is dropped from the first inline block, which the stylesheet
+	// follows; .btn from the stylesheet, which the second inline block follows.
+	if got := deduped.InlineCSS[0].Content; got != ".hero { margin: 0; }" {
+		t.Fatalf("expected .card to be dropped from the first <style>, got %q", got)
+	}
+	if got := deduped.ExternalCSS[0].Content; got != ".card { padding: 1rem; }\n" {
+		t.Fatalf("expected .btn to be dropped from the stylesheet, got %q", got)
+	}
+	if got := deduped.InlineCSS[1].Content; got != ".btn { color: blue; }" {
+		t.Fatalf("expected the last .btn to be kept, got %q", got)
+	}
+	if deduped.CSS != ".hero { margin: 0; }\n.btn { color: blue; }\n" {
+		t.Fatalf("expected the pooled CSS to match the inline files, got %q", deduped.CSS)
+	}
+}
@@ -2,10 +2,16 @@ package extractor
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"github.com/omariomari2/uncluster/internal/fetcher"
 	"github.com/omariomari2/uncluster/internal/formatter"
+	"github.com/omariomari2/uncluster/internal/htmlparse"
+	"github.com/omariomari2/uncluster/internal/logging"
+	"net/url"
+	"path"
 	"strings"
+	"time"
 
 	"golang.org/x/net/html"
 )
@@ -19,23 +25,155 @@ type ExtractedContent struct {
 	ExternalCSS []fetcher.FetchedResource
 	ExternalJS  []fetcher.FetchedResource
 	LocalAssets []LocalAsset
+	Head        HeadMetadata
+
+	ExtractedAt  time.Time // when extraction ran, recorded in the export manifest
+	SourceLength int       // byte length of the HTML that was extracted
 }
 
 type InlineResource struct {
 	Path    string
 	Content string
+	Type    string // script type attribute, e.g. "module"; empty for classic scripts and CSS
 }
 
-// LocalAsset holds a binary file (image, font, SVG, etc.) that was either
-// bundled in an uploaded ZIP or downloaded by the URL scraper.
+// LocalAsset holds a binary file (image, font, SVG, etc.) that was bundled in
+// an uploaded ZIP, downloaded by the URL scraper, or decoded from a data URI.
 type LocalAsset struct {
 	Path    string // relative path as it should appear in the export, e.g. "assets/logo.png"
 	Content []byte // raw binary content
 	MIME    string // e.g. "image/png", "font/woff2"
+	URL     string // absolute URL the asset was downloaded from, if any
+}
+
+// ExtractOptions tunes ExtractWithOptions. The zero value matches Extract.
+type ExtractOptions struct {
+	// CSSFileName and JSFileName name the files inline <style> and <script>
+	// blocks are moved to. Each block gets its own numbered file, so the
+	// default "style.css" produces inline/style-1.css, inline/style-2.css, ...
+	CSSFileName string
+	JSFileName  string
+	// ExternalizeDataURIs writes each data URI in a src or poster attribute
+	// longer than DataURIThreshold bytes to assets/ as its own file and points
+	// the attribute at it. The zero value keeps every data URI inline.
+	ExternalizeDataURIs bool
+	// DataURIThreshold is the length, in bytes, above which
+	// ExternalizeDataURIs moves a data URI to a file. Zero or less means 4 KB.
+	DataURIThreshold int
+	// Timeout bounds the time spent downloading external stylesheets,
+	// scripts, and linked assets, which are fetched one after another. Fetches
+	// still running when it expires, and those not started, are abandoned and
+	// recorded with an error wrapping fetcher.ErrDeadline; the export goes on
+	// with what was downloaded. Zero means no overall limit.
+	Timeout time.Duration
+	// KeepExternal leaves external stylesheets, scripts, and icon and
+	// manifest links at their original URLs instead of downloading them.
+	// Nothing is fetched; the resources are reported with a SkipReason, like
+	// those the fetch policy skips, so exports link the CDN copies directly.
+	KeepExternal bool
+	// SkipCSS leaves <style> blocks and stylesheet links as they are: no CSS
+	// is extracted or downloaded and no link is injected. SkipJS does the same
+	// for scripts. The zero value extracts both.
+	SkipCSS bool
+	SkipJS  bool
+	// DedupeCSS drops rule blocks that appear again, byte for byte, in a later
+	// inline or downloaded stylesheet, keeping the last copy. Blocks that
+	// differ at all, even in formatting, are kept.
+	DedupeCSS bool
+	// HashFilenames names downloaded stylesheets and scripts after a hash of
+	// their content, such as external/css/theme-1a2b3c4d.css, instead of
+	// numbering repeated names in fetch order (see fetcher.HashFilenames).
+	HashFilenames bool
+}
+
+// Normalize fills in the default file names and rejects names that are not
+// plain file names.
+func (o ExtractOptions) Normalize() (ExtractOptions, error) {
+	var err error
+	if o.CSSFileName, err = normalizeInlineFileName(o.CSSFileName, "style.css", "cssFileName"); err != nil {
+		return o, err
+	}
+	if o.JSFileName, err = normalizeInlineFileName(o.JSFileName, "script.js", "jsFileName"); err != nil {
+		return o, err
+	}
+	if o.DataURIThreshold <= 0 {
+		o.DataURIThreshold = defaultDataURIThreshold
+	}
+	return o, nil
+}
+
+func normalizeInlineFileName(name, def, option string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return def, nil
+	}
+	if strings.ContainsAny(name, `/\`) || strings.HasPrefix(name, ".") {
+		return "", fmt.Errorf("invalid %s %q (expected a plain file name such as %s)", option, name, def)
+	}
+	if path.Ext(name) == "" {
+		name += path.Ext(def)
+	}
+	return name, nil
+}
+
+// nextInlineFilePath advances index to the next inline file path under
+// fileName that the document does not already reference.
+func nextInlineFilePath(fileName string, index *int, taken map[string]bool) string {
+	for {
+		*index++
+		if filename := inlineFilePath(fileName, *index); !taken[filename] {
+			return filename
+		}
+	}
+}
+
+// referencedPaths returns the href of every <link> and the src of every
+// <script> in the document.
+func referencedPaths(n *html.Node) map[string]bool {
+	paths := make(map[string]bool)
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			if n.Data == "link" && getAttribute(n, "href") != "" {
+				paths[getAttribute(n, "href")] = true
+			} else if n.Data == "script" && getAttribute(n, "src") != "" {
+				paths[getAttribute(n, "src")] = true
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return paths
+}
+
+// inlineFilePath returns the path of the index-th (1-based) inline block
+// written under fileName, e.g. inline/style-2.css for "style.css".
+func inlineFilePath(fileName string, index int) string {
+	ext := path.Ext(fileName)
+	return fmt.Sprintf("inline/%s-%d%s", strings.TrimSuffix(fileName, ext), index, ext)
 }
 
 func Extract(htmlContent string) (*ExtractedContent, error) {
-	doc, err := html.Parse(strings.NewReader(htmlContent))
+	return ExtractWithOptions(htmlContent, ExtractOptions{})
+}
+
+// ExtractWithOptions extracts htmlContent like Extract, applying opts.
+func ExtractWithOptions(htmlContent string, opts ExtractOptions) (*ExtractedContent, error) {
+	return ExtractContext(context.Background(), htmlContent, opts)
+}
+
+// ExtractContext is ExtractWithOptions bounded by ctx: external resources are
+// fetched under ctx, further limited by opts.Timeout, and progress is logged
+// with the request ID ctx carries.
+func ExtractContext(ctx context.Context, htmlContent string, opts ExtractOptions) (*ExtractedContent, error) {
+	opts, err := opts.Normalize()
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := html.Parse(strings.NewReader(htmlparse.ToUTF8(htmlContent, "")))
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse HTML: %w", err)
 	}
@@ -48,21 +186,63 @@ func Extract(htmlContent string) (*ExtractedContent, error) {
 	cssIndex := 0
 	jsIndex := 0
 
-	extractInlineResources(doc, &cssContent, &jsContent, &inlineCSS, &inlineJS, &cssIndex, &jsIndex)
+	// HTML that was exported before already links inline/style-1.css and so
+	// on; new inline blocks are numbered past those so they don't collide.
+	taken := referencedPaths(doc)
+	extractInlineResources(doc, &opts, taken, &cssContent, &jsContent, &inlineCSS, &inlineJS, &cssIndex, &jsIndex)
+
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
 
 	cssURLs, jsURLs := findExternalResourceURLs(doc)
+	if opts.SkipCSS {
+		cssURLs = nil
+	}
+	if opts.SkipJS {
+		jsURLs = nil
+	}
+	var localAssets []LocalAsset
+	if !opts.KeepExternal {
+		localAssets = fetchLinkedAssets(ctx, doc)
+	}
+	if opts.ExternalizeDataURIs {
+		localAssets = append(localAssets, externalizeDataURIs(doc, opts.DataURIThreshold)...)
+	}
+	// Read after the icon and manifest links point at their local copies.
+	head := ExtractHeadMetadata(doc)
 
 	var externalCSS []fetcher.FetchedResource
 	var externalJS []fetcher.FetchedResource
 
+	fetch := fetcher.FetchExternalResourcesContext
+	if opts.KeepExternal {
+		fetch = keepExternal
+	}
 	if len(cssURLs) > 0 {
-		externalCSS = fetcher.FetchExternalResources(cssURLs, "css")
+		externalCSS = fetch(ctx, cssURLs, "css")
 	}
 	if len(jsURLs) > 0 {
-		externalJS = fetcher.FetchExternalResources(jsURLs, "js")
+		externalJS = fetch(ctx, jsURLs, "js")
+		recordScriptAttributes(doc, externalJS)
 	}
 
+	if opts.HashFilenames {
+		fetcher.HashFilenames(externalCSS)
+		fetcher.HashFilenames(externalJS)
+	}
 	rewriteExternalLinks(doc, externalCSS, externalJS)
+	css := cssContent.String()
+	if opts.DedupeCSS {
+		dedupeCSS(doc, inlineCSS, externalCSS)
+		css = pooledCSS(inlineCSS)
+	}
+	logging.FromContext(ctx).Debug("extracted page resources",
+		"inlineCSS", len(inlineCSS), "inlineJS", len(inlineJS),
+		"externalCSS", len(externalCSS), "externalJS", len(externalJS),
+		"localAssets", len(localAssets))
 
 	var buf bytes.Buffer
 	err = html.Render(&buf, doc)
@@ -77,12 +257,17 @@ func Extract(htmlContent string) (*ExtractedContent, error) {
 
 	return &ExtractedContent{
 		HTML:        formattedHTML,
-		CSS:         cssContent.String(),
+		CSS:         css,
 		JS:          jsContent.String(),
 		InlineCSS:   inlineCSS,
 		InlineJS:    inlineJS,
 		ExternalCSS: externalCSS,
 		ExternalJS:  externalJS,
+		LocalAssets: localAssets,
+		Head:        head,
+
+		ExtractedAt:  time.Now().UTC(),
+		SourceLength: len(htmlContent),
 	}, nil
 }
 
@@ -119,13 +304,12 @@ func extractStylesAndScripts(n *html.Node, cssContent, jsContent *strings.Builde
 	}
 }
 
-func extractInlineResources(n *html.Node, cssContent, jsContent *strings.Builder, inlineCSS, inlineJS *[]InlineResource, cssIndex, jsIndex *int) {
+func extractInlineResources(n *html.Node, opts *ExtractOptions, taken map[string]bool, cssContent, jsContent *strings.Builder, inlineCSS, inlineJS *[]InlineResource, cssIndex, jsIndex *int) {
 	if n.Type == html.ElementNode {
-		if n.Data == "style" {
+		if n.Data == "style" && !opts.SkipCSS {
 			content := collectTextContent(n)
 			if strings.TrimSpace(content) != "" {
-				*cssIndex++
-				filename := fmt.Sprintf("inline/style-%d.css", *cssIndex)
+				filename := nextInlineFilePath(opts.CSSFileName, cssIndex, taken)
 				*inlineCSS = append(*inlineCSS, InlineResource{Path: filename, Content: content})
 				cssContent.WriteString(content)
 				if !strings.HasSuffix(content, "\n") {
@@ -135,18 +319,22 @@ func extractInlineResources(n *html.Node, cssContent, jsContent *strings.Builder
 				replaceNode(n, replacement)
 				return
 			}
-		} else if n.Data == "script" && !hasAttribute(n, "src") {
+		} else if n.Data == "script" && !opts.SkipJS && !hasAttribute(n, "src") {
 			if !isJavaScriptType(getAttribute(n, "type")) {
 				return
 			}
 			content := collectTextContent(n)
 			if strings.TrimSpace(content) != "" {
-				*jsIndex++
-				filename := fmt.Sprintf("inline/script-%d.js", *jsIndex)
-				*inlineJS = append(*inlineJS, InlineResource{Path: filename, Content: content})
-				jsContent.WriteString(content)
-				if !strings.HasSuffix(content, "\n") {
-					jsContent.WriteString("\n")
+				filename := nextInlineFilePath(opts.JSFileName, jsIndex, taken)
+				scriptType := strings.TrimSpace(getAttribute(n, "type"))
+				*inlineJS = append(*inlineJS, InlineResource{Path: filename, Content: content, Type: scriptType})
+				// Module scripts can't be concatenated with classic scripts, so they
+				// only live in their own ordered file and stay out of the pooled JS.
+				if !isModuleType(scriptType) {
+					jsContent.WriteString(content)
+					if !strings.HasSuffix(content, "\n") {
+						jsContent.WriteString("\n")
+					}
 				}
 				replacement := buildScriptSrcNode(n, filename)
 				replaceNode(n, replacement)
@@ -157,7 +345,7 @@ func extractInlineResources(n *html.Node, cssContent, jsContent *strings.Builder
 
 	for c := n.FirstChild; c != nil; {
 		next := c.NextSibling
-		extractInlineResources(c, cssContent, jsContent, inlineCSS, inlineJS, cssIndex, jsIndex)
+		extractInlineResources(c, opts, taken, cssContent, jsContent, inlineCSS, inlineJS, cssIndex, jsIndex)
 		c = next
 	}
 }
@@ -188,6 +376,10 @@ func isJavaScriptType(scriptType string) bool {
 	}
 }
 
+func isModuleType(scriptType string) bool {
+	return strings.EqualFold(strings.TrimSpace(scriptType), "module")
+}
+
 func buildStyleLinkNode(original *html.Node, href string) *html.Node {
 	attrs := []html.Attribute{
 		{Key: "rel", Val: "stylesheet"},
@@ -282,64 +474,6 @@ func removeStyleAndScriptTags(n *html.Node) {
 	}
 }
 
-func addLinksToDocument(doc *html.Node) {
-	head := findOrCreateHead(doc)
-
-	body := findOrCreateBody(doc)
-
-	addCSSToHead(head)
-
-	addJSToBody(body)
-}
-
-func findOrCreateHead(doc *html.Node) *html.Node {
-	head := findElement(doc, "head")
-	if head != nil {
-		return head
-	}
-
-	htmlNode := findElement(doc, "html")
-	if htmlNode == nil {
-		htmlNode = &html.Node{
-			Type: html.ElementNode,
-			Data: "html",
-		}
-		doc.AppendChild(htmlNode)
-	}
-
-	head = &html.Node{
-		Type: html.ElementNode,
-		Data: "head",
-	}
-	htmlNode.AppendChild(head)
-
-	return head
-}
-
-func findOrCreateBody(doc *html.Node) *html.Node {
-	body := findElement(doc, "body")
-	if body != nil {
-		return body
-	}
-
-	htmlNode := findElement(doc, "html")
-	if htmlNode == nil {
-		htmlNode = &html.Node{
-			Type: html.ElementNode,
-			Data: "html",
-		}
-		doc.AppendChild(htmlNode)
-	}
-
-	body = &html.Node{
-		Type: html.ElementNode,
-		Data: "body",
-	}
-	htmlNode.AppendChild(body)
-
-	return body
-}
-
 func findElement(n *html.Node, tagName string) *html.Node {
 	if n.Type == html.ElementNode && n.Data == tagName {
 		return n
@@ -354,29 +488,6 @@ func findElement(n *html.Node, tagName string) *html.Node {
 	return nil
 }
 
-func addCSSToHead(head *html.Node) {
-	link := &html.Node{
-		Type: html.ElementNode,
-		Data: "link",
-		Attr: []html.Attribute{
-			{Key: "rel", Val: "stylesheet"},
-			{Key: "href", Val: "style.css"},
-		},
-	}
-	head.AppendChild(link)
-}
-
-func addJSToBody(body *html.Node) {
-	script := &html.Node{
-		Type: html.ElementNode,
-		Data: "script",
-		Attr: []html.Attribute{
-			{Key: "src", Val: "script.js"},
-		},
-	}
-	body.AppendChild(script)
-}
-
 func findExternalResourceURLs(doc *html.Node) ([]string, []string) {
 	var cssURLs []string
 	var jsURLs []string
@@ -406,6 +517,27 @@ func findExternalURLs(n *html.Node, cssURLs, jsURLs *[]string) {
 	}
 }
 
+// recordScriptAttributes copies the type, async, and defer attributes of each
+// external <script> onto the resource fetched for its src.
+func recordScriptAttributes(n *html.Node, externalJS []fetcher.FetchedResource) {
+	if n.Type == html.ElementNode && n.Data == "script" {
+		if src := getAttribute(n, "src"); src != "" {
+			for i := range externalJS {
+				if externalJS[i].URL == src {
+					externalJS[i].ScriptType = getAttribute(n, "type")
+					externalJS[i].Async = hasAttribute(n, "async")
+					externalJS[i].Defer = hasAttribute(n, "defer")
+					break
+				}
+			}
+		}
+	}
+
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		recordScriptAttributes(c, externalJS)
+	}
+}
+
 func getAttribute(n *html.Node, key string) string {
 	for _, attr := range n.Attr {
 		if attr.Key == key {
@@ -424,6 +556,93 @@ func hasAttribute(n *html.Node, key string) bool {
 	return false
 }
 
+// isFetchableLinkRel reports whether a <link> rel points at a concrete file
+// (favicon, touch icon, web manifest) that should be bundled with the export.
+// Hint-only rels such as preconnect, dns-prefetch, and preload are left as-is.
+func isFetchableLinkRel(rel string) bool {
+	for _, token := range strings.Fields(strings.ToLower(rel)) {
+		switch token {
+		case "icon", "apple-touch-icon", "apple-touch-icon-precomposed", "mask-icon", "manifest":
+			return true
+		}
+	}
+	return false
+}
+
+// fetchLinkedAssets downloads external icon and manifest links, rewrites their
+// href to the local copy, and returns the downloaded files. Links that fail to
+// download, or come after ctx is done, keep their original href.
+func fetchLinkedAssets(ctx context.Context, doc *html.Node) []LocalAsset {
+	var assets []LocalAsset
+	usedNames := make(map[string]int)
+	localByURL := make(map[string]string)
+
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.Data == "link" && isFetchableLinkRel(getAttribute(n, "rel")) {
+			href := getAttribute(n, "href")
+			if isExternalURL(href) {
+				local, ok := localByURL[href]
+				if !ok {
+					content, mimeType, err := fetcher.FetchRawContext(ctx, href)
+					if err == nil && len(content) > 0 {
+						local = "assets/" + linkedAssetFilename(href, usedNames)
+						assets = append(assets, LocalAsset{Path: local, Content: content, MIME: mimeType, URL: href})
+					}
+					localByURL[href] = local
+				}
+				if local != "" {
+					updateAttribute(n, "href", local)
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+
+	return assets
+}
+
+func linkedAssetFilename(rawURL string, used map[string]int) string {
+	name := "asset"
+	if parsed, err := url.Parse(rawURL); err == nil {
+		if base := path.Base(parsed.Path); base != "" && base != "." && base != "/" {
+			name = base
+		}
+	}
+
+	name = strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '.' || r == '-' || r == '_' {
+			return r
+		}
+		return '-'
+	}, name)
+
+	original := name
+	for counter := 1; used[name] > 0; counter++ {
+		ext := path.Ext(original)
+		name = fmt.Sprintf("%s-%d%s", strings.TrimSuffix(original, ext), counter, ext)
+	}
+	used[name]++
+	return name
+}
+
+// keepExternal stands in for fetcher.FetchExternalResourcesContext under the
+// KeepExternal option, recording each URL as skipped without fetching it.
+func keepExternal(_ context.Context, urls []string, resourceType string) []fetcher.FetchedResource {
+	resources := make([]fetcher.FetchedResource, 0, len(urls))
+	for _, resourceURL := range urls {
+		resources = append(resources, fetcher.FetchedResource{
+			URL:        resourceURL,
+			Type:       resourceType,
+			SkipReason: "kept external",
+		})
+	}
+	return resources
+}
+
 func isExternalURL(urlStr string) bool {
 	return strings.HasPrefix(urlStr, "http://") || strings.HasPrefix(urlStr, "https://")
 }
@@ -442,7 +661,7 @@ func rewriteLinks(n *html.Node, externalCSS, externalJS []fetcher.FetchedResourc
 			href := getAttribute(n, "href")
 			if href != "" && isExternalURL(href) {
 				for _, resource := range externalCSS {
-					if resource.URL == href && resource.Error == nil {
+					if resource.URL == href && resource.Filename != "" {
 						updateAttribute(n, "href", "external/css/"+resource.Filename)
 						break
 					}
@@ -452,7 +671,7 @@ func rewriteLinks(n *html.Node, externalCSS, externalJS []fetcher.FetchedResourc
 			src := getAttribute(n, "src")
 			if src != "" && isExternalURL(src) {
 				for _, resource := range externalJS {
-					if resource.URL == src && resource.Error == nil {
+					if resource.URL == src && resource.Filename != "" {
 						updateAttribute(n, "src", "external/js/"+resource.Filename)
 						break
 					}
@@ -466,6 +685,23 @@ func rewriteLinks(n *html.Node, externalCSS, externalJS []fetcher.FetchedResourc
 	}
 }
 
+// FailedResources returns the external CSS and JS resources that could not be
+// downloaded.
+func (e *ExtractedContent) FailedResources() []fetcher.FetchedResource {
+	var failed []fetcher.FetchedResource
+	for _, resource := range e.ExternalCSS {
+		if resource.Error != nil {
+			failed = append(failed, resource)
+		}
+	}
+	for _, resource := range e.ExternalJS {
+		if resource.Error != nil {
+			failed = append(failed, resource)
+		}
+	}
+	return failed
+}
+
 func updateAttribute(n *html.Node, key, value string) {
 	for i, attr := range n.Attr {
 		if attr.Key == key {
@@ -506,6 +742,39 @@ func (e *ExtractedContent) RewriteForEJS() string {
 	return buf.String()
 }
 
+// RewriteForStatic rewrites inline resource references to the css/ and js/
+// folders used by the plain static site export. External and asset paths are
+// already relative to the site root and are left untouched.
+func (e *ExtractedContent) RewriteForStatic() string {
+	doc, err := html.Parse(strings.NewReader(e.HTML))
+	if err != nil {
+		return e.HTML
+	}
+	rewriteLinksForStatic(doc)
+	var buf bytes.Buffer
+	if err := html.Render(&buf, doc); err != nil {
+		return e.HTML
+	}
+	return buf.String()
+}
+
+func rewriteLinksForStatic(n *html.Node) {
+	if n.Type == html.ElementNode {
+		if n.Data == "link" {
+			if href := getAttribute(n, "href"); strings.HasPrefix(href, "inline/") {
+				updateAttribute(n, "href", "css/"+strings.TrimPrefix(href, "inline/"))
+			}
+		} else if n.Data == "script" {
+			if src := getAttribute(n, "src"); strings.HasPrefix(src, "inline/") {
+				updateAttribute(n, "src", "js/"+strings.TrimPrefix(src, "inline/"))
+			}
+		}
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		rewriteLinksForStatic(c)
+	}
+}
+
 func rewriteLinksForEJS(n *html.Node) {
 	if n.Type == html.ElementNode {
 		if n.Data == "link" {
@@ -530,6 +799,8 @@ func rewriteLinksForNodeJS(n *html.Node) {
 			if href != "" {
 				if href == "style.css" {
 					updateAttribute(n, "href", "/styles/main.css")
+				} else if strings.HasPrefix(href, "inline/") {
+					updateAttribute(n, "href", "/styles/"+href)
 				} else if strings.HasPrefix(href, "external/css/") {
 					filename := strings.TrimPrefix(href, "external/css/")
 					updateAttribute(n, "href", "/styles/external/"+filename)
@@ -540,6 +811,8 @@ func rewriteLinksForNodeJS(n *html.Node) {
 			if src != "" {
 				if src == "script.js" {
 					updateAttribute(n, "src", "/scripts/main.js")
+				} else if strings.HasPrefix(src, "inline/") {
+					updateAttribute(n, "src", "/scripts/"+src)
 				} else if strings.HasPrefix(src, "external/js/") {
 					filename := strings.TrimPrefix(src, "external/js/")
 					updateAttribute(n, "src", "/scripts/external/"+filename)
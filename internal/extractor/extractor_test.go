@@ -0,0 +1,452 @@
+package extractor
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/omariomari2/uncluster/internal/fetcher"
+)
+
+func TestExtractKeepsInlineScriptsOrderedAndPreservesModuleType(t *testing.T) {
+	input := `<!doctype html><html><head></head><body>
+<script>var a = 1;</script>
+<script type="module">import { x } from './x.js';</script>
+<script>var b = 2;</script>
+</body></html>`
+
+	extracted, err := Extract(input)
+	if err != nil {
+		t.Fatalf("Extract returned error: %v", err)
+	}
+
+	if len(extracted.InlineJS) != 3 {
+		t.Fatalf("expected 3 inline scripts, got %d", len(extracted.InlineJS))
+	}
+	expected := []struct {
+		path, typ, content string
+	}{
+		{"inline/script-1.js", "", "var a = 1;"},
+		{"inline/script-2.js", "module", "import { x }"},
+		{"inline/script-3.js", "", "var b = 2;"},
+	}
+	for i, want := range expected {
+		got := extracted.InlineJS[i]
+		if got.Path != want.path {
+			t.Fatalf("script %d: expected path %q, got %q", i, want.path, got.Path)
+		}
+		if got.Type != want.typ {
+			t.Fatalf("script %d: expected type %q, got %q", i, want.typ, got.Type)
+		}
+		if !strings.Contains(got.Content, want.content) {
+			t.Fatalf("script %d: expected content %q, got %q", i, want.content, got.Content)
+		}
+	}
+
+	if strings.Contains(extracted.JS, "import") {
+		t.Fatalf("module script should not be pooled with classic scripts: %q", extracted.JS)
+	}
+	if !strings.Contains(extracted.HTML, `<script src="inline/script-2.js" type="module">`) {
+		t.Fatalf("expected module script to be re-linked with its type, got:\n%s", extracted.HTML)
+	}
+	first := strings.Index(extracted.HTML, "inline/script-1.js")
+	second := strings.Index(extracted.HTML, "inline/script-2.js")
+	third := strings.Index(extracted.HTML, "inline/script-3.js")
+	if first < 0 || second < first || third < second {
+		t.Fatalf("expected scripts to keep execution order, got:\n%s", extracted.HTML)
+	}
+}
+
+func TestExtractKeepsFaviconAndHintLinks(t *testing.T) {
+	input := `<!doctype html><html><head>
+<link rel="icon" href="/favicon.ico" type="image/x-icon">
+<link rel="preconnect" href="https://fonts.gstatic.com">
+<link rel="manifest" href="site.webmanifest">
+</head><body><p>hi</p></body></html>`
+
+	extracted, err := Extract(input)
+	if err != nil {
+		t.Fatalf("Extract returned error: %v", err)
+	}
+
+	for _, want := range []string{
+		`<link rel="icon" href="/favicon.ico" type="image/x-icon" />`,
+		`<link rel="preconnect" href="https://fonts.gstatic.com" />`,
+		`<link rel="manifest" href="site.webmanifest" />`,
+	} {
+		if !strings.Contains(extracted.HTML, want) {
+			t.Fatalf("expected %q to survive extraction, got:\n%s", want, extracted.HTML)
+		}
+	}
+}
+
+func TestExtractDownloadsExternalFavicon(t *testing.T) {
+	defer fetcher.SetPolicy(fetcher.SetPolicy(fetcher.Policy{AllowPrivate: true}))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte("png-bytes"))
+	}))
+	defer server.Close()
+
+	input := `<html><head><link rel="icon" href="` + server.URL + `/static/favicon.png"></head><body></body></html>`
+
+	extracted, err := Extract(input)
+	if err != nil {
+		t.Fatalf("Extract returned error: %v", err)
+	}
+
+	if len(extracted.LocalAssets) != 1 {
+		t.Fatalf("expected 1 local asset, got %d", len(extracted.LocalAssets))
+	}
+	asset := extracted.LocalAssets[0]
+	if asset.Path != "assets/favicon.png" || string(asset.Content) != "png-bytes" || asset.MIME != "image/png" {
+		t.Fatalf("unexpected asset: %+v", asset)
+	}
+	if !strings.Contains(extracted.HTML, `href="assets/favicon.png"`) {
+		t.Fatalf("expected favicon href to be rewritten, got:\n%s", extracted.HTML)
+	}
+	if links := extracted.Head.Links; len(links) != 1 || links[0].Rel != "icon" || links[0].Href != "assets/favicon.png" {
+		t.Fatalf("expected the head metadata to link the local favicon, got %+v", links)
+	}
+}
+
+func TestExtractExposesInlineResourcesAndRewritesForNodeJS(t *testing.T) {
+	input := `<html><head><style>body { color: red; }</style><style>p { margin: 0; }</style></head>
+<body><p>hi</p><script>console.log("hi");</script></body></html>`
+
+	extracted, err := Extract(input)
+	if err != nil {
+		t.Fatalf("Extract returned error: %v", err)
+	}
+
+	if len(extracted.InlineCSS) != 2 || extracted.InlineCSS[0].Path != "inline/style-1.css" || extracted.InlineCSS[1].Path != "inline/style-2.css" {
+		t.Fatalf("unexpected inline CSS resources: %+v", extracted.InlineCSS)
+	}
+	if len(extracted.InlineJS) != 1 || extracted.InlineJS[0].Path != "inline/script-1.js" {
+		t.Fatalf("unexpected inline JS resources: %+v", extracted.InlineJS)
+	}
+
+	rewritten := extracted.RewriteForNodeJS()
+	for _, want := range []string{
+		`href="/styles/inline/style-1.css"`,
+		`href="/styles/inline/style-2.css"`,
+		`src="/scripts/inline/script-1.js"`,
+	} {
+		if !strings.Contains(rewritten, want) {
+			t.Fatalf("expected %s in rewritten HTML, got:\n%s", want, rewritten)
+		}
+	}
+}
+
+func TestExtractWithOptionsNamesInlineFiles(t *testing.T) {
+	input := `<html><head><style>p { margin: 0; }</style></head><body><p>hi</p><script>var a = 1;</script></body></html>`
+
+	extracted, err := ExtractWithOptions(input, ExtractOptions{CSSFileName: "about.css", JSFileName: "about"})
+	if err != nil {
+		t.Fatalf("ExtractWithOptions returned error: %v", err)
+	}
+	if len(extracted.InlineCSS) != 1 || extracted.InlineCSS[0].Path != "inline/about-1.css" {
+		t.Fatalf("unexpected inline CSS resources: %+v", extracted.InlineCSS)
+	}
+	if len(extracted.InlineJS) != 1 || extracted.InlineJS[0].Path != "inline/about-1.js" {
+		t.Fatalf("unexpected inline JS resources: %+v", extracted.InlineJS)
+	}
+	for _, want := range []string{`href="inline/about-1.css"`, `src="inline/about-1.js"`} {
+		if !strings.Contains(extracted.HTML, want) {
+			t.Fatalf("expected %s in HTML, got:\n%s", want, extracted.HTML)
+		}
+	}
+
+	if _, err := ExtractWithOptions(input, ExtractOptions{CSSFileName: "../style.css"}); err == nil || !strings.Contains(err.Error(), "invalid cssFileName") {
+		t.Fatalf("expected invalid cssFileName error, got %v", err)
+	}
+}
+
+func TestExtractAddsNoLinksWithoutInlineContent(t *testing.T) {
+	defer fetcher.SetPolicy(fetcher.SetPolicy(fetcher.Policy{AllowPrivate: true}))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("/* remote */"))
+	}))
+	defer server.Close()
+
+	input := `<html><head><link rel="stylesheet" href="` + server.URL + `/site.css"><style> </style></head>
+<body><p>hi</p><script src="` + server.URL + `/app.js"></script></body></html>`
+
+	extracted, err := Extract(input)
+	if err != nil {
+		t.Fatalf("Extract returned error: %v", err)
+	}
+	if len(extracted.InlineCSS) != 0 || len(extracted.InlineJS) != 0 {
+		t.Fatalf("expected no inline resources, got %+v %+v", extracted.InlineCSS, extracted.InlineJS)
+	}
+	for _, unwanted := range []string{"style.css", "script.js", "inline/"} {
+		if strings.Contains(extracted.HTML, unwanted) {
+			t.Fatalf("unexpected %q link in HTML without inline content:\n%s", unwanted, extracted.HTML)
+		}
+	}
+	for _, want := range []string{`href="external/css/`, `src="external/js/`} {
+		if !strings.Contains(extracted.HTML, want) {
+			t.Fatalf("expected %s in HTML, got:\n%s", want, extracted.HTML)
+		}
+	}
+}
+
+func TestExtractExternalizesLargeDataURIs(t *testing.T) {
+	png := append([]byte("\x89PNG\r\n\x1a\n"), bytes.Repeat([]byte{0x42}, 6000)...)
+	large := "data:image/png;base64," + base64.StdEncoding.EncodeToString(png)
+	small := "data:image/gif;base64,R0lGODlhAQABAAAAACw="
+	input := `<html><body><img src="` + large + `" alt="hero"><img src="` + large + `"><img src="` + small + `"></body></html>`
+
+	extracted, err := ExtractWithOptions(input, ExtractOptions{ExternalizeDataURIs: true})
+	if err != nil {
+		t.Fatalf("ExtractWithOptions returned error: %v", err)
+	}
+	if len(extracted.LocalAssets) != 1 {
+		t.Fatalf("expected one externalized image, got %+v", extracted.LocalAssets)
+	}
+	asset := extracted.LocalAssets[0]
+	if asset.Path != "assets/data-image-1.png" || asset.MIME != "image/png" || !bytes.Equal(asset.Content, png) {
+		t.Fatalf("unexpected asset %s (%s, %d bytes)", asset.Path, asset.MIME, len(asset.Content))
+	}
+	if strings.Contains(extracted.HTML, large) || strings.Count(extracted.HTML, `src="assets/data-image-1.png"`) != 2 {
+		t.Fatalf("expected both large images to point at the file, got:\n%s", extracted.HTML)
+	}
+	if !strings.Contains(extracted.HTML, small) {
+		t.Fatalf("expected the small data URI to stay inline, got:\n%s", extracted.HTML)
+	}
+
+	kept, err := Extract(input)
+	if err != nil {
+		t.Fatalf("Extract returned error: %v", err)
+	}
+	if len(kept.LocalAssets) != 0 || !strings.Contains(kept.HTML, large) {
+		t.Fatalf("expected data URIs to stay inline by default")
+	}
+}
+
+func TestExtractKeepsExternalScriptLoadingAttributes(t *testing.T) {
+	defer fetcher.SetPolicy(fetcher.SetPolicy(fetcher.Policy{AllowPrivate: true}))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/javascript")
+		w.Write([]byte("export const ready = true;"))
+	}))
+	defer server.Close()
+
+	input := `<html><head><script type="module" src="` + server.URL + `/app.js"></script>` +
+		`<script async src="` + server.URL + `/analytics.js"></script></head><body></body></html>`
+
+	extracted, err := Extract(input)
+	if err != nil {
+		t.Fatalf("Extract returned error: %v", err)
+	}
+	if len(extracted.ExternalJS) != 2 {
+		t.Fatalf("expected 2 external scripts, got %+v", extracted.ExternalJS)
+	}
+	module, analytics := extracted.ExternalJS[0], extracted.ExternalJS[1]
+	if !module.IsModule() || module.Async || analytics.IsModule() || !analytics.Async {
+		t.Fatalf("unexpected script attributes: %+v, %+v", module, analytics)
+	}
+
+	for _, html := range []string{extracted.HTML, extracted.RewriteForNodeJS(), extracted.RewriteForEJS()} {
+		if !strings.Contains(html, `<script type="module" src="`) || !strings.Contains(html, `<script async="" src="`) {
+			t.Fatalf("expected type and async to survive the rewrite, got:\n%s", html)
+		}
+	}
+}
+
+func TestExtractLeavesSkippedResourcesExternal(t *testing.T) {
+	defer fetcher.SetPolicy(fetcher.SetPolicy(fetcher.Policy{AllowPrivate: true, OnlyHosts: []string{"cdn.example.com"}}))
+
+	input := `<html><head><link rel="stylesheet" href="https://other.example.org/site.css"></head><body></body></html>`
+	extracted, err := Extract(input)
+	if err != nil {
+		t.Fatalf("Extract returned error: %v", err)
+	}
+	if len(extracted.ExternalCSS) != 1 || extracted.ExternalCSS[0].SkipReason == "" {
+		t.Fatalf("expected the stylesheet to be skipped, got %+v", extracted.ExternalCSS)
+	}
+	if len(extracted.FailedResources()) != 0 {
+		t.Fatalf("skipped resources should not count as failed")
+	}
+	if !strings.Contains(extracted.HTML, `href="https://other.example.org/site.css"`) {
+		t.Fatalf("expected the skipped stylesheet to keep its URL, got:\n%s", extracted.HTML)
+	}
+}
+
+func TestExtractWithOptionsKeepExternalFetchesNothing(t *testing.T) {
+	defer fetcher.SetPolicy(fetcher.SetPolicy(fetcher.Policy{AllowPrivate: true}))
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Write([]byte("body {}"))
+	}))
+	defer server.Close()
+
+	input := `<html><head><link rel="stylesheet" href="` + server.URL + `/site.css"><link rel="icon" href="` + server.URL + `/favicon.png"></head>` +
+		`<body><script src="` + server.URL + `/app.js" defer></script></body></html>`
+	extracted, err := ExtractWithOptions(input, ExtractOptions{KeepExternal: true})
+	if err != nil {
+		t.Fatalf("ExtractWithOptions returned error: %v", err)
+	}
+	if n := requests.Load(); n != 0 {
+		t.Fatalf("expected no fetches, got %d", n)
+	}
+	for _, ref := range []string{`href="` + server.URL + `/site.css"`, `href="` + server.URL + `/favicon.png"`, `src="` + server.URL + `/app.js"`} {
+		if !strings.Contains(extracted.HTML, ref) {
+			t.Fatalf("expected %s to stay external, got:\n%s", ref, extracted.HTML)
+		}
+	}
+	if len(extracted.ExternalCSS) != 1 || extracted.ExternalCSS[0].SkipReason == "" || extracted.ExternalCSS[0].Filename != "" {
+		t.Fatalf("expected the stylesheet to be recorded as kept external, got %+v", extracted.ExternalCSS)
+	}
+	if len(extracted.ExternalJS) != 1 || !extracted.ExternalJS[0].Defer || len(extracted.LocalAssets) != 0 {
+		t.Fatalf("expected the script kept with its attributes and no assets, got %+v / %+v", extracted.ExternalJS, extracted.LocalAssets)
+	}
+}
+
+func TestExtractWithOptionsHashesExternalFilenames(t *testing.T) {
+	defer fetcher.SetPolicy(fetcher.SetPolicy(fetcher.Policy{AllowPrivate: true}))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("/* " + r.URL.Path + " */"))
+	}))
+	defer server.Close()
+
+	input := `<html><head><link rel="stylesheet" href="` + server.URL + `/one/site.css">` +
+		`<link rel="stylesheet" href="` + server.URL + `/two/site.css"></head><body></body></html>`
+	extracted, err := ExtractWithOptions(input, ExtractOptions{HashFilenames: true})
+	if err != nil {
+		t.Fatalf("ExtractWithOptions returned error: %v", err)
+	}
+	if len(extracted.ExternalCSS) != 2 {
+		t.Fatalf("expected 2 stylesheets, got %+v", extracted.ExternalCSS)
+	}
+	for _, res := range extracted.ExternalCSS {
+		sum := sha256.Sum256([]byte(res.Content))
+		if !strings.HasSuffix(res.Filename, "-"+hex.EncodeToString(sum[:4])+".css") {
+			t.Fatalf("expected %s to be named after its content hash, got %q", res.URL, res.Filename)
+		}
+		if !strings.Contains(extracted.HTML, `href="external/css/`+res.Filename+`"`) {
+			t.Fatalf("expected the link to %s to use %s, got:\n%s", res.URL, res.Filename, extracted.HTML)
+		}
+	}
+}
+
+func TestExtractWithOptionsSkipsCSSOrJS(t *testing.T) {
+	input := `<html><head><style>p { color: red; }</style><link rel="stylesheet" href="https://cdn.example.com/site.css"></head>` +
+		`<body><p>x</p><script>start()</script><script src="https://cdn.example.com/app.js"></script></body></html>`
+
+	cssOnly, err := ExtractWithOptions(input, ExtractOptions{SkipJS: true, KeepExternal: true})
+	if err != nil {
+		t.Fatalf("ExtractWithOptions returned error: %v", err)
+	}
+	if cssOnly.CSS == "" || len(cssOnly.InlineCSS) != 1 || len(cssOnly.ExternalCSS) != 1 {
+		t.Fatalf("expected the CSS to be extracted, got %+v", cssOnly)
+	}
+	if cssOnly.JS != "" || len(cssOnly.InlineJS) != 0 || len(cssOnly.ExternalJS) != 0 {
+		t.Fatalf("expected no JS to be extracted, got %q / %+v / %+v", cssOnly.JS, cssOnly.InlineJS, cssOnly.ExternalJS)
+	}
+	if !strings.Contains(cssOnly.HTML, "start()") || strings.Contains(cssOnly.HTML, "inline/script-1.js") {
+		t.Fatalf("expected the inline script to stay in the HTML, got:\n%s", cssOnly.HTML)
+	}
+
+	jsOnly, err := ExtractWithOptions(input, ExtractOptions{SkipCSS: true, KeepExternal: true})
+	if err != nil {
+		t.Fatalf("ExtractWithOptions returned error: %v", err)
+	}
+	if jsOnly.JS == "" || len(jsOnly.InlineJS) != 1 || len(jsOnly.ExternalJS) != 1 {
+		t.Fatalf("expected the JS to be extracted, got %+v", jsOnly)
+	}
+	if jsOnly.CSS != "" || len(jsOnly.InlineCSS) != 0 || len(jsOnly.ExternalCSS) != 0 {
+		t.Fatalf("expected no CSS to be extracted, got %q / %+v / %+v", jsOnly.CSS, jsOnly.InlineCSS, jsOnly.ExternalCSS)
+	}
+	if !strings.Contains(jsOnly.HTML, "color: red") || strings.Contains(jsOnly.HTML, "inline/style-1.css") {
+		t.Fatalf("expected the <style> block to stay in the HTML, got:\n%s", jsOnly.HTML)
+	}
+}
+
+func TestExtractIsIdempotent(t *testing.T) {
+	input := `<html><head><style>p { color: red; }</style></head><body><p>x</p><script>start()</script></body></html>`
+
+	first, err := Extract(input)
+	if err != nil {
+		t.Fatalf("Extract returned error: %v", err)
+	}
+	second, err := Extract(first.HTML)
+	if err != nil {
+		t.Fatalf("Extract returned error on its own output: %v", err)
+	}
+	if second.HTML != first.HTML || len(second.InlineCSS) != 0 || len(second.InlineJS) != 0 {
+		t.Fatalf("expected re-extraction to change nothing, got:\n%s", second.HTML)
+	}
+
+	// Exported HTML with a new inline block pasted in gets a fresh file name
+	// rather than a second reference to the existing one.
+	pasted := strings.Replace(first.HTML, "</head>", "<style>h1 { margin: 0; }</style></head>", 1)
+	pasted = strings.Replace(pasted, "</body>", "<script>finish()</script></body>", 1)
+	third, err := Extract(pasted)
+	if err != nil {
+		t.Fatalf("Extract returned error: %v", err)
+	}
+	for _, ref := range []string{`href="inline/style-1.css"`, `href="inline/style-2.css"`, `src="inline/script-1.js"`, `src="inline/script-2.js"`} {
+		if count := strings.Count(third.HTML, ref); count != 1 {
+			t.Fatalf("expected a single %s, found %d in:\n%s", ref, count, third.HTML)
+		}
+	}
+	if len(third.InlineCSS) != 1 || third.InlineCSS[0].Path != "inline/style-2.css" {
+		t.Fatalf("unexpected inline CSS: %+v", third.InlineCSS)
+	}
+}
+
+func TestExtractWithOptionsAbandonsFetchesAtTimeout(t *testing.T) {
+	defer fetcher.SetPolicy(fetcher.SetPolicy(fetcher.Policy{AllowPrivate: true}))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow.css" {
+			<-r.Context().Done()
+			return
+		}
+		w.Write([]byte("body{}"))
+	}))
+	defer server.Close()
+
+	input := `<html><head>
+<link rel="stylesheet" href="` + server.URL + `/fast.css">
+<link rel="stylesheet" href="` + server.URL + `/slow.css">
+<link rel="stylesheet" href="` + server.URL + `/late.css">
+</head><body></body></html>`
+
+	start := time.Now()
+	extracted, err := ExtractWithOptions(input, ExtractOptions{Timeout: 200 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("ExtractWithOptions returned error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("expected the export to stop at the timeout, took %v", elapsed)
+	}
+
+	css := extracted.ExternalCSS
+	if len(css) != 3 {
+		t.Fatalf("expected 3 external stylesheets, got %+v", css)
+	}
+	if css[0].Error != nil || css[0].Content != "body{}" {
+		t.Fatalf("expected the fast stylesheet to download, got %+v", css[0])
+	}
+	for _, resource := range css[1:] {
+		if !errors.Is(resource.Error, fetcher.ErrDeadline) || resource.Filename == "" {
+			t.Fatalf("expected %s to be abandoned at the deadline, got %+v", resource.URL, resource)
+		}
+	}
+}
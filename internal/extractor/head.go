@@ -0,0 +1,105 @@
+package extractor
+
+import (
+	stdhtml "html"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// HeadMetadata holds the <head> metadata a generated project carries over:
+// the title, the meta tags identified by name, property, or http-equiv
+// (description, viewport, Open Graph, ...), and the icon and manifest links.
+// The page's charset is not carried over, since generated files are always
+// UTF-8.
+type HeadMetadata struct {
+	Title string
+	Meta  []MetaTag // in document order
+	Links []LinkTag // icon and manifest links, in document order
+}
+
+// MetaTag is a <meta> element keyed by Attr, which is "name", "property", or
+// "http-equiv".
+type MetaTag struct {
+	Attr    string
+	Key     string
+	Content string
+}
+
+// String renders the tag as a self-closing <meta> element.
+func (m MetaTag) String() string {
+	return `<meta ` + m.Attr + `="` + stdhtml.EscapeString(m.Key) + `" content="` + stdhtml.EscapeString(m.Content) + `" />`
+}
+
+// LinkTag is an icon or manifest <link>. Href is the local "assets/..." copy
+// when the file was downloaded, and the original URL otherwise.
+type LinkTag struct {
+	Rel   string
+	Href  string
+	Type  string
+	Sizes string
+}
+
+// String renders the tag as a self-closing <link> element.
+func (l LinkTag) String() string {
+	var b strings.Builder
+	b.WriteString(`<link rel="` + stdhtml.EscapeString(l.Rel) + `" href="` + stdhtml.EscapeString(l.Href) + `"`)
+	if l.Type != "" {
+		b.WriteString(` type="` + stdhtml.EscapeString(l.Type) + `"`)
+	}
+	if l.Sizes != "" {
+		b.WriteString(` sizes="` + stdhtml.EscapeString(l.Sizes) + `"`)
+	}
+	b.WriteString(" />")
+	return b.String()
+}
+
+// Has reports whether a meta tag with the given name or property is present.
+func (h HeadMetadata) Has(key string) bool {
+	for _, meta := range h.Meta {
+		if strings.EqualFold(meta.Key, key) {
+			return true
+		}
+	}
+	return false
+}
+
+var metaKeyAttrs = []string{"name", "property", "http-equiv"}
+
+// ExtractHeadMetadata reads the title, keyed meta tags, and icon and manifest
+// links from the document's <head>, leaving out <meta charset> and the
+// http-equiv Content-Type that also declares one.
+func ExtractHeadMetadata(doc *html.Node) HeadMetadata {
+	var head HeadMetadata
+	headNode := findElement(doc, "head")
+	if headNode == nil {
+		return head
+	}
+
+	for c := headNode.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode {
+			continue
+		}
+		switch c.Data {
+		case "title":
+			if head.Title == "" {
+				head.Title = strings.TrimSpace(collectTextContent(c))
+			}
+		case "meta":
+			if getAttribute(c, "charset") != "" || strings.EqualFold(getAttribute(c, "http-equiv"), "content-type") {
+				continue
+			}
+			for _, attr := range metaKeyAttrs {
+				if key := getAttribute(c, attr); key != "" {
+					head.Meta = append(head.Meta, MetaTag{Attr: attr, Key: key, Content: getAttribute(c, "content")})
+					break
+				}
+			}
+		case "link":
+			if rel, href := getAttribute(c, "rel"), getAttribute(c, "href"); href != "" && isFetchableLinkRel(rel) {
+				head.Links = append(head.Links, LinkTag{Rel: rel, Href: href, Type: getAttribute(c, "type"), Sizes: getAttribute(c, "sizes")})
+			}
+		}
+	}
+	return head
+}
@@ -0,0 +1,118 @@
+package extractor
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// urlAttributes lists the attributes that hold resource URLs, per element.
+var urlAttributes = map[string][]string{
+	"a":      {"href"},
+	"link":   {"href"},
+	"script": {"src"},
+	"img":    {"src", "srcset"},
+	"source": {"src", "srcset"},
+	"video":  {"src", "poster"},
+	"audio":  {"src"},
+	"iframe": {"src"},
+	"embed":  {"src"},
+	"object": {"data"},
+	"form":   {"action"},
+}
+
+// ResolveRelativeURLs rewrites relative resource URLs in htmlContent to
+// absolute URLs against pageURL, honouring a <base href> if the page has one.
+// Fragment-only links and data:, mailto:, and javascript: URLs are left alone.
+func ResolveRelativeURLs(htmlContent, pageURL string) (string, error) {
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid page URL: %w", err)
+	}
+
+	doc, err := html.Parse(strings.NewReader(htmlContent))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse HTML: %w", err)
+	}
+
+	if href := findBaseHref(doc); href != "" {
+		if ref, err := url.Parse(href); err == nil {
+			base = base.ResolveReference(ref)
+		}
+	}
+
+	resolveURLAttributes(doc, base)
+
+	var buf bytes.Buffer
+	if err := html.Render(&buf, doc); err != nil {
+		return "", fmt.Errorf("failed to render HTML: %w", err)
+	}
+	return buf.String(), nil
+}
+
+func findBaseHref(n *html.Node) string {
+	if n.Type == html.ElementNode && n.Data == "base" {
+		return getAttribute(n, "href")
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if href := findBaseHref(c); href != "" {
+			return href
+		}
+	}
+	return ""
+}
+
+func resolveURLAttributes(n *html.Node, base *url.URL) {
+	if n.Type == html.ElementNode {
+		for _, attr := range urlAttributes[n.Data] {
+			val := getAttribute(n, attr)
+			if val == "" {
+				continue
+			}
+			if attr == "srcset" {
+				updateAttribute(n, attr, resolveSrcset(val, base))
+			} else {
+				updateAttribute(n, attr, resolveReference(val, base))
+			}
+		}
+	}
+
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		resolveURLAttributes(c, base)
+	}
+}
+
+func resolveReference(val string, base *url.URL) string {
+	trimmed := strings.TrimSpace(val)
+	lower := strings.ToLower(trimmed)
+	if strings.HasPrefix(trimmed, "#") ||
+		strings.HasPrefix(lower, "data:") ||
+		strings.HasPrefix(lower, "mailto:") ||
+		strings.HasPrefix(lower, "tel:") ||
+		strings.HasPrefix(lower, "javascript:") {
+		return val
+	}
+	ref, err := url.Parse(trimmed)
+	if err != nil {
+		return val
+	}
+	return base.ResolveReference(ref).String()
+}
+
+// resolveSrcset resolves each candidate URL in a srcset list, keeping its
+// width or density descriptor.
+func resolveSrcset(val string, base *url.URL) string {
+	candidates := strings.Split(val, ",")
+	for i, candidate := range candidates {
+		fields := strings.Fields(candidate)
+		if len(fields) == 0 {
+			continue
+		}
+		fields[0] = resolveReference(fields[0], base)
+		candidates[i] = strings.Join(fields, " ")
+	}
+	return strings.Join(candidates, ", ")
+}
@@ -0,0 +1,41 @@
+package extractor
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestResolveRelativeURLs(t *testing.T) {
+	input := `<html><head><link rel="stylesheet" href="css/site.css"><script src="/js/app.js"></script></head>
+<body><a href="#top">top</a><a href="../about">about</a><img src="img/a.png" srcset="img/a.png 1x, img/a@2x.png 2x"><img src="data:image/png;base64,AAAA"></body></html>`
+
+	resolved, err := ResolveRelativeURLs(input, "https://example.com/blog/post/")
+	if err != nil {
+		t.Fatalf("ResolveRelativeURLs returned error: %v", err)
+	}
+
+	for _, want := range []string{
+		`href="https://example.com/blog/post/css/site.css"`,
+		`src="https://example.com/js/app.js"`,
+		`href="#top"`,
+		`href="https://example.com/blog/about"`,
+		`srcset="https://example.com/blog/post/img/a.png 1x, https://example.com/blog/post/img/a@2x.png 2x"`,
+		`src="data:image/png;base64,AAAA"`,
+	} {
+		if !strings.Contains(resolved, want) {
+			t.Fatalf("expected %s in resolved HTML, got:\n%s", want, resolved)
+		}
+	}
+}
+
+func TestResolveRelativeURLsHonoursBaseHref(t *testing.T) {
+	input := `<html><head><base href="https://cdn.example.com/assets/"></head><body><img src="logo.png"></body></html>`
+
+	resolved, err := ResolveRelativeURLs(input, "https://example.com/")
+	if err != nil {
+		t.Fatalf("ResolveRelativeURLs returned error: %v", err)
+	}
+	if !strings.Contains(resolved, `src="https://cdn.example.com/assets/logo.png"`) {
+		t.Fatalf("expected <base href> to be honoured, got:\n%s", resolved)
+	}
+}
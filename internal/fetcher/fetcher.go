@@ -1,13 +1,21 @@
 package fetcher
 
 import (
+	"context"
+	"crypto/sha256"
+	"errors"
 	"fmt"
+	"html"
 	"io"
 	"net/http"
 	"net/url"
 	"path/filepath"
 	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/omariomari2/uncluster/internal/logging"
 )
 
 type FetchedResource struct {
@@ -16,23 +24,85 @@ type FetchedResource struct {
 	Filename string
 	Type     string
 	Error    error
+
+	// Loading attributes of the <script> tag that referenced a JS resource,
+	// filled in by the extractor so generators can rebuild the tag.
+	ScriptType string // e.g. "module"; empty for classic scripts
+	Async      bool
+	Defer      bool
+
+	// SkipReason says why the resource was not downloaded under the fetch
+	// policy. Skipped resources have no Filename or Error, so links to them
+	// keep their original URL.
+	SkipReason string
+}
+
+// IsModule reports whether the resource was loaded as an ES module.
+func (r FetchedResource) IsModule() bool {
+	return strings.EqualFold(strings.TrimSpace(r.ScriptType), "module")
+}
+
+// ScriptAttributes returns the loading attributes to put on a <script> tag
+// for the resource, e.g. ` type="module"` or ` async`. Classic scripts with
+// neither async nor defer get defer, so they run after the markup is parsed.
+func (r FetchedResource) ScriptAttributes() string {
+	var attrs string
+	if r.ScriptType != "" {
+		attrs += ` type="` + html.EscapeString(r.ScriptType) + `"`
+	}
+	if r.Async {
+		attrs += " async"
+	}
+	if r.Defer || (!r.Async && !r.IsModule()) {
+		attrs += " defer"
+	}
+	return attrs
+}
+
+// Placeholder returns the file body written in place of a resource that failed
+// to download, so links in the exported HTML still resolve. CSS placeholders
+// fall back to importing the original URL.
+func (r FetchedResource) Placeholder() string {
+	reason := "unknown error"
+	if r.Error != nil {
+		reason = r.Error.Error()
+	}
+	comment := fmt.Sprintf("/* uncluster: failed to fetch %s: %s */\n", r.URL, reason)
+	if r.Type == "css" {
+		return comment + fmt.Sprintf("@import url(%q);\n", r.URL)
+	}
+	return comment
+}
+
+// ErrDeadline is returned (wrapped) for fetches abandoned because the
+// context's deadline passed, so callers can tell them apart from failures.
+var ErrDeadline = errors.New("abandoned at the export deadline")
+
+// deadlineError replaces err with ErrDeadline once ctx is done, since the
+// request then failed because it was cut off.
+func deadlineError(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return fmt.Errorf("%w: %v", ErrDeadline, ctx.Err())
+	}
+	return err
 }
 
 // FetchRaw downloads a URL and returns the raw bytes plus the detected MIME type.
 // Used for binary assets such as images, fonts, and SVGs.
 // A 30-second timeout is used to accommodate slower CDNs.
 func FetchRaw(rawURL string) (content []byte, mimeType string, err error) {
-	client := &http.Client{
-		Timeout: 30 * time.Second,
-		CheckRedirect: func(req *http.Request, via []*http.Request) error {
-			if len(via) >= 10 {
-				return http.ErrUseLastResponse
-			}
-			return nil
-		},
+	return FetchRawContext(context.Background(), rawURL)
+}
+
+// FetchRawContext is FetchRaw that gives up when ctx is done, returning an
+// error that wraps ErrDeadline.
+func FetchRawContext(ctx context.Context, rawURL string) (content []byte, mimeType string, err error) {
+	if ctx.Err() != nil {
+		return nil, "", deadlineError(ctx, nil)
 	}
+	client := newClient(30 * time.Second)
 
-	req, err := http.NewRequest("GET", rawURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", rawURL, nil)
 	if err != nil {
 		return nil, "", fmt.Errorf("failed to create request: %w", err)
 	}
@@ -40,7 +110,7 @@ func FetchRaw(rawURL string) (content []byte, mimeType string, err error) {
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return nil, "", fmt.Errorf("request failed: %w", err)
+		return nil, "", deadlineError(ctx, fmt.Errorf("request failed: %w", err))
 	}
 	defer resp.Body.Close()
 
@@ -50,7 +120,7 @@ func FetchRaw(rawURL string) (content []byte, mimeType string, err error) {
 
 	data, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, "", fmt.Errorf("failed to read body: %w", err)
+		return nil, "", deadlineError(ctx, fmt.Errorf("failed to read body: %w", err))
 	}
 
 	ct := resp.Header.Get("Content-Type")
@@ -66,98 +136,174 @@ func FetchRaw(rawURL string) (content []byte, mimeType string, err error) {
 }
 
 func FetchExternalResources(urls []string, resourceType string) []FetchedResource {
+	return FetchExternalResourcesContext(context.Background(), urls, resourceType)
+}
+
+// FetchExternalResourcesContext is FetchExternalResources bounded by ctx. Once
+// ctx is done the fetch in flight and those not yet started are abandoned;
+// they keep their filename, like other failures, and their Error wraps
+// ErrDeadline.
+func FetchExternalResourcesContext(ctx context.Context, urls []string, resourceType string) []FetchedResource {
 	if len(urls) == 0 {
 		return []FetchedResource{}
 	}
 
-	client := &http.Client{
-		Timeout: 10 * time.Second,
-		CheckRedirect: func(req *http.Request, via []*http.Request) error {
-			if len(via) >= 10 {
-				return http.ErrUseLastResponse
-			}
-			return nil
-		},
-	}
+	policy := getPolicy()
+	client := newClient(10 * time.Second)
 
 	var results []FetchedResource
 	usedFilenames := make(map[string]int)
 
 	for _, resourceURL := range urls {
-		req, reqErr := http.NewRequest("GET", resourceURL, nil)
-		if reqErr != nil {
+		if reason := policy.skipReason(resourceURL); reason != "" {
 			results = append(results, FetchedResource{
-				URL:   resourceURL,
-				Type:  resourceType,
-				Error: reqErr,
+				URL:        resourceURL,
+				Type:       resourceType,
+				SkipReason: reason,
 			})
 			continue
 		}
-		req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
-		resp, err := client.Do(req)
-		if err != nil {
-			results = append(results, FetchedResource{
-				URL:   resourceURL,
-				Type:  resourceType,
-				Error: err,
-			})
-			continue
+
+		// Failed resources keep a filename too, so exports can write a
+		// placeholder at the path the rewritten HTML points to.
+		filename := generateSafeFilename(resourceURL, resourceType, usedFilenames)
+
+		reportProgress(ctx, Progress{Stage: FetchStarted, URL: resourceURL, Type: resourceType})
+		res := fetchResource(ctx, client, resourceURL, filename, resourceType)
+		done := Progress{Stage: FetchComplete, URL: resourceURL, Type: resourceType}
+		if res.Error != nil {
+			done.Error = res.Error.Error()
 		}
-		defer resp.Body.Close()
+		reportProgress(ctx, done)
+		results = append(results, res)
+	}
 
-		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-			err := fmt.Errorf("HTTP %d", resp.StatusCode)
-			results = append(results, FetchedResource{
-				URL:   resourceURL,
-				Type:  resourceType,
-				Error: err,
-			})
-			continue
+	logResults(ctx, results)
+	return results
+}
+
+// fetchResource downloads resourceURL under ctx, recording any failure in the
+// returned resource's Error.
+func fetchResource(ctx context.Context, client *http.Client, resourceURL, filename, resourceType string) FetchedResource {
+	if ctx.Err() != nil {
+		return FetchedResource{
+			URL:      resourceURL,
+			Filename: filename,
+			Type:     resourceType,
+			Error:    deadlineError(ctx, nil),
 		}
+	}
 
-		content, err := io.ReadAll(resp.Body)
-		if err != nil {
-			results = append(results, FetchedResource{
-				URL:   resourceURL,
-				Type:  resourceType,
-				Error: err,
-			})
-			continue
+	req, reqErr := http.NewRequestWithContext(ctx, "GET", resourceURL, nil)
+	if reqErr != nil {
+		return FetchedResource{
+			URL:      resourceURL,
+			Filename: filename,
+			Type:     resourceType,
+			Error:    reqErr,
 		}
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
+	resp, err := client.Do(req)
+	if err != nil {
+		return FetchedResource{
+			URL:      resourceURL,
+			Filename: filename,
+			Type:     resourceType,
+			Error:    deadlineError(ctx, err),
+		}
+	}
+	defer resp.Body.Close()
 
-		filename := generateSafeFilename(resourceURL, resourceType, usedFilenames)
-		usedFilenames[filename]++
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		err := fmt.Errorf("HTTP %d", resp.StatusCode)
+		return FetchedResource{
+			URL:      resourceURL,
+			Filename: filename,
+			Type:     resourceType,
+			Error:    err,
+		}
+	}
 
-		results = append(results, FetchedResource{
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return FetchedResource{
 			URL:      resourceURL,
-			Content:  string(content),
 			Filename: filename,
 			Type:     resourceType,
-			Error:    nil,
-		})
+			Error:    deadlineError(ctx, err),
+		}
 	}
 
-	return results
+	return FetchedResource{
+		URL:      resourceURL,
+		Content:  string(content),
+		Filename: filename,
+		Type:     resourceType,
+		Error:    nil,
+	}
 }
 
-func generateSafeFilename(resourceURL, resourceType string, usedFilenames map[string]int) string {
-	parsedURL, err := url.Parse(resourceURL)
-	if err != nil {
-		return fmt.Sprintf("external-%d.%s", len(usedFilenames), getExtension(resourceType))
+// logResults logs the outcome of each fetch with the logger of ctx, so the
+// lines of concurrent exports carry their own request ID.
+func logResults(ctx context.Context, results []FetchedResource) {
+	logger := logging.FromContext(ctx)
+	for _, res := range results {
+		switch {
+		case res.SkipReason != "":
+			logger.Debug("fetch: skipped resource", "url", res.URL, "reason", res.SkipReason)
+		case res.Error != nil:
+			logger.Warn("fetch: failed to fetch resource", "url", res.URL, "error", res.Error)
+		default:
+			logger.Debug("fetch: fetched resource", "url", res.URL, "bytes", len(res.Content))
+		}
 	}
+}
 
-	filename := generateDescriptiveFilename(parsedURL, resourceType)
+// HashFilenames renames resources for cache busting: each keeps the
+// descriptive name generateSafeFilename starts from, with the first 8 hex
+// digits of the SHA-256 of its content appended (theme-1a2b3c4d.css) instead
+// of a counter. Names then depend only on the URL and the content, not on
+// the order resources were fetched in, and change whenever the content does.
+// Resources that failed to download hash their URL; skipped ones have no
+// file and are left alone.
+func HashFilenames(resources []FetchedResource) {
+	for i := range resources {
+		res := &resources[i]
+		if res.Filename == "" {
+			continue
+		}
+		data := res.Content
+		if res.Error != nil {
+			data = res.URL
+		}
+		sum := sha256.Sum256([]byte(data))
+		filename := descriptiveFilename(res.URL, res.Type)
+		ext := filepath.Ext(filename)
+		res.Filename = fmt.Sprintf("%s-%x%s", strings.TrimSuffix(filename, ext), sum[:4], ext)
+	}
+}
 
-	filename = sanitizeFilename(filename)
+// descriptiveFilename returns the readable, sanitized filename for
+// resourceURL, before any suffix that makes it unique.
+func descriptiveFilename(resourceURL, resourceType string) string {
+	if parsedURL, err := url.Parse(resourceURL); err == nil {
+		return sanitizeFilename(generateDescriptiveFilename(parsedURL, resourceType))
+	}
+	return "external" + getExtension(resourceType)
+}
 
-	originalFilename := filename
-	counter := 1
-	for usedFilenames[filename] > 0 {
-		ext := filepath.Ext(originalFilename)
-		base := strings.TrimSuffix(originalFilename, ext)
+// generateSafeFilename picks a readable filename for resourceURL and records
+// it in usedFilenames. Names are compared case-insensitively, so Theme.css and
+// theme.css don't overwrite each other on macOS or Windows.
+func generateSafeFilename(resourceURL, resourceType string, usedFilenames map[string]int) string {
+	filename := descriptiveFilename(resourceURL, resourceType)
+	ext := filepath.Ext(filename)
+	base := strings.TrimSuffix(filename, ext)
+	for counter := 1; usedFilenames[strings.ToLower(filename)] > 0; counter++ {
 		filename = fmt.Sprintf("%s-%d%s", base, counter, ext)
-		counter++
 	}
+	usedFilenames[strings.ToLower(filename)]++
 
 	return filename
 }
@@ -245,31 +391,34 @@ func getExtension(resourceType string) string {
 	}
 }
 
+// sanitizeFilename reduces filename to an ASCII slug that is safe on every
+// filesystem: anything other than ASCII letters, digits, '.', '-', and '_',
+// including non-Latin letters, becomes '_', and separators left dangling at
+// either end of the name are trimmed. Case is kept for readability;
+// generateSafeFilename handles names that differ only in case.
 func sanitizeFilename(filename string) string {
-	unsafeChars := []string{"/", "\\", ":", "*", "?", "\"", "<", ">", "|", " "}
-
-	for _, char := range unsafeChars {
-		filename = strings.ReplaceAll(filename, char, "_")
-	}
+	filename = strings.Map(func(r rune) rune {
+		if r < utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == '.' || r == '-' || r == '_') {
+			return r
+		}
+		return '_'
+	}, filename)
 
 	for strings.Contains(filename, "__") {
 		filename = strings.ReplaceAll(filename, "__", "_")
 	}
 
-	filename = strings.Trim(filename, "_")
-
-	if filename == "" {
-		filename = "resource"
+	ext := strings.TrimRight(filepath.Ext(filename), "_")
+	if ext == "." {
+		ext = ""
 	}
-
-	if len(filename) > 100 {
-		ext := filepath.Ext(filename)
-		base := strings.TrimSuffix(filename, ext)
-		if len(base) > 100-len(ext) {
-			base = base[:100-len(ext)]
-		}
-		filename = base + ext
+	base := strings.Trim(strings.TrimSuffix(filename, filepath.Ext(filename)), "_-.")
+	if base == "" {
+		base = "resource"
 	}
 
-	return filename
+	if limit := 100 - len(ext); limit > 0 && len(base) > limit {
+		base = base[:limit]
+	}
+	return base + ext
 }
@@ -0,0 +1,92 @@
+package fetcher
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSanitizeFilenameProducesASCII(t *testing.T) {
+	cases := map[string]string{
+		"style-стили.css":      "style.css",
+		"script-app-привет.js": "script-app.js",
+		"café menu.css":        "caf_menu.css",
+		"a/b:c*d?.js":          "a_b_c_d.js",
+		"стили":                "resource",
+		"":                     "resource",
+	}
+	for input, want := range cases {
+		if got := sanitizeFilename(input); got != want {
+			t.Errorf("sanitizeFilename(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestGenerateSafeFilenameHandlesCyrillicPath(t *testing.T) {
+	used := make(map[string]int)
+	first := generateSafeFilename("https://example.com/%D1%81%D1%82%D0%B8%D0%BB%D0%B8/main.css", "css", used)
+	second := generateSafeFilename("https://example.com/стили/тема/main.css", "css", used)
+
+	for _, name := range []string{first, second} {
+		for _, r := range name {
+			if r > 127 {
+				t.Fatalf("expected an ASCII filename, got %q", name)
+			}
+		}
+		if !strings.HasSuffix(name, ".css") {
+			t.Fatalf("expected a .css filename, got %q", name)
+		}
+	}
+	if first == second {
+		t.Fatalf("expected distinct filenames, both were %q", first)
+	}
+}
+
+func TestGenerateSafeFilenameDedupsIgnoringCase(t *testing.T) {
+	used := make(map[string]int)
+	first := generateSafeFilename("https://example.com/assets/Theme/x.css", "css", used)
+	second := generateSafeFilename("https://example.com/assets/theme/x.css", "css", used)
+
+	if first != "style-assets-Theme.css" {
+		t.Fatalf("expected the readable name to keep its case, got %q", first)
+	}
+	if strings.EqualFold(first, second) {
+		t.Fatalf("expected names that differ beyond case, got %q and %q", first, second)
+	}
+}
+
+func TestHashFilenamesIgnoresFetchOrder(t *testing.T) {
+	resources := []FetchedResource{
+		{URL: "https://a.example.com/theme/x.css", Type: "css", Content: "body { color: red; }"},
+		{URL: "https://b.example.com/theme/x.css", Type: "css", Content: "body { color: blue; }"},
+		{URL: "https://c.example.com/theme/x.css", Type: "css", Content: "body { color: red; }"},
+	}
+	names := func(order []int) map[string]string {
+		used := make(map[string]int)
+		var batch []FetchedResource
+		for _, i := range order {
+			res := resources[i]
+			res.Filename = generateSafeFilename(res.URL, res.Type, used)
+			batch = append(batch, res)
+		}
+		HashFilenames(batch)
+		byURL := make(map[string]string)
+		for _, res := range batch {
+			byURL[res.URL] = res.Filename
+		}
+		return byURL
+	}
+
+	forward, backward := names([]int{0, 1, 2}), names([]int{2, 1, 0})
+	for url, name := range forward {
+		if backward[url] != name {
+			t.Fatalf("expected %s to get the same name in either order, got %q and %q", url, name, backward[url])
+		}
+	}
+	a, b, c := forward[resources[0].URL], forward[resources[1].URL], forward[resources[2].URL]
+	if a != c || a == b {
+		t.Fatalf("expected names to follow content, got %q, %q, %q", a, b, c)
+	}
+	if !strings.HasSuffix(a, ".css") || len(a) != len("style-theme-12345678.css") {
+		t.Fatalf("expected an 8-digit hash before the extension, got %q", a)
+	}
+}
@@ -0,0 +1,193 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrBlockedURL is returned (wrapped) when a request is refused by the outbound
+// fetch policy.
+var ErrBlockedURL = errors.New("blocked by fetch policy")
+
+// Policy controls which hosts outbound fetches may reach. By default only
+// public addresses are allowed.
+type Policy struct {
+	// AllowPrivate permits loopback, private, link-local, and other non-public
+	// addresses. Intended for local development and tests.
+	AllowPrivate bool
+	// AllowHosts lists hostnames that are always permitted, even when they
+	// resolve to a non-public address.
+	AllowHosts []string
+	// DenyHosts lists hostnames that are always refused. A leading "." matches
+	// any subdomain, e.g. ".internal.example.com".
+	DenyHosts []string
+	// OnlyHosts, when set, limits FetchExternalResources to these hostnames,
+	// matched like DenyHosts. Other URLs are skipped rather than fetched and
+	// keep pointing at their original address.
+	OnlyHosts []string
+}
+
+var (
+	policyMu      sync.RWMutex
+	currentPolicy Policy
+)
+
+// SetPolicy replaces the outbound fetch policy and returns the previous one.
+func SetPolicy(p Policy) Policy {
+	policyMu.Lock()
+	defer policyMu.Unlock()
+	previous := currentPolicy
+	currentPolicy = p
+	return previous
+}
+
+func getPolicy() Policy {
+	policyMu.RLock()
+	defer policyMu.RUnlock()
+	return currentPolicy
+}
+
+// PolicyFromEnv builds a policy from FETCH_ALLOW_PRIVATE ("true" or "1"),
+// FETCH_ALLOW_HOSTS, FETCH_DENY_HOSTS, and FETCH_ONLY_HOSTS (comma-separated
+// hostnames).
+func PolicyFromEnv() Policy {
+	allowPrivate := strings.ToLower(strings.TrimSpace(os.Getenv("FETCH_ALLOW_PRIVATE")))
+	return Policy{
+		AllowPrivate: allowPrivate == "true" || allowPrivate == "1",
+		AllowHosts:   splitHostList(os.Getenv("FETCH_ALLOW_HOSTS")),
+		DenyHosts:    splitHostList(os.Getenv("FETCH_DENY_HOSTS")),
+		OnlyHosts:    splitHostList(os.Getenv("FETCH_ONLY_HOSTS")),
+	}
+}
+
+func splitHostList(value string) []string {
+	var hosts []string
+	for _, host := range strings.Split(value, ",") {
+		if host = strings.ToLower(strings.TrimSpace(host)); host != "" {
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts
+}
+
+func matchesHost(host string, patterns []string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	for _, pattern := range patterns {
+		if strings.HasPrefix(pattern, ".") {
+			if strings.HasSuffix(host, pattern) || host == pattern[1:] {
+				return true
+			}
+		} else if host == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// checkHost applies the host allow and deny lists. It reports whether the
+// host is explicitly allowed, which skips the address check.
+func (p Policy) checkHost(host string) (allowed bool, err error) {
+	if matchesHost(host, p.DenyHosts) {
+		return false, fmt.Errorf("%w: host %s is denied", ErrBlockedURL, host)
+	}
+	return p.AllowPrivate || matchesHost(host, p.AllowHosts), nil
+}
+
+// skipReason reports why FetchExternalResources should not download rawURL:
+// its host is denied or missing from OnlyHosts. It returns "" for URLs that
+// may be fetched; anything else is left to the dialer's checks.
+func (p Policy) skipReason(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Hostname() == "" {
+		return ""
+	}
+	host := parsed.Hostname()
+	if matchesHost(host, p.DenyHosts) {
+		return "host " + host + " is denied"
+	}
+	if len(p.OnlyHosts) > 0 && !matchesHost(host, p.OnlyHosts) {
+		return "host " + host + " is not in the allowed hosts"
+	}
+	return ""
+}
+
+// dialContext resolves the host itself and connects only to addresses the
+// policy permits, so a hostname cannot pass the check and then resolve to an
+// internal address at connect time.
+func (p Policy) dialContext(dialer *net.Dialer) func(ctx context.Context, network, address string) (net.Conn, error) {
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(address)
+		if err != nil {
+			return nil, err
+		}
+
+		allowed, err := p.checkHost(host)
+		if err != nil {
+			return nil, err
+		}
+		if allowed {
+			return dialer.DialContext(ctx, network, address)
+		}
+
+		ips, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		for _, ip := range ips {
+			if isNonPublicIP(ip.IP) {
+				return nil, fmt.Errorf("%w: refusing to connect to non-public address %s (%s)", ErrBlockedURL, ip.IP, host)
+			}
+		}
+		if len(ips) == 0 {
+			return nil, fmt.Errorf("no addresses found for %s", host)
+		}
+
+		return dialer.DialContext(ctx, network, net.JoinHostPort(ips[0].IP.String(), port))
+	}
+}
+
+// newClient returns an HTTP client that enforces the current fetch policy on
+// every connection, including redirect hops, and refuses redirects to
+// non-HTTP schemes. Proxies are not used, since the policy must see the real
+// destination address.
+func newClient(timeout time.Duration) *http.Client {
+	p := getPolicy()
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:         p.dialContext(dialer),
+			TLSHandshakeTimeout: 10 * time.Second,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 10 {
+				return http.ErrUseLastResponse
+			}
+			if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+				return fmt.Errorf("%w: redirect to %s URL", ErrBlockedURL, req.URL.Scheme)
+			}
+			return nil
+		},
+	}
+}
+
+// isNonPublicIP reports whether ip is loopback, private, link-local (which
+// covers cloud metadata endpoints), multicast, or unspecified.
+func isNonPublicIP(ip net.IP) bool {
+	return ip.IsLoopback() ||
+		ip.IsPrivate() ||
+		ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast() ||
+		ip.IsUnspecified()
+}
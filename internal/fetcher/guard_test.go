@@ -0,0 +1,96 @@
+package fetcher
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestFetchExternalResourcesBlocksPrivateAddresses(t *testing.T) {
+	defer SetPolicy(SetPolicy(Policy{}))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("body{}"))
+	}))
+	defer server.Close()
+
+	results := FetchExternalResources([]string{server.URL + "/site.css", "http://169.254.169.254/latest/meta-data/"}, "css")
+	for _, result := range results {
+		if !errors.Is(result.Error, ErrBlockedURL) {
+			t.Fatalf("expected %s to be blocked, got %v", result.URL, result.Error)
+		}
+	}
+}
+
+func TestPolicyAllowPrivatePermitsLoopback(t *testing.T) {
+	defer SetPolicy(SetPolicy(Policy{AllowPrivate: true}))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("body{}"))
+	}))
+	defer server.Close()
+
+	results := FetchExternalResources([]string{server.URL + "/site.css"}, "css")
+	if results[0].Error != nil || results[0].Content != "body{}" {
+		t.Fatalf("expected loopback fetch to succeed, got %+v", results[0])
+	}
+}
+
+func TestPolicyChecksEveryRedirectHop(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("secret"))
+	}))
+	defer target.Close()
+
+	targetURL, _ := url.Parse(target.URL)
+	internal := "http://localhost:" + targetURL.Port() + "/"
+
+	redirector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, internal, http.StatusFound)
+	}))
+	defer redirector.Close()
+
+	defer SetPolicy(SetPolicy(Policy{AllowHosts: []string{"127.0.0.1"}}))
+
+	_, _, err := FetchRaw(redirector.URL)
+	if !errors.Is(err, ErrBlockedURL) {
+		t.Fatalf("expected redirect to localhost to be blocked, got %v", err)
+	}
+}
+
+func TestPolicyDenyHosts(t *testing.T) {
+	defer SetPolicy(SetPolicy(Policy{AllowPrivate: true, DenyHosts: []string{".internal.example.com"}}))
+
+	_, _, err := FetchRaw("http://db.internal.example.com/")
+	if !errors.Is(err, ErrBlockedURL) || !strings.Contains(err.Error(), "denied") {
+		t.Fatalf("expected denied host to be blocked, got %v", err)
+	}
+}
+
+func TestPolicyOnlyHostsSkipsOtherHosts(t *testing.T) {
+	defer SetPolicy(SetPolicy(Policy{AllowPrivate: true, OnlyHosts: []string{"127.0.0.1"}}))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("body{}"))
+	}))
+	defer server.Close()
+
+	port := strings.TrimPrefix(server.URL, "http://127.0.0.1")
+	results := FetchExternalResources([]string{server.URL + "/allowed.css", "http://localhost" + port + "/other.css"}, "css")
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+	if results[0].Error != nil || results[0].Content != "body{}" || results[0].SkipReason != "" {
+		t.Fatalf("expected allowed host to be fetched, got %+v", results[0])
+	}
+	skipped := results[1]
+	if skipped.SkipReason == "" || skipped.Error != nil || skipped.Filename != "" || skipped.Content != "" {
+		t.Fatalf("expected other host to be skipped without a file, got %+v", skipped)
+	}
+	if !strings.Contains(skipped.SkipReason, "localhost") {
+		t.Fatalf("expected skip reason to name the host, got %q", skipped.SkipReason)
+	}
+}
@@ -0,0 +1,64 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/omariomari2/uncluster/internal/htmlparse"
+)
+
+// MaxPageBytes caps the size of a page downloaded by FetchPage.
+const MaxPageBytes = 10 * 1024 * 1024
+
+// ErrPageTooLarge is returned (wrapped) when a page is over the size limit.
+var ErrPageTooLarge = errors.New("page is too large")
+
+// FetchPage downloads the HTML of a user-supplied page URL. Only http and
+// https URLs are accepted, and connections to loopback, private, link-local,
+// and other non-public addresses are refused according to the fetch policy.
+func FetchPage(rawURL string) (string, error) {
+	return FetchPageContext(context.Background(), rawURL, MaxPageBytes)
+}
+
+// FetchPageContext is FetchPage that gives up when ctx is done and refuses
+// pages over limit bytes.
+func FetchPageContext(ctx context.Context, rawURL string, limit int) (string, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return "", fmt.Errorf("invalid URL: must start with http:// or https://")
+	}
+
+	client := newClient(30 * time.Second)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", parsed.String(), nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, int64(limit)+1))
+	if err != nil {
+		return "", fmt.Errorf("failed to read body: %w", err)
+	}
+	if len(body) > limit {
+		return "", fmt.Errorf("%w: over the %d byte limit", ErrPageTooLarge, limit)
+	}
+
+	return htmlparse.ToUTF8(string(body), resp.Header.Get("Content-Type")), nil
+}
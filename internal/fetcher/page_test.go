@@ -0,0 +1,28 @@
+package fetcher
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestFetchPageRefusesLoopbackAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html></html>"))
+	}))
+	defer server.Close()
+
+	_, err := FetchPage(server.URL)
+	if err == nil || !strings.Contains(err.Error(), "non-public address") {
+		t.Fatalf("expected loopback fetch to be refused, got %v", err)
+	}
+}
+
+func TestFetchPageRejectsNonHTTPSchemes(t *testing.T) {
+	for _, raw := range []string{"file:///etc/passwd", "ftp://example.com/", "example.com"} {
+		if _, err := FetchPage(raw); err == nil {
+			t.Fatalf("expected %q to be rejected", raw)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package fetcher
+
+import "context"
+
+// Progress stages reported while external resources are downloaded.
+const (
+	FetchStarted  = "fetch-started"
+	FetchComplete = "fetch-complete"
+)
+
+// Progress is one step of FetchExternalResourcesContext: a download starting
+// or finishing. Resources the fetch policy skips report nothing.
+type Progress struct {
+	Stage string `json:"stage"`
+	URL   string `json:"url"`
+	Type  string `json:"type"`            // "css" or "js"
+	Error string `json:"error,omitempty"` // why a finished download failed
+}
+
+type progressKey struct{}
+
+// WithProgress returns a copy of ctx that has FetchExternalResourcesContext
+// call report before and after each download. report is called from the
+// fetching goroutine, one step at a time.
+func WithProgress(ctx context.Context, report func(Progress)) context.Context {
+	return context.WithValue(ctx, progressKey{}, report)
+}
+
+func reportProgress(ctx context.Context, p Progress) {
+	if report, ok := ctx.Value(progressKey{}).(func(Progress)); ok {
+		report(p)
+	}
+}
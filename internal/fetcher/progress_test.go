@@ -0,0 +1,40 @@
+package fetcher
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFetchExternalResourcesReportsProgress(t *testing.T) {
+	defer SetPolicy(SetPolicy(Policy{AllowPrivate: true}))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing.css" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("body{}"))
+	}))
+	defer server.Close()
+
+	var steps []Progress
+	ctx := WithProgress(context.Background(), func(p Progress) { steps = append(steps, p) })
+	FetchExternalResourcesContext(ctx, []string{server.URL + "/site.css", server.URL + "/missing.css"}, "css")
+
+	want := []Progress{
+		{Stage: FetchStarted, URL: server.URL + "/site.css", Type: "css"},
+		{Stage: FetchComplete, URL: server.URL + "/site.css", Type: "css"},
+		{Stage: FetchStarted, URL: server.URL + "/missing.css", Type: "css"},
+		{Stage: FetchComplete, URL: server.URL + "/missing.css", Type: "css", Error: "HTTP 404"},
+	}
+	if len(steps) != len(want) {
+		t.Fatalf("expected %d progress steps, got %+v", len(want), steps)
+	}
+	for i := range want {
+		if steps[i] != want[i] {
+			t.Fatalf("step %d: expected %+v, got %+v", i, want[i], steps[i])
+		}
+	}
+}
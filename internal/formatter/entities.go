@@ -0,0 +1,119 @@
+package formatter
+
+import (
+	"fmt"
+	stdhtml "html"
+	"strconv"
+	"strings"
+)
+
+// Options tunes FormatWithOptions. The zero value matches Format.
+type Options struct {
+	// EncodeEntities re-encodes non-ASCII characters in text and attribute
+	// values as entities. The parser decodes &copy; to a raw ©, so without it
+	// the output is plain UTF-8. Characters with a common name use the named
+	// entity; the rest are written as &#NNN;.
+	EncodeEntities bool
+	// EntityChars limits EncodeEntities to the characters in this string.
+	// Empty means every non-ASCII character.
+	EntityChars string
+	// Lenient builds the tree from the token stream instead of html.Parse,
+	// so misplaced elements are formatted where they were written rather
+	// than moved. See Restructured.
+	Lenient bool
+	// VoidStyle is how void elements such as br, img, and input are closed:
+	// "xhtml" (default) writes <br />, "html" writes <br>.
+	VoidStyle string
+}
+
+// Normalize fills in the default void style and rejects unknown ones.
+func (o Options) Normalize() (Options, error) {
+	o.VoidStyle = strings.ToLower(strings.TrimSpace(o.VoidStyle))
+	switch o.VoidStyle {
+	case "":
+		o.VoidStyle = "xhtml"
+	case "xhtml", "html":
+	default:
+		return o, fmt.Errorf("invalid voidStyle %q (expected xhtml or html)", o.VoidStyle)
+	}
+	return o, nil
+}
+
+// namedEntities maps commonly used characters to their named entity.
+var namedEntities = map[rune]string{
+	'\u00a0': "&nbsp;",
+	'¡':      "&iexcl;",
+	'¢':      "&cent;",
+	'£':      "&pound;",
+	'¥':      "&yen;",
+	'§':      "&sect;",
+	'©':      "&copy;",
+	'«':      "&laquo;",
+	'®':      "&reg;",
+	'°':      "&deg;",
+	'±':      "&plusmn;",
+	'¶':      "&para;",
+	'·':      "&middot;",
+	'»':      "&raquo;",
+	'¿':      "&iquest;",
+	'×':      "&times;",
+	'÷':      "&divide;",
+	'–':      "&ndash;",
+	'—':      "&mdash;",
+	'‘':      "&lsquo;",
+	'’':      "&rsquo;",
+	'“':      "&ldquo;",
+	'”':      "&rdquo;",
+	'•':      "&bull;",
+	'…':      "&hellip;",
+	'€':      "&euro;",
+	'™':      "&trade;",
+	'←':      "&larr;",
+	'→':      "&rarr;",
+}
+
+// escape escapes s for text or attribute output, encoding entities when the
+// options ask for it.
+func (o *Options) escape(s string) string {
+	return o.encodeEntities(stdhtml.EscapeString(s))
+}
+
+// attributeEscaper escapes attribute values apart from their quotes, which
+// quoteAttribute handles.
+var attributeEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// quoteAttribute escapes and quotes an attribute value the way browsers
+// serialize it: in double quotes, in single quotes when it contains a double
+// quote but no single quote, and with &quot; for its double quotes when it
+// contains both.
+func (o *Options) quoteAttribute(val string) string {
+	s := o.encodeEntities(attributeEscaper.Replace(val))
+	switch {
+	case !strings.Contains(val, `"`):
+		return `"` + s + `"`
+	case !strings.Contains(val, "'"):
+		return "'" + s + "'"
+	default:
+		return `"` + strings.ReplaceAll(s, `"`, "&quot;") + `"`
+	}
+}
+
+// encodeEntities writes the non-ASCII characters of s as entities when the
+// options ask for it.
+func (o *Options) encodeEntities(s string) string {
+	if !o.EncodeEntities {
+		return s
+	}
+
+	var b strings.Builder
+	for _, r := range s {
+		if r < 0x80 || (o.EntityChars != "" && !strings.ContainsRune(o.EntityChars, r)) {
+			b.WriteRune(r)
+		} else if name, ok := namedEntities[r]; ok {
+			b.WriteString(name)
+		} else {
+			b.WriteString("&#" + strconv.Itoa(int(r)) + ";")
+		}
+	}
+	return b.String()
+}
@@ -3,20 +3,37 @@ package formatter
 import (
 	"bytes"
 	"fmt"
-	stdhtml "html"
 	"strings"
+	"unicode"
 
+	"github.com/omariomari2/uncluster/internal/htmlparse"
 	"golang.org/x/net/html"
 )
 
 func Format(htmlInput string) (string, error) {
-	doc, err := html.Parse(strings.NewReader(htmlInput))
+	return FormatWithOptions(htmlInput, Options{})
+}
+
+// FormatWithOptions formats htmlInput like Format, applying opts.
+func FormatWithOptions(htmlInput string, opts Options) (string, error) {
+	opts, err := opts.Normalize()
+	if err != nil {
+		return "", err
+	}
+
+	// Fragments are formatted as-is, without implied html, head, and body
+	// elements.
+	parse := htmlparse.Parse
+	if opts.Lenient {
+		parse = parseLenient
+	}
+	doc, err := parse(htmlparse.ToUTF8(htmlInput, ""))
 	if err != nil {
 		return "", fmt.Errorf("failed to parse HTML: %w", err)
 	}
 
 	var buf bytes.Buffer
-	err = formatNode(&buf, doc, 0, false)
+	err = formatNode(&buf, doc, 0, false, &opts)
 	if err != nil {
 		return "", fmt.Errorf("failed to format HTML: %w", err)
 	}
@@ -24,43 +41,64 @@ func Format(htmlInput string) (string, error) {
 	return buf.String(), nil
 }
 
-func formatNode(buf *bytes.Buffer, n *html.Node, depth int, inline bool) error {
+func formatNode(buf *bytes.Buffer, n *html.Node, depth int, inline bool, opts *Options) error {
 	switch n.Type {
 	case html.DocumentNode:
 		for c := n.FirstChild; c != nil; c = c.NextSibling {
-			if err := formatNode(buf, c, depth, inline); err != nil {
+			if err := formatNode(buf, c, depth, inline, opts); err != nil {
 				return err
 			}
 		}
 	case html.ElementNode:
 		if isVoidElement(n.Data) {
 			writeIndent(buf, depth, inline)
-			writeOpenTag(buf, n)
-			buf.WriteString(" />")
+			writeOpenTag(buf, n, opts)
+			if opts.VoidStyle == "html" {
+				buf.WriteString(">")
+			} else {
+				buf.WriteString(" />")
+			}
 			if !inline {
 				buf.WriteString("\n")
 			}
 		} else {
 			writeIndent(buf, depth, inline)
-			writeOpenTag(buf, n)
+			writeOpenTag(buf, n, opts)
 			buf.WriteString(">")
 
-			if isRawTextElement(n.Data) {
+			if isScriptOrStyle(n.Data) && hasChildren(n) {
+				// Script and style bodies are written verbatim; only line
+				// breaks are added so the tags sit on their own lines.
+				content := textContent(n)
+				if !strings.HasPrefix(content, "\n") {
+					buf.WriteString("\n")
+				}
+				buf.WriteString(content)
+				if !strings.HasSuffix(content, "\n") {
+					buf.WriteString("\n")
+				}
+				if !inline {
+					buf.WriteString(strings.Repeat("\t", depth))
+				}
+			} else if isRawTextElement(n.Data) {
 				for c := n.FirstChild; c != nil; c = c.NextSibling {
-					if err := formatNode(buf, c, 0, true); err != nil {
+					if err := formatNode(buf, c, 0, true, opts); err != nil {
 						return err
 					}
 				}
 			} else if shouldInlineChildren(n) {
 				for c := n.FirstChild; c != nil; c = c.NextSibling {
-					if err := formatNode(buf, c, 0, true); err != nil {
+					if err := formatNode(buf, c, 0, true, opts); err != nil {
 						return err
 					}
 				}
 			} else if hasChildren(n) {
 				buf.WriteString("\n")
 				for c := n.FirstChild; c != nil; c = c.NextSibling {
-					if err := formatNode(buf, c, depth+1, false); err != nil {
+					if isWhitespaceText(c) {
+						continue
+					}
+					if err := formatNode(buf, c, depth+1, false, opts); err != nil {
 						return err
 					}
 				}
@@ -79,7 +117,7 @@ func formatNode(buf *bytes.Buffer, n *html.Node, depth int, inline bool) error {
 		if n.Parent != nil && isRawTextElement(n.Parent.Data) {
 			buf.WriteString(n.Data)
 		} else {
-			buf.WriteString(stdhtml.EscapeString(n.Data))
+			buf.WriteString(opts.escape(collapseText(n)))
 		}
 
 	case html.CommentNode:
@@ -94,9 +132,7 @@ func formatNode(buf *bytes.Buffer, n *html.Node, depth int, inline bool) error {
 		}
 
 	case html.DoctypeNode:
-		buf.WriteString("<!DOCTYPE ")
-		buf.WriteString(n.Data)
-		buf.WriteString(">")
+		buf.WriteString(Doctype(n))
 		if !inline {
 			buf.WriteString("\n")
 		}
@@ -105,6 +141,43 @@ func formatNode(buf *bytes.Buffer, n *html.Node, depth int, inline bool) error {
 	return nil
 }
 
+// Doctype renders a doctype node, keeping the public and system identifiers
+// of legacy doctypes such as XHTML 1.0 Strict.
+func Doctype(n *html.Node) string {
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE ")
+	b.WriteString(n.Data)
+
+	var public, system string
+	var hasPublic, hasSystem bool
+	for _, attr := range n.Attr {
+		switch attr.Key {
+		case "public":
+			public, hasPublic = attr.Val, true
+		case "system":
+			system, hasSystem = attr.Val, true
+		}
+	}
+
+	if hasPublic {
+		b.WriteString(` PUBLIC "`)
+		b.WriteString(public)
+		b.WriteString(`"`)
+		if hasSystem {
+			b.WriteString(` "`)
+			b.WriteString(system)
+			b.WriteString(`"`)
+		}
+	} else if hasSystem {
+		b.WriteString(` SYSTEM "`)
+		b.WriteString(system)
+		b.WriteString(`"`)
+	}
+
+	b.WriteString(">")
+	return b.String()
+}
+
 func writeIndent(buf *bytes.Buffer, depth int, inline bool) {
 	if inline {
 		return
@@ -112,30 +185,28 @@ func writeIndent(buf *bytes.Buffer, depth int, inline bool) {
 	buf.WriteString(strings.Repeat("\t", depth))
 }
 
-func writeOpenTag(buf *bytes.Buffer, n *html.Node) {
+func writeOpenTag(buf *bytes.Buffer, n *html.Node, opts *Options) {
 	buf.WriteString("<")
 	buf.WriteString(n.Data)
 
 	for _, attr := range n.Attr {
 		buf.WriteString(" ")
 		buf.WriteString(attr.Key)
-		buf.WriteString(`="`)
-		buf.WriteString(escapeAttributeValue(attr.Val))
-		buf.WriteString(`"`)
+		buf.WriteString("=")
+		buf.WriteString(opts.quoteAttribute(attr.Val))
 	}
 }
 
-func escapeAttributeValue(value string) string {
-	return stdhtml.EscapeString(value)
-}
-
+// shouldInlineChildren reports whether n's children must be written inline.
+// Comments don't count: in block layout they keep their own line, so markers
+// such as <!-- [Webflow] --> stay between the elements they separate.
 func shouldInlineChildren(n *html.Node) bool {
 	for c := n.FirstChild; c != nil; c = c.NextSibling {
 		switch c.Type {
 		case html.TextNode:
-			return true
-		case html.CommentNode:
-			return true
+			if !isWhitespaceText(c) {
+				return true
+			}
 		case html.ElementNode:
 			if !isBlockElement(c.Data) {
 				return true
@@ -145,12 +216,63 @@ func shouldInlineChildren(n *html.Node) bool {
 	return false
 }
 
+func isWhitespaceText(n *html.Node) bool {
+	return n.Type == html.TextNode && strings.TrimSpace(n.Data) == ""
+}
+
+// collapseText returns the text to write for a text node. Whitespace-only text
+// between two inline elements is significant and collapses to a single space;
+// elsewhere it is dropped. Whitespace at the start or end of a block element
+// is trimmed.
+func collapseText(n *html.Node) string {
+	if isWhitespaceText(n) {
+		if isInlineElement(n.PrevSibling) && isInlineElement(n.NextSibling) {
+			return " "
+		}
+		return ""
+	}
+
+	text := n.Data
+	if n.Parent != nil && isBlockElement(n.Parent.Data) {
+		if n.PrevSibling == nil {
+			text = strings.TrimLeftFunc(text, unicode.IsSpace)
+		}
+		if n.NextSibling == nil {
+			text = strings.TrimRightFunc(text, unicode.IsSpace)
+		}
+	}
+	return text
+}
+
+func isInlineElement(n *html.Node) bool {
+	return n != nil && n.Type == html.ElementNode && !isBlockElement(n.Data)
+}
+
+func isScriptOrStyle(tagName string) bool {
+	tagName = strings.ToLower(tagName)
+	return tagName == "script" || tagName == "style"
+}
+
+func textContent(n *html.Node) string {
+	var b strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.TextNode {
+			b.WriteString(c.Data)
+		}
+	}
+	return b.String()
+}
+
+// isRawTextElement reports whether the text inside tagName is written as-is.
+// The parser keeps noscript content as unparsed text, so escaping it would
+// turn the fallback markup into visible text.
 func isRawTextElement(tagName string) bool {
 	rawTextElements := map[string]bool{
 		"script":   true,
 		"style":    true,
 		"pre":      true,
 		"textarea": true,
+		"noscript": true,
 	}
 	return rawTextElements[strings.ToLower(tagName)]
 }
@@ -169,6 +291,7 @@ func isBlockElement(tagName string) bool {
 		"address":    true,
 		"article":    true,
 		"aside":      true,
+		"base":       true,
 		"blockquote": true,
 		"body":       true,
 		"canvas":     true,
@@ -192,18 +315,23 @@ func isBlockElement(tagName string) bool {
 		"hr":         true,
 		"html":       true,
 		"li":         true,
+		"link":       true,
 		"main":       true,
+		"meta":       true,
 		"nav":        true,
 		"noscript":   true,
 		"ol":         true,
 		"p":          true,
+		"script":     true,
 		"section":    true,
+		"style":      true,
 		"table":      true,
 		"tbody":      true,
 		"td":         true,
 		"tfoot":      true,
 		"th":         true,
 		"thead":      true,
+		"title":      true,
 		"tr":         true,
 		"ul":         true,
 	}
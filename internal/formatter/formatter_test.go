@@ -0,0 +1,190 @@
+package formatter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormatPreservesHTML5Doctype(t *testing.T) {
+	out, err := Format(`<!DOCTYPE html><html lang="en"><head><title>x</title></head><body><p>hi</p></body></html>`)
+	if err != nil {
+		t.Fatalf("Format returned error: %v", err)
+	}
+	if !strings.HasPrefix(out, "<!DOCTYPE html>\n") {
+		t.Fatalf("expected HTML5 doctype first, got:\n%s", out)
+	}
+	if !strings.Contains(out, `<html lang="en">`) {
+		t.Fatalf("expected lang attribute to survive, got:\n%s", out)
+	}
+}
+
+func TestFormatPreservesLegacyXHTMLDoctype(t *testing.T) {
+	doctype := `<!DOCTYPE html PUBLIC "-//W3C//DTD XHTML 1.0 Strict//EN" "http://www.w3.org/TR/xhtml1/DTD/xhtml1-strict.dtd">`
+	out, err := Format(doctype + `<html xmlns="http://www.w3.org/1999/xhtml" lang="en"><head><title>x</title></head><body></body></html>`)
+	if err != nil {
+		t.Fatalf("Format returned error: %v", err)
+	}
+	if !strings.HasPrefix(out, doctype+"\n") {
+		t.Fatalf("expected legacy doctype to be preserved, got:\n%s", out)
+	}
+}
+
+func TestFormatKeepsScriptContentVerbatim(t *testing.T) {
+	script := "var a = 1; // trailing comment\nvar b = a + 1;\n  if (a < b) { console.log('<ok>'); }"
+	out, err := Format("<html><head><script>" + script + "</script><style>p > a { color: red; } /* note */</style></head><body></body></html>")
+	if err != nil {
+		t.Fatalf("Format returned error: %v", err)
+	}
+
+	if !strings.Contains(out, "\t\t<script>\n"+script+"\n\t\t</script>\n") {
+		t.Fatalf("expected script body to be emitted verbatim on its own lines, got:\n%s", out)
+	}
+	if !strings.Contains(out, "\t\t<style>\np > a { color: red; } /* note */\n\t\t</style>\n") {
+		t.Fatalf("expected style body to be emitted verbatim, got:\n%s", out)
+	}
+}
+
+func TestFormatWithOptionsEncodesEntities(t *testing.T) {
+	input := `<p title="&copy; 2024">&copy; Acme &mdash; caf&eacute;</p>`
+
+	raw, err := Format(input)
+	if err != nil {
+		t.Fatalf("Format returned error: %v", err)
+	}
+	if !strings.Contains(raw, "© Acme — café") {
+		t.Fatalf("expected raw UTF-8 by default, got:\n%s", raw)
+	}
+
+	encoded, err := FormatWithOptions(input, Options{EncodeEntities: true})
+	if err != nil {
+		t.Fatalf("FormatWithOptions returned error: %v", err)
+	}
+	for _, want := range []string{`title="&copy; 2024"`, "&copy; Acme &mdash; caf&#233;"} {
+		if !strings.Contains(encoded, want) {
+			t.Fatalf("expected %q, got:\n%s", want, encoded)
+		}
+	}
+
+	limited, err := FormatWithOptions(input, Options{EncodeEntities: true, EntityChars: "©"})
+	if err != nil {
+		t.Fatalf("FormatWithOptions returned error: %v", err)
+	}
+	if !strings.Contains(limited, "&copy; Acme — café") {
+		t.Fatalf("expected only © to be encoded, got:\n%s", limited)
+	}
+}
+
+func TestFormatWithOptionsVoidStyle(t *testing.T) {
+	input := `<p>Line<br>next</p><img src="a.png" alt=""><input type="text" name="q">`
+
+	for style, want := range map[string][]string{
+		"":      {"<br />", `<img src="a.png" alt="" />`, `<input type="text" name="q" />`},
+		"xhtml": {"<br />", `<img src="a.png" alt="" />`, `<input type="text" name="q" />`},
+		"html":  {"<br>", `<img src="a.png" alt="">`, `<input type="text" name="q">`},
+	} {
+		out, err := FormatWithOptions(input, Options{VoidStyle: style})
+		if err != nil {
+			t.Fatalf("voidStyle %q: FormatWithOptions returned error: %v", style, err)
+		}
+		for _, tag := range want {
+			if !strings.Contains(out, tag) {
+				t.Fatalf("voidStyle %q: expected %s, got:\n%s", style, tag, out)
+			}
+		}
+		if style == "html" && strings.Contains(out, "/>") {
+			t.Fatalf("voidStyle html: expected no self-closing tags, got:\n%s", out)
+		}
+	}
+
+	if _, err := FormatWithOptions(input, Options{VoidStyle: "xml"}); err == nil || !strings.Contains(err.Error(), "expected xhtml or html") {
+		t.Fatalf("expected an unknown void style to be rejected, got %v", err)
+	}
+}
+
+func TestFormatKeepsSpaceBetweenInlineElements(t *testing.T) {
+	formatted, err := Format("<p>\n  <a href=\"/a\">click</a> <a href=\"/b\">here</a>\n</p><div>\n  <p>one</p>\n  <p>two</p>\n</div>")
+	if err != nil {
+		t.Fatalf("Format returned error: %v", err)
+	}
+
+	if !strings.Contains(formatted, "<p><a href=\"/a\">click</a> <a href=\"/b\">here</a></p>") {
+		t.Fatalf("expected a single space between the links and no padding inside <p>, got:\n%s", formatted)
+	}
+	if !strings.Contains(formatted, "<div>\n\t<p>one</p>\n\t<p>two</p>\n</div>") {
+		t.Fatalf("expected whitespace between block elements to be dropped, got:\n%s", formatted)
+	}
+}
+
+func TestFormatLeavesFragmentsUnwrapped(t *testing.T) {
+	cases := map[string]string{
+		"<li>one</li><li>two</li>": "<li>one</li>\n<li>two</li>\n",
+		"just text":                "just text",
+	}
+	for input, want := range cases {
+		formatted, err := Format(input)
+		if err != nil {
+			t.Fatalf("Format(%q) returned error: %v", input, err)
+		}
+		if formatted != want {
+			t.Fatalf("Format(%q) = %q, want %q", input, formatted, want)
+		}
+	}
+}
+
+func TestFormatKeepsCommentsBetweenBlocks(t *testing.T) {
+	out, err := Format("<html><head></head><body><section><p>one</p></section><!-- [Webflow] --><section><p>two</p></section></body></html>")
+	if err != nil {
+		t.Fatalf("Format returned error: %v", err)
+	}
+	want := "\t\t</section>\n\t\t<!-- [Webflow] -->\n\t\t<section>\n"
+	if !strings.Contains(out, want) {
+		t.Fatalf("expected comment on its own line between sections, got:\n%s", out)
+	}
+}
+
+func TestFormatKeepsNoscriptFallbackMarkup(t *testing.T) {
+	out, err := Format(`<html><head></head><body><noscript><img src="https://px.example.com/p.gif?a=1&amp;b=2" height="1" width="1"></noscript><p>x</p></body></html>`)
+	if err != nil {
+		t.Fatalf("Format returned error: %v", err)
+	}
+	want := `<noscript><img src="https://px.example.com/p.gif?a=1&amp;b=2" height="1" width="1"></noscript>`
+	if !strings.Contains(out, want) {
+		t.Fatalf("expected noscript content to be written verbatim, got:\n%s", out)
+	}
+}
+
+func TestFormatQuotesAttributesContainingQuotes(t *testing.T) {
+	cases := map[string]string{
+		`<span title='say "hi"'>x</span>`:            `<span title='say "hi"'>x</span>`,
+		`<span title="it's">x</span>`:                `<span title="it's">x</span>`,
+		`<span title="it's &quot;hi&quot;">x</span>`: `<span title="it's &quot;hi&quot;">x</span>`,
+		`<span title="a &amp; b < c">x</span>`:       `<span title="a &amp; b &lt; c">x</span>`,
+	}
+	for input, want := range cases {
+		formatted, err := Format(input)
+		if err != nil {
+			t.Fatalf("Format(%q) returned error: %v", input, err)
+		}
+		if strings.TrimSpace(formatted) != want {
+			t.Fatalf("Format(%q) = %q, want %q", input, formatted, want)
+		}
+	}
+}
+
+func TestFormatDecodesBOMAndLegacyEncodings(t *testing.T) {
+	out, err := Format("\ufeff<!DOCTYPE html><html><body><p>café</p></body></html>")
+	if err != nil {
+		t.Fatalf("Format returned error: %v", err)
+	}
+	if strings.Contains(out, "\ufeff") || !strings.HasPrefix(out, "<!DOCTYPE html>") {
+		t.Fatalf("expected the byte order mark to be stripped, got %q", out)
+	}
+
+	out, err = Format("<html><head><meta charset=\"ISO-8859-1\"></head><body><p>Cr\xe8me br\xfbl\xe9e</p></body></html>")
+	if err != nil {
+		t.Fatalf("Format returned error: %v", err)
+	}
+	if !strings.Contains(out, "<p>Crème brûlée</p>") {
+		t.Fatalf("expected latin-1 text decoded to UTF-8, got:\n%s", out)
+	}
+}
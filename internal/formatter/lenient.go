@@ -0,0 +1,139 @@
+package formatter
+
+import (
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/omariomari2/uncluster/internal/htmlparse"
+	"golang.org/x/net/html"
+)
+
+// impliedEnds lists, for a start tag, the open elements it closes the way a
+// browser would, so <li>one<li>two gives two sibling items.
+var impliedEnds = map[string]map[string]bool{
+	"li":     {"li": true},
+	"dt":     {"dt": true, "dd": true},
+	"dd":     {"dt": true, "dd": true},
+	"option": {"option": true},
+	"tr":     {"tr": true, "td": true, "th": true},
+	"td":     {"td": true, "th": true},
+	"th":     {"td": true, "th": true},
+}
+
+// parseLenient builds a tree straight from the token stream. Unlike
+// html.Parse it never moves an element: a <style> between </head> and <body>
+// or a <div> inside <head> stays where it was written, and no html, head,
+// body, or tbody elements are added. Stray end tags are ignored.
+func parseLenient(input string) (*html.Node, error) {
+	doc := &html.Node{Type: html.DocumentNode}
+	open := []*html.Node{doc}
+
+	z := html.NewTokenizer(strings.NewReader(input))
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			if z.Err() == io.EOF {
+				return doc, nil
+			}
+			return nil, z.Err()
+		}
+
+		t := z.Token()
+		current := open[len(open)-1]
+		switch tt {
+		case html.TextToken:
+			current.AppendChild(&html.Node{Type: html.TextNode, Data: t.Data})
+		case html.CommentToken:
+			current.AppendChild(&html.Node{Type: html.CommentNode, Data: t.Data})
+		case html.DoctypeToken:
+			current.AppendChild(&html.Node{Type: html.DoctypeNode, Data: t.Data})
+		case html.StartTagToken, html.SelfClosingTagToken:
+			open = closeImpliedElements(open, t.Data)
+			n := &html.Node{Type: html.ElementNode, Data: t.Data, DataAtom: t.DataAtom, Attr: t.Attr}
+			open[len(open)-1].AppendChild(n)
+			if tt == html.StartTagToken && !isVoidElement(t.Data) {
+				open = append(open, n)
+			}
+		case html.EndTagToken:
+			for i := len(open) - 1; i > 0; i-- {
+				if open[i].Data == t.Data {
+					open = open[:i]
+					break
+				}
+			}
+		}
+	}
+}
+
+// closeImpliedElements pops the open elements that a tag start ends: the ones
+// in impliedEnds, and an open <p> when a block element starts.
+func closeImpliedElements(open []*html.Node, tag string) []*html.Node {
+	for len(open) > 1 {
+		current := open[len(open)-1].Data
+		if !impliedEnds[tag][current] && !(current == "p" && isBlockElement(tag)) {
+			break
+		}
+		open = open[:len(open)-1]
+	}
+	return open
+}
+
+// placement is an element and the element it was placed in.
+type placement struct {
+	tag, parent string
+}
+
+// impliableElements are the elements html.Parse adds when the input leaves
+// them out.
+var impliableElements = map[string]bool{"html": true, "head": true, "body": true}
+
+// Restructured reports whether html.Parse, which Format uses, places any
+// element of htmlInput somewhere other than where it was written: a <div> in
+// <head> moved to <body>, a <style> after </head> moved into it, or misnested
+// tags split and reopened. Implied html, head, body, and tbody elements don't
+// count. Formatting with Options.Lenient keeps the original structure.
+func Restructured(htmlInput string) (bool, error) {
+	parsed, err := htmlparse.Parse(htmlInput)
+	if err != nil {
+		return false, err
+	}
+	written, err := parseLenient(htmlInput)
+	if err != nil {
+		return false, err
+	}
+
+	want := placements(written, func(n *html.Node) bool { return n.Data != "tbody" })
+	explicit := make(map[string]bool)
+	for _, p := range want {
+		if impliableElements[p.tag] {
+			explicit[p.tag] = true
+		}
+	}
+	got := placements(parsed, func(n *html.Node) bool {
+		return n.Data != "tbody" && (!impliableElements[n.Data] || explicit[n.Data])
+	})
+	return !slices.Equal(want, got), nil
+}
+
+// placements lists, in document order, the elements under root that keep
+// accepts, each with the nearest accepted ancestor as its parent.
+func placements(root *html.Node, keep func(*html.Node) bool) []placement {
+	var out []placement
+	var walk func(n *html.Node, parent string)
+	walk = func(n *html.Node, parent string) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode {
+				continue
+			}
+			if keep(c) {
+				out = append(out, placement{tag: c.Data, parent: parent})
+				walk(c, c.Data)
+			} else {
+				walk(c, parent)
+			}
+		}
+	}
+	walk(root, "")
+	return out
+}
@@ -0,0 +1,46 @@
+package formatter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRestructuredDetectsMisplacedStyle(t *testing.T) {
+	input := `<html><head><title>x</title></head><style>p { color: red; }</style><body><p>a</p></body></html>`
+
+	restructured, err := Restructured(input)
+	if err != nil {
+		t.Fatalf("Restructured returned error: %v", err)
+	}
+	if !restructured {
+		t.Fatal("expected a style between </head> and <body> to be reported as moved")
+	}
+
+	out, err := FormatWithOptions(input, Options{Lenient: true})
+	if err != nil {
+		t.Fatalf("FormatWithOptions returned error: %v", err)
+	}
+	head := strings.Index(out, "</head>")
+	style := strings.Index(out, "<style>")
+	body := strings.Index(out, "<body>")
+	if head < 0 || style < head || body < style {
+		t.Fatalf("expected the style to stay between head and body, got:\n%s", out)
+	}
+}
+
+func TestRestructuredIgnoresImpliedElements(t *testing.T) {
+	for _, input := range []string{
+		`<!DOCTYPE html><html><head><title>x</title></head><body><ul><li>a<li>b</ul><p>one<p>two</body></html>`,
+		`<title>x</title><p>no head or body tags</p>`,
+		`<table><tr><td>1<td>2</table>`,
+		`<li>fragment</li><li>items</li>`,
+	} {
+		restructured, err := Restructured(input)
+		if err != nil {
+			t.Fatalf("Restructured returned error: %v", err)
+		}
+		if restructured {
+			t.Fatalf("expected %q not to count as restructured", input)
+		}
+	}
+}
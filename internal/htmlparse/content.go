@@ -0,0 +1,20 @@
+package htmlparse
+
+import "golang.org/x/net/html"
+
+// IsNonContent reports whether n is an element that starts a subtree that is
+// not page content: scripts, styles, head metadata, templates, and inline SVG
+// with its shapes, which would otherwise be read as markup to extract or
+// tally as patterns.
+func IsNonContent(n *html.Node) bool {
+	if n.Type != html.ElementNode {
+		return false
+	}
+	switch n.Data {
+	case "script", "style", "link", "meta", "title", "noscript", "template",
+		"svg", "path", "circle", "rect", "line", "polygon", "polyline", "defs", "g", "use":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,36 @@
+package htmlparse
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html/charset"
+)
+
+// byteOrderMark is U+FEFF as it appears at the start of UTF-8 text saved by
+// some editors.
+const byteOrderMark = "\ufeff"
+
+// metaCharset matches the charset a <meta charset> or http-equiv Content-Type
+// tag declares, with everything before it in the tag as the first group.
+var metaCharset = regexp.MustCompile(`(?i)(<meta\b[^>]*?\bcharset\s*=\s*["']?)[^"'\s;/>]+`)
+
+// ToUTF8 returns input as UTF-8 without a leading byte order mark. Input that
+// is already valid UTF-8 is kept as is, whatever its markup declares, since
+// pasted text has been decoded once already. Anything else is decoded from the
+// encoding named by a byte order mark, contentType (an HTTP Content-Type
+// value, may be empty), or the document's <meta charset> or http-equiv
+// Content-Type, falling back to windows-1252 as browsers do. Either way the
+// charset the markup declares is rewritten to utf-8, so that saving the result
+// does not produce a page that misreads itself.
+func ToUTF8(input, contentType string) string {
+	if !utf8.ValidString(input) {
+		enc, _, _ := charset.DetermineEncoding([]byte(input), contentType)
+		if decoded, err := enc.NewDecoder().String(input); err == nil {
+			input = decoded
+		}
+	}
+	input = metaCharset.ReplaceAllString(input, "${1}utf-8")
+	return strings.TrimPrefix(input, byteOrderMark)
+}
@@ -0,0 +1,72 @@
+// Package htmlparse parses user-supplied markup that may be a whole document
+// or a bare fragment such as "<li>one</li><li>two</li>".
+package htmlparse
+
+import (
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// documentMarkup matches the tags that mark input as a whole document.
+var documentMarkup = regexp.MustCompile(`(?i)<(?:!doctype|html|head|body)[\s>/]`)
+
+// firstTag captures the name of the first start tag, skipping comments.
+var firstTag = regexp.MustCompile(`(?s)^\s*(?:<!--.*?-->\s*)*<([a-zA-Z][a-zA-Z0-9-]*)`)
+
+// fragmentContexts maps a fragment's first tag to the element it must be
+// parsed inside to survive; anything else is parsed as <body> content.
+var fragmentContexts = map[string]atom.Atom{
+	"tr":       atom.Tbody,
+	"td":       atom.Tr,
+	"th":       atom.Tr,
+	"thead":    atom.Table,
+	"tbody":    atom.Table,
+	"tfoot":    atom.Table,
+	"caption":  atom.Table,
+	"colgroup": atom.Table,
+	"col":      atom.Colgroup,
+	"option":   atom.Select,
+	"optgroup": atom.Select,
+}
+
+// IsFragment reports whether input is a fragment: markup with no DOCTYPE,
+// <html>, <head>, or <body> tag. Plain text is a fragment too.
+func IsFragment(input string) bool {
+	return !documentMarkup.MatchString(input)
+}
+
+// Parse parses input with html.Parse when it is a document. A fragment is
+// parsed with html.ParseFragment instead, so none of the implied html, head,
+// and body elements are added: the returned DocumentNode holds the fragment's
+// nodes as its direct children. Input is first converted with ToUTF8.
+func Parse(input string) (*html.Node, error) {
+	input = ToUTF8(input, "")
+	if !IsFragment(input) {
+		return html.Parse(strings.NewReader(input))
+	}
+
+	context := atom.Body
+	if m := firstTag.FindStringSubmatch(input); m != nil {
+		if a, ok := fragmentContexts[strings.ToLower(m[1])]; ok {
+			context = a
+		}
+	}
+
+	nodes, err := html.ParseFragment(strings.NewReader(input), &html.Node{
+		Type:     html.ElementNode,
+		Data:     context.String(),
+		DataAtom: context,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &html.Node{Type: html.DocumentNode}
+	for _, n := range nodes {
+		doc.AppendChild(n)
+	}
+	return doc, nil
+}
@@ -0,0 +1,106 @@
+package htmlparse
+
+import (
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+func TestIsFragment(t *testing.T) {
+	cases := map[string]bool{
+		"<li>one</li><li>two</li>":               true,
+		"just some text":                         true,
+		"<div class=\"body-copy\">x</div>":       true,
+		"<!DOCTYPE html><p>x</p>":                false,
+		"<html><body><p>x</p></body></html>":     false,
+		"  <body class=\"page\"><p>x</p></body>": false,
+	}
+	for input, want := range cases {
+		if got := IsFragment(input); got != want {
+			t.Fatalf("IsFragment(%q) = %v, want %v", input, got, want)
+		}
+	}
+}
+
+func TestParseKeepsFragmentsAsIs(t *testing.T) {
+	cases := map[string][]string{
+		"<li>one</li><li>two</li>":  {"li", "li"},
+		"<tr><td>a</td></tr>":       {"tr"},
+		"<td>a</td><td>b</td>":      {"td", "td"},
+		"<option>a</option>":        {"option"},
+		"hello <b>world</b>":        {"#text", "b"},
+		"<!-- note --><li>one</li>": {"#comment", "li"},
+	}
+	for input, want := range cases {
+		doc, err := Parse(input)
+		if err != nil {
+			t.Fatalf("Parse(%q) returned error: %v", input, err)
+		}
+		var got []string
+		for n := doc.FirstChild; n != nil; n = n.NextSibling {
+			switch n.Type {
+			case html.TextNode:
+				got = append(got, "#text")
+			case html.CommentNode:
+				got = append(got, "#comment")
+			default:
+				got = append(got, n.Data)
+			}
+		}
+		if len(got) != len(want) {
+			t.Fatalf("Parse(%q) top-level nodes = %v, want %v", input, got, want)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("Parse(%q) top-level nodes = %v, want %v", input, got, want)
+			}
+		}
+	}
+}
+
+func TestParseDocumentAddsScaffolding(t *testing.T) {
+	doc, err := Parse("<!DOCTYPE html><p>x</p>")
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+	if doc.LastChild == nil || doc.LastChild.Data != "html" {
+		t.Fatalf("expected a full document with an <html> root")
+	}
+}
+
+func TestToUTF8(t *testing.T) {
+	cases := []struct {
+		name, input, contentType, want string
+	}{
+		{"bom", "\ufeff<p>café</p>", "", "<p>café</p>"},
+		{"latin-1 meta", "<meta charset=\"iso-8859-1\"><p>caf\xe9</p>", "", "<meta charset=\"utf-8\"><p>café</p>"},
+		{"latin-1 http-equiv", "<META HTTP-EQUIV=\"Content-Type\" CONTENT=\"text/html; charset=ISO-8859-1\"><p>caf\xe9</p>", "", "<META HTTP-EQUIV=\"Content-Type\" CONTENT=\"text/html; charset=utf-8\"><p>café</p>"},
+		{"latin-1 header", "<p>caf\xe9</p>", "text/html; charset=ISO-8859-1", "<p>café</p>"},
+		{"utf-8 despite meta", "<meta charset=iso-8859-1><p>café</p>", "", "<meta charset=utf-8><p>café</p>"},
+		{"charset in text", "<p>charset=latin1</p>", "", "<p>charset=latin1</p>"},
+	}
+	for _, tc := range cases {
+		if got := ToUTF8(tc.input, tc.contentType); got != tc.want {
+			t.Fatalf("%s: expected %q, got %q", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestIsNonContent(t *testing.T) {
+	doc, err := Parse(`<div><script>x()</script><template><p>t</p></template><svg><path d="M0"></path></svg><p>text</p></div>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]bool{"script": true, "template": true, "svg": true, "p": false}
+	for c := doc.FirstChild.FirstChild; c != nil; c = c.NextSibling {
+		if got := IsNonContent(c); got != want[c.Data] {
+			t.Fatalf("IsNonContent(<%s>) = %v, want %v", c.Data, got, want[c.Data])
+		}
+	}
+	if path := doc.FirstChild.LastChild.PrevSibling.FirstChild; !IsNonContent(path) {
+		t.Fatalf("expected the svg's <%s> to be non-content", path.Data)
+	}
+	if IsNonContent(&html.Node{Type: html.TextNode, Data: "text"}) {
+		t.Fatalf("expected a text node not to be non-content")
+	}
+}
@@ -0,0 +1,14 @@
+// Package jsxtext escapes text for a JSX child position, for the converter
+// and the component analyzer, which both write JSX.
+package jsxtext
+
+import "strings"
+
+// escaper escapes the characters JSX would otherwise read as markup or
+// expressions. JSX text accepts HTML entities, so they render unchanged.
+var escaper = strings.NewReplacer("<", "&lt;", ">", "&gt;", "{", "&#123;", "}", "&#125;")
+
+// Escape returns s with <, >, {, and } replaced by HTML entities.
+func Escape(s string) string {
+	return escaper.Replace(s)
+}
@@ -0,0 +1,17 @@
+package jsxtext
+
+import "testing"
+
+func TestEscape(t *testing.T) {
+	cases := map[string]string{
+		"plain text":            "plain text",
+		"a < b > c":             "a &lt; b &gt; c",
+		"{price} & {tax}":       "&#123;price&#125; & &#123;tax&#125;",
+		"already &amp; escaped": "already &amp; escaped",
+	}
+	for in, want := range cases {
+		if got := Escape(in); got != want {
+			t.Errorf("Escape(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestFromContextTagsRequestID(t *testing.T) {
+	var buf bytes.Buffer
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, nil)))
+
+	FromContext(WithRequestID(context.Background(), "req-1")).Info("first")
+	FromContext(context.Background()).Info("second")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected two log lines, got %q", buf.String())
+	}
+	if !strings.Contains(lines[0], "request_id=req-1") {
+		t.Fatalf("expected request ID on the first line, got %q", lines[0])
+	}
+	if strings.Contains(lines[1], "request_id") {
+		t.Fatalf("expected no request ID without one in the context, got %q", lines[1])
+	}
+}
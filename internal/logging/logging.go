@@ -0,0 +1,33 @@
+// Package logging configures the process-wide slog logger. Packages log
+// through log/slog directly; this package only decides the level and format.
+package logging
+
+import (
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// ParseLevel maps a level name (debug, info, warn or warning, error) to a
+// slog.Level. It reports false for anything else.
+func ParseLevel(name string) (slog.Level, bool) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "debug":
+		return slog.LevelDebug, true
+	case "info":
+		return slog.LevelInfo, true
+	case "warn", "warning":
+		return slog.LevelWarn, true
+	case "error":
+		return slog.LevelError, true
+	}
+	return slog.LevelInfo, false
+}
+
+// Setup installs a text logger on stderr as the slog default, at the level
+// named by LOG_LEVEL. Unset or unknown values mean info.
+func Setup() {
+	level, _ := ParseLevel(os.Getenv("LOG_LEVEL"))
+	handler := slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level})
+	slog.SetDefault(slog.New(handler))
+}
@@ -0,0 +1,24 @@
+package logging
+
+import (
+	"log/slog"
+	"testing"
+)
+
+func TestParseLevel(t *testing.T) {
+	cases := map[string]slog.Level{
+		"debug":   slog.LevelDebug,
+		" INFO ":  slog.LevelInfo,
+		"warning": slog.LevelWarn,
+		"Error":   slog.LevelError,
+	}
+	for name, want := range cases {
+		got, ok := ParseLevel(name)
+		if !ok || got != want {
+			t.Fatalf("ParseLevel(%q) = %v, %v; want %v", name, got, ok, want)
+		}
+	}
+	if level, ok := ParseLevel("verbose"); ok || level != slog.LevelInfo {
+		t.Fatalf("expected unknown level to fall back to info, got %v, %v", level, ok)
+	}
+}
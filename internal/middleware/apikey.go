@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"os"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// APIKeysFromEnv returns the comma-separated keys in the API_KEYS environment
+// variable, or nil when it is unset or empty.
+func APIKeysFromEnv() []string {
+	var keys []string
+	for _, key := range strings.Split(os.Getenv("API_KEYS"), ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// APIKeyAuth requires a matching key in either the "Authorization: Bearer"
+// or "X-API-Key" header. Requests to publicPaths are let through. With no
+// keys configured the middleware allows every request.
+func APIKeyAuth(keys []string, publicPaths ...string) fiber.Handler {
+	public := make(map[string]bool, len(publicPaths))
+	for _, path := range publicPaths {
+		public[path] = true
+	}
+
+	return func(c *fiber.Ctx) error {
+		if len(keys) == 0 || public[c.Path()] || c.Method() == fiber.MethodOptions {
+			return c.Next()
+		}
+
+		provided := c.Get("X-API-Key")
+		if auth := c.Get(fiber.HeaderAuthorization); provided == "" && len(auth) > 7 && strings.EqualFold(auth[:7], "Bearer ") {
+			provided = strings.TrimSpace(auth[7:])
+		}
+
+		if provided == "" || !matchesAnyKey(provided, keys) {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"success": false,
+				"error":   "A valid API key is required",
+			})
+		}
+
+		return c.Next()
+	}
+}
+
+// matchesAnyKey compares provided against every key in constant time, without
+// stopping at the first match.
+func matchesAnyKey(provided string, keys []string) bool {
+	match := 0
+	for _, key := range keys {
+		match |= subtle.ConstantTimeCompare([]byte(provided), []byte(key))
+	}
+	return match == 1
+}
@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// DecompressBody replaces a gzip or deflate encoded request body with its
+// decompressed bytes, so BodyParser and FormFile see plain content. Bodies
+// that decompress to more than limit bytes are rejected with 413 before they
+// are fully expanded; undecodable bodies get 400 and other encodings 415.
+func DecompressBody(limit int) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		encoding := strings.ToLower(strings.TrimSpace(c.Get(fiber.HeaderContentEncoding)))
+		if encoding == "" || encoding == "identity" {
+			return c.Next()
+		}
+
+		// c.Body() would decode the body itself, without a size limit, so
+		// read the raw bytes.
+		body, err := decompress(encoding, c.Request().Body(), limit)
+		if err != nil {
+			status := fiber.StatusBadRequest
+			if errors.Is(err, errUnsupportedEncoding) {
+				status = fiber.StatusUnsupportedMediaType
+			} else if errors.Is(err, errBodyTooLarge) {
+				status = fiber.StatusRequestEntityTooLarge
+			}
+			return c.Status(status).JSON(fiber.Map{
+				"success": false,
+				"error":   fmt.Sprintf("could not decode %s request body: %v", encoding, err),
+			})
+		}
+
+		c.Request().Header.Del(fiber.HeaderContentEncoding)
+		c.Request().SetBody(body)
+		return c.Next()
+	}
+}
+
+var (
+	errUnsupportedEncoding = errors.New("unsupported content encoding (expected gzip or deflate)")
+	errBodyTooLarge        = errors.New("decompressed body is too large")
+)
+
+// decompress expands data, reading at most limit bytes of output. deflate is
+// zlib-wrapped per RFC 9110, but raw deflate streams are accepted too since
+// some clients send them.
+func decompress(encoding string, data []byte, limit int) ([]byte, error) {
+	var r io.ReadCloser
+	var err error
+	switch encoding {
+	case "gzip", "x-gzip":
+		r, err = gzip.NewReader(bytes.NewReader(data))
+	case "deflate":
+		r, err = zlib.NewReader(bytes.NewReader(data))
+		if err != nil {
+			r, err = flate.NewReader(bytes.NewReader(data)), nil
+		}
+	default:
+		return nil, errUnsupportedEncoding
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	out, err := io.ReadAll(io.LimitReader(r, int64(limit)+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(out) > limit {
+		return nil, errBodyTooLarge
+	}
+	return out, nil
+}
@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func gzipped(t *testing.T, s string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := io.WriteString(w, s); err != nil {
+		t.Fatalf("failed to compress: %v", err)
+	}
+	w.Close()
+	return buf.Bytes()
+}
+
+func TestDecompressBodyEnforcesLimit(t *testing.T) {
+	app := fiber.New()
+	app.Use(DecompressBody(1024))
+	app.Post("/", func(c *fiber.Ctx) error {
+		return c.Send(c.Body())
+	})
+
+	for _, tc := range []struct {
+		body     []byte
+		encoding string
+		status   int
+	}{
+		{gzipped(t, "hello"), "gzip", 200},
+		{gzipped(t, strings.Repeat("a", 1025)), "gzip", 413},
+		{[]byte("not gzip"), "gzip", 400},
+		{[]byte("hello"), "br", 415},
+	} {
+		req := httptest.NewRequest("POST", "/", bytes.NewReader(tc.body))
+		req.Header.Set("Content-Encoding", tc.encoding)
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		if resp.StatusCode != tc.status {
+			t.Fatalf("%s body: expected %d, got %d", tc.encoding, tc.status, resp.StatusCode)
+		}
+		if tc.status == 200 {
+			if body, _ := io.ReadAll(resp.Body); string(body) != "hello" {
+				t.Fatalf("expected the decompressed body, got %q", body)
+			}
+		}
+	}
+}
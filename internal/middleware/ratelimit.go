@@ -0,0 +1,135 @@
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RateLimit configures a per-IP token bucket. PerMinute is the sustained
+// refill rate and Burst the bucket size. A PerMinute of zero disables limiting.
+type RateLimit struct {
+	PerMinute int
+	Burst     int
+}
+
+// RateLimitFromEnv reads <prefix>_PER_MINUTE and <prefix>_BURST, falling back
+// to def for unset or invalid values.
+func RateLimitFromEnv(prefix string, def RateLimit) RateLimit {
+	limit := def
+	if n, ok := envInt(prefix + "_PER_MINUTE"); ok {
+		limit.PerMinute = n
+	}
+	if n, ok := envInt(prefix + "_BURST"); ok {
+		limit.Burst = n
+	}
+	return limit
+}
+
+func envInt(name string) (int, bool) {
+	value := strings.TrimSpace(os.Getenv(name))
+	if value == "" {
+		return 0, false
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	return n, true
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// rateLimiter holds the per-IP buckets for one RateLimit.
+type rateLimiter struct {
+	limit RateLimit
+	now   func() time.Time
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+// take removes a token from key's bucket. When the bucket is empty it returns
+// false and how long until the next token is available.
+func (l *rateLimiter) take(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	rate := float64(l.limit.PerMinute) / 60 // tokens per second
+	capacity := float64(l.limit.Burst)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		if len(l.buckets) >= 10000 {
+			l.evictFull(now, rate, capacity)
+		}
+		b = &bucket{tokens: capacity, last: now}
+		l.buckets[key] = b
+	}
+
+	b.tokens = math.Min(capacity, b.tokens+now.Sub(b.last).Seconds()*rate)
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	wait := time.Duration((1 - b.tokens) / rate * float64(time.Second))
+	return false, wait
+}
+
+// evictFull drops buckets that have refilled completely; they behave the same
+// as a fresh bucket.
+func (l *rateLimiter) evictFull(now time.Time, rate, capacity float64) {
+	for key, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*rate >= capacity {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// RateLimiter returns a middleware that limits requests per client IP. Over the
+// limit it responds 429 with a Retry-After header. Requests to exemptPaths are
+// not counted.
+func RateLimiter(limit RateLimit, exemptPaths ...string) fiber.Handler {
+	if limit.PerMinute <= 0 {
+		return func(c *fiber.Ctx) error { return c.Next() }
+	}
+	if limit.Burst <= 0 {
+		limit.Burst = 1
+	}
+
+	exempt := make(map[string]bool, len(exemptPaths))
+	for _, path := range exemptPaths {
+		exempt[path] = true
+	}
+
+	limiter := &rateLimiter{limit: limit, now: time.Now, buckets: make(map[string]*bucket)}
+
+	return func(c *fiber.Ctx) error {
+		if exempt[c.Path()] || c.Method() == fiber.MethodOptions {
+			return c.Next()
+		}
+
+		ok, wait := limiter.take(c.IP())
+		if !ok {
+			c.Set(fiber.HeaderRetryAfter, fmt.Sprintf("%d", int(math.Ceil(wait.Seconds()))))
+			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+				"success": false,
+				"error":   "Rate limit exceeded, try again later",
+			})
+		}
+
+		return c.Next()
+	}
+}
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateLimiterRefillsAtConfiguredRate(t *testing.T) {
+	now := time.Unix(0, 0)
+	limiter := &rateLimiter{
+		limit:   RateLimit{PerMinute: 60, Burst: 2},
+		now:     func() time.Time { return now },
+		buckets: make(map[string]*bucket),
+	}
+
+	for i := 0; i < 2; i++ {
+		if ok, _ := limiter.take("1.2.3.4"); !ok {
+			t.Fatalf("request %d should fit in the burst", i+1)
+		}
+	}
+	ok, wait := limiter.take("1.2.3.4")
+	if ok || wait != time.Second {
+		t.Fatalf("expected third request to be limited for 1s, got ok=%v wait=%v", ok, wait)
+	}
+	if ok, _ := limiter.take("5.6.7.8"); !ok {
+		t.Fatalf("other clients should have their own bucket")
+	}
+
+	now = now.Add(time.Second)
+	if ok, _ := limiter.take("1.2.3.4"); !ok {
+		t.Fatalf("expected a token after one second")
+	}
+}
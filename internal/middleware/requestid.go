@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"regexp"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/omariomari2/uncluster/internal/logging"
+)
+
+// RequestIDHeader carries the request's correlation ID, in both directions.
+const RequestIDHeader = "X-Request-ID"
+
+// requestIDPattern accepts the IDs a proxy or client is likely to send while
+// keeping log lines free of arbitrary text.
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// RequestID gives every request a correlation ID: the incoming X-Request-ID
+// when it is well formed, a new UUID otherwise. The ID is echoed in the
+// response header, stored in Locals under "requestid" for the access log, and
+// attached to the user context, where logging.FromContext picks it up.
+func RequestID() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id := c.Get(RequestIDHeader)
+		if !requestIDPattern.MatchString(id) {
+			id = utils.UUIDv4()
+		}
+		c.Set(RequestIDHeader, id)
+		c.Locals("requestid", id)
+		c.SetUserContext(logging.WithRequestID(c.UserContext(), id))
+		return c.Next()
+	}
+}
@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/omariomari2/uncluster/internal/logging"
+)
+
+func TestRequestIDEchoesOrGeneratesID(t *testing.T) {
+	app := fiber.New()
+	app.Use(RequestID())
+	app.Get("/", func(c *fiber.Ctx) error {
+		return c.SendString(logging.RequestID(c.UserContext()))
+	})
+
+	for _, tc := range []struct {
+		incoming string
+		keep     bool
+	}{
+		{"abc-123.def_4", true},
+		{"", false},
+		{"has spaces", false},
+	} {
+		req := httptest.NewRequest("GET", "/", nil)
+		if tc.incoming != "" {
+			req.Header.Set(RequestIDHeader, tc.incoming)
+		}
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		id := resp.Header.Get(RequestIDHeader)
+		if id == "" || string(body) != id {
+			t.Fatalf("incoming %q: header %q and context ID %q should match and be set", tc.incoming, id, body)
+		}
+		if (id == tc.incoming) != tc.keep {
+			t.Fatalf("incoming %q: got ID %q, keep=%v", tc.incoming, id, tc.keep)
+		}
+	}
+}
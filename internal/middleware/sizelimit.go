@@ -0,0 +1,10 @@
+package middleware
+
+// SizeLimitFromEnv reads a byte limit from the named environment variable,
+// falling back to def when it is unset, invalid, or zero.
+func SizeLimitFromEnv(name string, def int) int {
+	if n, ok := envInt(name); ok && n > 0 {
+		return n
+	}
+	return def
+}
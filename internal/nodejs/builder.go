@@ -2,8 +2,10 @@ package nodejs
 
 import (
 	"fmt"
+	"github.com/omariomari2/uncluster/internal/converter"
+	"github.com/omariomari2/uncluster/internal/extractor"
 	"github.com/omariomari2/uncluster/internal/fetcher"
-	"log"
+	"log/slog"
 	"strings"
 	"text/template"
 )
@@ -11,19 +13,231 @@ import (
 type ProjectConfig struct {
 	ProjectName    string
 	PackageManager string
+	Language       string // "ts" (default) or "js"
+	Tailwind       *bool  // nil detects Tailwind usage from the HTML and CSS
+	ComponentName  string // main converted component; defaults to "MainComponent"
+	IncludeDocker  bool   // adds a Dockerfile and .dockerignore
 	HTML           string
 	CSS            string
 	JS             string
+	InlineCSS      []extractor.InlineResource
+	InlineJS       []extractor.InlineResource
 	ExternalCSS    []fetcher.FetchedResource
 	ExternalJS     []fetcher.FetchedResource
+	Head           extractor.HeadMetadata // source page metadata for src/index.html
+	Versions       DependencyVersions     // package.json versions; zero value uses the defaults
+	Minify         bool                   // minifies the CSS written to the project, and the JS in static and Svelte projects; React keeps the page's script in the component
+	Router         bool                   // adds react-router-dom with a route per major section when there are two or more
+	Layout         Layout                 // folders under src/; zero value is components, styles, and scripts
+	Logger         *slog.Logger           // logs generation progress; nil uses slog.Default()
 }
 
 type ProjectFiles struct {
 	Files map[string]string
 }
 
+// packageManagerVersions pins the version advertised to corepack through the
+// package.json "packageManager" field. npm ships with Node and needs no pin.
+var packageManagerVersions = map[string]string{
+	"npm":  "",
+	"yarn": "4.1.0",
+	"pnpm": "8.15.4",
+}
+
+// NormalizePackageManager defaults an empty package manager to npm and
+// rejects anything other than npm, yarn, or pnpm. Request handlers call it to
+// validate input up front; the generators apply it to their config.
+func NormalizePackageManager(pm string) (string, error) {
+	pm = strings.ToLower(strings.TrimSpace(pm))
+	if pm == "" {
+		return "npm", nil
+	}
+	if _, ok := packageManagerVersions[pm]; !ok {
+		return "", fmt.Errorf("unsupported package manager %q (expected npm, yarn, or pnpm)", pm)
+	}
+	return pm, nil
+}
+
+// normalizePackageManager replaces c.PackageManager with its
+// NormalizePackageManager form.
+func (c *ProjectConfig) normalizePackageManager() error {
+	pm, err := NormalizePackageManager(c.PackageManager)
+	if err != nil {
+		return err
+	}
+	c.PackageManager = pm
+	return nil
+}
+
+// normalizeLanguage defaults an empty language to TypeScript and rejects
+// anything other than "ts" or "js".
+func normalizeLanguage(language string) (string, error) {
+	language = strings.ToLower(strings.TrimSpace(language))
+	switch language {
+	case "":
+		return "ts", nil
+	case "ts", "js":
+		return language, nil
+	default:
+		return "", fmt.Errorf("unsupported language %q (expected ts or js)", language)
+	}
+}
+
+// logger returns config.Logger, or the default logger when it is unset.
+func (c *ProjectConfig) logger() *slog.Logger {
+	if c.Logger != nil {
+		return c.Logger
+	}
+	return slog.Default()
+}
+
+// IsTypeScript reports whether the project is generated as TypeScript.
+func (c *ProjectConfig) IsTypeScript() bool {
+	return c.Language != "js"
+}
+
+// SourceExt returns the React source file extension ("tsx" or "jsx").
+func (c *ProjectConfig) SourceExt() string {
+	if c.IsTypeScript() {
+		return "tsx"
+	}
+	return "jsx"
+}
+
+// PageTitle returns the <title> for src/index.html: the source page's title,
+// or the project name when it had none.
+func (c *ProjectConfig) PageTitle() string {
+	if c.Head.Title != "" {
+		return c.Head.Title
+	}
+	return c.ProjectName
+}
+
+// PageMeta returns the meta tags for src/index.html. A responsive viewport is
+// added when the source page did not set one.
+func (c *ProjectConfig) PageMeta() []extractor.MetaTag {
+	meta := c.Head.Meta
+	if !c.Head.Has("viewport") {
+		meta = append([]extractor.MetaTag{{Attr: "name", Key: "viewport", Content: "width=device-width, initial-scale=1.0"}}, meta...)
+	}
+	return meta
+}
+
+// PageLinks returns the icon and manifest links for src/index.html. Local
+// copies live in public/, which Vite serves from the site root, so their
+// href is made root-relative.
+func (c *ProjectConfig) PageLinks() []extractor.LinkTag {
+	links := make([]extractor.LinkTag, 0, len(c.Head.Links))
+	for _, link := range c.Head.Links {
+		if strings.HasPrefix(link.Href, "assets/") {
+			link.Href = "/" + link.Href
+		}
+		links = append(links, link)
+	}
+	return links
+}
+
+// RemoteStylesheets returns the external stylesheets that were not downloaded,
+// such as those kept at their CDN URL, which src/index.html links directly.
+func (c *ProjectConfig) RemoteStylesheets() []fetcher.FetchedResource {
+	return skippedResources(c.ExternalCSS)
+}
+
+// RemoteScripts returns the external scripts that were not downloaded, which
+// src/index.html loads from their original URL.
+func (c *ProjectConfig) RemoteScripts() []fetcher.FetchedResource {
+	return skippedResources(c.ExternalJS)
+}
+
+func skippedResources(resources []fetcher.FetchedResource) []fetcher.FetchedResource {
+	var skipped []fetcher.FetchedResource
+	for _, resource := range resources {
+		if resource.SkipReason != "" {
+			skipped = append(skipped, resource)
+		}
+	}
+	return skipped
+}
+
+// PackageManagerSpec returns the corepack "name@version" for the configured
+// package manager, or an empty string for npm.
+func (c *ProjectConfig) PackageManagerSpec() string {
+	if version := packageManagerVersions[c.PackageManager]; version != "" {
+		return c.PackageManager + "@" + version
+	}
+	return ""
+}
+
+// InstallCommand returns the dependency install command for the configured package manager.
+func (c *ProjectConfig) InstallCommand() string {
+	return c.PackageManager + " install"
+}
+
+// ProductionInstallCommand returns the install command that skips
+// devDependencies, used by the runtime stage of the Dockerfile.
+func (c *ProjectConfig) ProductionInstallCommand() string {
+	switch c.PackageManager {
+	case "yarn":
+		return "yarn workspaces focus --production"
+	case "pnpm":
+		return "pnpm install --prod"
+	}
+	return "npm install --omit=dev"
+}
+
+// DockerCmd returns the start command as a Dockerfile CMD exec-form array.
+func (c *ProjectConfig) DockerCmd() string {
+	return `["` + strings.Join(strings.Fields(c.RunCommand("serve")), `", "`) + `"]`
+}
+
+// RunCommand returns the command that runs a package.json script with the
+// configured package manager.
+func (c *ProjectConfig) RunCommand(script string) string {
+	if c.PackageManager == "npm" {
+		if script == "start" {
+			return "npm start"
+		}
+		return "npm run " + script
+	}
+	return c.PackageManager + " " + script
+}
+
 func GenerateProject(config *ProjectConfig) (*ProjectFiles, error) {
-	log.Printf("🏗️ Generating Node.js project: %s", config.ProjectName)
+	config.logger().Debug("generating Node.js project", "project", config.ProjectName)
+
+	if err := config.normalizePackageManager(); err != nil {
+		return nil, err
+	}
+
+	language, err := normalizeLanguage(config.Language)
+	if err != nil {
+		return nil, err
+	}
+	config.Language = language
+
+	versions, err := config.Versions.Normalize()
+	if err != nil {
+		return nil, err
+	}
+	config.Versions = versions
+
+	layout, err := config.Layout.Normalize()
+	if err != nil {
+		return nil, err
+	}
+	config.Layout = layout
+
+	config.ComponentName = strings.TrimSpace(config.ComponentName)
+	if config.ComponentName == "" {
+		config.ComponentName = converter.DefaultComponentName
+	} else if err := converter.ValidateComponentName(config.ComponentName); err != nil {
+		return nil, err
+	}
+
+	if config.Tailwind == nil {
+		detected := detectTailwind(config.HTML, config.CSS)
+		config.Tailwind = &detected
+	}
 
 	files := make(map[string]string)
 
@@ -35,10 +249,27 @@ func GenerateProject(config *ProjectConfig) (*ProjectFiles, error) {
 
 	files["vite.config.js"] = viteConfigTemplate
 	files["server.js"] = serverJSTemplate
-	files[".eslintrc.json"] = eslintConfigTemplate
 	files[".prettierrc"] = prettierConfigTemplate
-	files["tsconfig.json"] = tsconfigTemplate
-	files[".gitignore"] = gitignoreTemplate
+	if config.IsTypeScript() {
+		files[".eslintrc.json"] = eslintConfigTemplate
+		files["tsconfig.json"] = tsconfigTemplate
+	} else {
+		files[".eslintrc.json"] = eslintConfigJSTemplate
+	}
+	files[".gitignore"] = gitignoreTemplate + gitignoreExtras[config.PackageManager]
+	if config.UsesTailwind() {
+		config.logger().Debug("Tailwind detected, adding Tailwind configuration", "project", config.ProjectName)
+		files["tailwind.config.js"] = tailwindConfigTemplate
+		files["postcss.config.js"] = postcssConfigTemplate
+	}
+	if config.IncludeDocker {
+		dockerfile, err := executeProjectTemplate("Dockerfile", dockerfileTemplate, config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate Dockerfile: %w", err)
+		}
+		files["Dockerfile"] = dockerfile
+		files[".dockerignore"] = dockerignoreTemplate
+	}
 
 	readme, err := generateREADME(config)
 	if err != nil {
@@ -48,7 +279,7 @@ func GenerateProject(config *ProjectConfig) (*ProjectFiles, error) {
 
 	organizeSourceFiles(config, files)
 
-	log.Printf("✅ Generated %d files for Node.js project", len(files))
+	config.logger().Debug("generated Node.js project", "project", config.ProjectName, "files", len(files))
 
 	return &ProjectFiles{Files: files}, nil
 }
@@ -97,51 +328,81 @@ func generateIndexHTML(config *ProjectConfig) (string, error) {
 func organizeSourceFiles(config *ProjectConfig, files map[string]string) {
 	indexHTML, err := generateIndexHTML(config)
 	if err != nil {
-		log.Printf("⚠️ Failed to generate index.html: %v", err)
+		config.logger().Error("failed to generate index.html", "error", err)
 		indexHTML = indexHtmlTemplate
 	}
 	files["src/index.html"] = indexHTML
 
+	css := config.CSS
+	if config.UsesTailwind() && !strings.Contains(css, "@tailwind") {
+		css = tailwindDirectives + "\n" + css
+	}
+
+	components, err := converter.NewComponentLibrary(config.HTML, converter.Options{Language: config.Language}, maxSuggestedComponents, config.ComponentName)
+	if err != nil {
+		config.logger().Error("failed to analyze components", "error", err)
+	}
+
 	sectionFiles, mainComponent, mainTsx, err := generateTSXViews(
 		config.HTML,
-		config.CSS,
+		css,
 		config.ExternalCSS,
+		config.Language,
+		config.ComponentName,
+		config.Router,
+		config.Layout,
+		components,
+		config.logger(),
 	)
 	if err != nil {
-		log.Printf("⚠️ Failed to generate TSX views: %v", err)
+		config.logger().Error("failed to generate TSX views", "error", err)
 		mainComponent = fmt.Sprintf(`import React from 'react'
 
-function MainComponent() {
+function %[1]s() {
   return (
-    <div dangerouslySetInnerHTML={{__html: %q}} />
+    <div dangerouslySetInnerHTML={{__html: %[2]q}} />
   )
 }
 
-export default MainComponent
-`, config.HTML)
+export default %[1]s
+`, config.ComponentName, config.HTML)
 		mainTsx = mainTsxFallback
+		if !config.IsTypeScript() {
+			mainTsx = strings.Replace(mainTsx, "getElementById('root')!", "getElementById('root')", 1)
+		}
 	}
 
+	ext := config.SourceExt()
 	for filename, content := range sectionFiles {
 		files[filename] = content
 	}
-	files["src/components/MainComponent.tsx"] = mainComponent
-	files["src/App.tsx"] = appTsxTemplate
-	files["src/main.tsx"] = mainTsx
+	files[config.Layout.ComponentPath(config.ComponentName+"."+ext)] = mainComponent
+	files["src/main."+ext] = mainTsx
 
-	if config.CSS != "" {
-		files["src/styles/main.css"] = config.CSS
+	if components != nil {
+		for _, component := range components.Components {
+			files[config.Layout.ComponentPath(component.Filename)] = component.Code
+		}
+	}
+	files["src/App."+ext] = fmt.Sprintf(appTsxTemplate, config.ComponentName, srcImport(config.Layout.ComponentPath(config.ComponentName)))
+
+	if strings.TrimSpace(css) != "" {
+		files[config.Layout.StylePath("main.css")] = config.outputCSS(css)
 	}
 
 	for _, css := range config.ExternalCSS {
 		if css.Error == nil && css.Content != "" {
-			files["src/styles/external/"+css.Filename] = css.Content
+			files[config.Layout.StylePath("external/"+css.Filename)] = css.Content
 		}
 	}
 
 	for _, js := range config.ExternalJS {
 		if js.Error == nil && js.Content != "" {
-			files["src/scripts/external/"+js.Filename] = js.Content
+			files[config.Layout.ScriptPath("external/"+js.Filename)] = js.Content
 		}
 	}
 }
+
+// maxSuggestedComponents caps how many analyzer suggestions are written out as
+// standalone component files and rendered by the page's components.
+const maxSuggestedComponents = 8
@@ -0,0 +1,530 @@
+package nodejs
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/omariomari2/uncluster/internal/extractor"
+	"github.com/omariomari2/uncluster/internal/fetcher"
+)
+
+const testPageHTML = `<html><head></head><body>
+<header class="header"><h1>Site</h1></header>
+<section class="hero"><h2>Welcome</h2><p>Intro text</p></section>
+<footer class="footer"><p>Footer</p></footer>
+</body></html>`
+
+func TestGenerateProjectJavaScriptModeEmitsNoTypeScript(t *testing.T) {
+	project, err := GenerateProject(&ProjectConfig{
+		ProjectName: "js-project",
+		Language:    "js",
+		HTML:        testPageHTML,
+		CSS:         "body { margin: 0; }",
+	})
+	if err != nil {
+		t.Fatalf("GenerateProject returned error: %v", err)
+	}
+
+	for path := range project.Files {
+		if strings.HasSuffix(path, ".ts") || strings.HasSuffix(path, ".tsx") {
+			t.Fatalf("unexpected TypeScript file in js mode: %s", path)
+		}
+	}
+	if _, ok := project.Files["tsconfig.json"]; ok {
+		t.Fatalf("tsconfig.json should not be generated in js mode")
+	}
+	for _, path := range []string{"src/App.jsx", "src/main.jsx", "src/components/MainComponent.jsx"} {
+		if _, ok := project.Files[path]; !ok {
+			t.Fatalf("expected %s to be generated", path)
+		}
+	}
+	if strings.Contains(project.Files["src/main.jsx"], "!)") {
+		t.Fatalf("main.jsx should not contain a TypeScript non-null assertion:\n%s", project.Files["src/main.jsx"])
+	}
+	if !strings.Contains(project.Files["src/index.html"], `src="/main.jsx"`) {
+		t.Fatalf("index.html should load main.jsx:\n%s", project.Files["src/index.html"])
+	}
+
+	var pkg struct {
+		DevDependencies map[string]string `json:"devDependencies"`
+	}
+	if err := json.Unmarshal([]byte(project.Files["package.json"]), &pkg); err != nil {
+		t.Fatalf("package.json is not valid JSON: %v\n%s", err, project.Files["package.json"])
+	}
+	for dep := range pkg.DevDependencies {
+		if dep == "typescript" || strings.HasPrefix(dep, "@typescript-eslint/") || strings.HasPrefix(dep, "@types/") {
+			t.Fatalf("unexpected TypeScript dependency in js mode: %s", dep)
+		}
+	}
+}
+
+func TestGenerateProjectDefaultsToTypeScript(t *testing.T) {
+	project, err := GenerateProject(&ProjectConfig{
+		ProjectName: "ts-project",
+		HTML:        testPageHTML,
+	})
+	if err != nil {
+		t.Fatalf("GenerateProject returned error: %v", err)
+	}
+
+	for _, path := range []string{"tsconfig.json", "src/App.tsx", "src/main.tsx", "src/components/MainComponent.tsx"} {
+		if _, ok := project.Files[path]; !ok {
+			t.Fatalf("expected %s to be generated", path)
+		}
+	}
+	if !json.Valid([]byte(project.Files["package.json"])) {
+		t.Fatalf("package.json is not valid JSON:\n%s", project.Files["package.json"])
+	}
+}
+
+func TestGenerateProjectWritesSuggestedComponents(t *testing.T) {
+	page := `<!doctype html><html><head><title>Cards</title></head><body>
+<main>
+<figure class="card"><img src="a.png" alt="a"><figcaption>A</figcaption></figure>
+<figure class="card"><img src="b.png" alt="b"><figcaption>B</figcaption></figure>
+<figure class="card"><img src="c.png" alt="c"><figcaption>C</figcaption></figure>
+</main>
+</body></html>`
+
+	files, err := GenerateProject(&ProjectConfig{ProjectName: "cards", HTML: page})
+	if err != nil {
+		t.Fatalf("GenerateProject returned error: %v", err)
+	}
+
+	card, ok := files.Files["src/components/FigureCard.tsx"]
+	if !ok {
+		t.Fatalf("expected src/components/FigureCard.tsx, got %d files", len(files.Files))
+	}
+	if !strings.Contains(card, "const FigureCard = ({ className, children }: FigureCardProps) =>") {
+		t.Fatalf("expected typed FigureCard component, got:\n%s", card)
+	}
+
+	var rendered string
+	for path, content := range files.Files {
+		if strings.HasPrefix(path, "src/components/") && path != "src/components/FigureCard.tsx" && strings.Contains(content, "<FigureCard") {
+			rendered = content
+		}
+	}
+	if !strings.Contains(rendered, "import FigureCard from './FigureCard'") || strings.Contains(rendered, "<figure") {
+		t.Fatalf("expected a page component to import FigureCard and render it in place of the cards, got:\n%s", rendered)
+	}
+	if !strings.Contains(rendered, `<FigureCard className="card"><img src="a.png" alt="a" /><figcaption>A</figcaption></FigureCard>`) {
+		t.Fatalf("expected each card's content passed as children, got:\n%s", rendered)
+	}
+}
+
+func TestGenerateProjectScaffoldsTailwindWhenUtilitiesArePresent(t *testing.T) {
+	page := `<!doctype html><html><head><title>TW</title></head><body>
+<div class="flex items-center justify-between px-4 py-2 bg-slate-800 text-white md:px-8">
+<h1 class="text-2xl font-bold">Hello</h1>
+</div>
+</body></html>`
+
+	files, err := GenerateProject(&ProjectConfig{ProjectName: "tw", HTML: page})
+	if err != nil {
+		t.Fatalf("GenerateProject returned error: %v", err)
+	}
+
+	for _, name := range []string{"tailwind.config.js", "postcss.config.js"} {
+		if _, ok := files.Files[name]; !ok {
+			t.Fatalf("expected %s to be generated", name)
+		}
+	}
+	if !strings.HasPrefix(files.Files["src/styles/main.css"], "@tailwind base;") {
+		t.Fatalf("expected Tailwind directives in main.css, got:\n%s", files.Files["src/styles/main.css"])
+	}
+	if !strings.Contains(files.Files["src/main.tsx"], "import './styles/main.css'") {
+		t.Fatalf("expected main.tsx to import main.css, got:\n%s", files.Files["src/main.tsx"])
+	}
+
+	var pkg struct {
+		DevDependencies map[string]string `json:"devDependencies"`
+	}
+	if err := json.Unmarshal([]byte(files.Files["package.json"]), &pkg); err != nil {
+		t.Fatalf("package.json is not valid JSON: %v", err)
+	}
+	for _, dep := range []string{"tailwindcss", "postcss", "autoprefixer"} {
+		if pkg.DevDependencies[dep] == "" {
+			t.Fatalf("expected %s in devDependencies, got %v", dep, pkg.DevDependencies)
+		}
+	}
+}
+
+func TestGenerateProjectTailwindOverride(t *testing.T) {
+	disabled := false
+	files, err := GenerateProject(&ProjectConfig{
+		ProjectName: "tw",
+		HTML:        `<div class="flex px-4 py-2 text-sm">hi</div>`,
+		Tailwind:    &disabled,
+	})
+	if err != nil {
+		t.Fatalf("GenerateProject returned error: %v", err)
+	}
+	if _, ok := files.Files["tailwind.config.js"]; ok {
+		t.Fatalf("expected no Tailwind config when explicitly disabled")
+	}
+
+	files, err = GenerateProject(&ProjectConfig{ProjectName: "plain", HTML: testPageHTML})
+	if err != nil {
+		t.Fatalf("GenerateProject returned error: %v", err)
+	}
+	if _, ok := files.Files["tailwind.config.js"]; ok {
+		t.Fatalf("expected no Tailwind config for a page without utility classes")
+	}
+}
+
+func TestGenerateProjectUsesCustomComponentName(t *testing.T) {
+	project, err := GenerateProject(&ProjectConfig{
+		ProjectName:   "named",
+		ComponentName: "LandingPage",
+		HTML:          testPageHTML,
+	})
+	if err != nil {
+		t.Fatalf("GenerateProject returned error: %v", err)
+	}
+
+	component, ok := project.Files["src/components/LandingPage.tsx"]
+	if !ok {
+		t.Fatalf("expected src/components/LandingPage.tsx to be generated")
+	}
+	if !strings.Contains(component, "function LandingPage()") || !strings.Contains(component, "export default LandingPage") {
+		t.Fatalf("unexpected main component:\n%s", component)
+	}
+	if _, ok := project.Files["src/components/MainComponent.tsx"]; ok {
+		t.Fatalf("MainComponent.tsx should not be generated when a name is given")
+	}
+	if !strings.Contains(project.Files["src/App.tsx"], "<LandingPage />") {
+		t.Fatalf("App.tsx should render LandingPage:\n%s", project.Files["src/App.tsx"])
+	}
+
+	if _, err := GenerateProject(&ProjectConfig{ComponentName: "landing-page", HTML: testPageHTML}); err == nil || !strings.Contains(err.Error(), "invalid componentName") {
+		t.Fatalf("expected invalid componentName error, got %v", err)
+	}
+}
+
+func TestGenerateProjectIncludeDocker(t *testing.T) {
+	project, err := GenerateProject(&ProjectConfig{ProjectName: "plain", HTML: testPageHTML})
+	if err != nil {
+		t.Fatalf("GenerateProject returned error: %v", err)
+	}
+	if _, ok := project.Files["Dockerfile"]; ok {
+		t.Fatalf("Dockerfile should only be generated when IncludeDocker is set")
+	}
+
+	project, err = GenerateProject(&ProjectConfig{
+		ProjectName:    "docker",
+		PackageManager: "pnpm",
+		IncludeDocker:  true,
+		HTML:           testPageHTML,
+	})
+	if err != nil {
+		t.Fatalf("GenerateProject returned error: %v", err)
+	}
+
+	dockerfile := project.Files["Dockerfile"]
+	for _, want := range []string{
+		"RUN corepack enable",
+		"RUN pnpm install --prod",
+		"COPY --from=build /app/dist ./dist",
+		`CMD ["pnpm", "serve"]`,
+	} {
+		if !strings.Contains(dockerfile, want) {
+			t.Fatalf("expected %q in Dockerfile, got:\n%s", want, dockerfile)
+		}
+	}
+	if !strings.Contains(project.Files[".dockerignore"], "node_modules") {
+		t.Fatalf("expected .dockerignore to exclude node_modules:\n%s", project.Files[".dockerignore"])
+	}
+	if !strings.Contains(project.Files["README.md"], "docker build -t docker .") {
+		t.Fatalf("expected a Docker section in the README")
+	}
+}
+
+func TestNormalizePackageManager(t *testing.T) {
+	cases := map[string]string{"": "npm", " Yarn ": "yarn", "pnpm": "pnpm"}
+	for input, want := range cases {
+		if got, err := NormalizePackageManager(input); err != nil || got != want {
+			t.Fatalf("NormalizePackageManager(%q) = %q, %v; want %q", input, got, err, want)
+		}
+	}
+	if _, err := NormalizePackageManager("bun"); err == nil {
+		t.Fatalf("expected an error for an unsupported package manager")
+	}
+	if _, err := GenerateStaticProject(&ProjectConfig{ProjectName: "bun", PackageManager: "bun", HTML: testPageHTML}); err == nil {
+		t.Fatalf("expected GenerateStaticProject to reject an unsupported package manager")
+	}
+}
+
+func TestGenerateProjectCarriesHeadMetadata(t *testing.T) {
+	extracted, err := extractor.Extract(`<!doctype html><html><head>
+<meta charset="iso-8859-1">
+<meta http-equiv="Content-Type" content="text/html; charset=iso-8859-1">
+<meta name="viewport" content="width=device-width">
+<title>Acme &amp; Co</title>
+<meta name="description" content="Rockets &quot;and&quot; anvils">
+<meta property="og:title" content="Acme">
+</head><body><p>Hi</p></body></html>`)
+	if err != nil {
+		t.Fatalf("Extract returned error: %v", err)
+	}
+
+	project, err := GenerateProject(&ProjectConfig{ProjectName: "acme", HTML: extracted.HTML, Head: extracted.Head})
+	if err != nil {
+		t.Fatalf("GenerateProject returned error: %v", err)
+	}
+	index := project.Files["src/index.html"]
+	for _, want := range []string{
+		`<meta charset="UTF-8" />`,
+		`<title>Acme &amp; Co</title>`,
+		`<meta name="description" content="Rockets &#34;and&#34; anvils" />`,
+		`<meta property="og:title" content="Acme" />`,
+		`<meta name="viewport" content="width=device-width" />`,
+	} {
+		if !strings.Contains(index, want) {
+			t.Fatalf("expected %s in index.html:\n%s", want, index)
+		}
+	}
+	if strings.Contains(strings.ToLower(index), "iso-8859-1") {
+		t.Fatalf("expected the source charset to be dropped:\n%s", index)
+	}
+	if strings.Count(index, `name="viewport"`) != 1 {
+		t.Fatalf("expected a single viewport tag:\n%s", index)
+	}
+
+	plain, err := GenerateProject(&ProjectConfig{ProjectName: "plain", HTML: testPageHTML})
+	if err != nil {
+		t.Fatalf("GenerateProject returned error: %v", err)
+	}
+	for _, want := range []string{`<meta charset="UTF-8" />`, `<meta name="viewport" content="width=device-width, initial-scale=1.0" />`, `<title>plain</title>`} {
+		if !strings.Contains(plain.Files["src/index.html"], want) {
+			t.Fatalf("expected default %s in index.html:\n%s", want, plain.Files["src/index.html"])
+		}
+	}
+}
+
+func TestGenerateProjectLinksIconsAndManifest(t *testing.T) {
+	project, err := GenerateProject(&ProjectConfig{ProjectName: "acme", HTML: testPageHTML, Head: extractor.HeadMetadata{Links: []extractor.LinkTag{
+		{Rel: "icon", Href: "assets/favicon.png", Type: "image/png", Sizes: "32x32"},
+		{Rel: "manifest", Href: "https://cdn.example.com/site.webmanifest"},
+	}}})
+	if err != nil {
+		t.Fatalf("GenerateProject returned error: %v", err)
+	}
+	index := project.Files["src/index.html"]
+	for _, want := range []string{
+		`<link rel="icon" href="/assets/favicon.png" type="image/png" sizes="32x32" />`,
+		`<link rel="manifest" href="https://cdn.example.com/site.webmanifest" />`,
+	} {
+		if !strings.Contains(index, want) {
+			t.Fatalf("expected %s in index.html:\n%s", want, index)
+		}
+	}
+}
+
+func TestGenerateProjectDependencyVersions(t *testing.T) {
+	project, err := GenerateProject(&ProjectConfig{
+		ProjectName: "react-19",
+		HTML:        testPageHTML,
+		Versions:    DependencyVersions{React: "^19.0.0", Vite: "6.0.1"},
+	})
+	if err != nil {
+		t.Fatalf("GenerateProject returned error: %v", err)
+	}
+
+	var pkg struct {
+		Dependencies    map[string]string `json:"dependencies"`
+		DevDependencies map[string]string `json:"devDependencies"`
+	}
+	if err := json.Unmarshal([]byte(project.Files["package.json"]), &pkg); err != nil {
+		t.Fatalf("package.json is not valid JSON: %v\n%s", err, project.Files["package.json"])
+	}
+	for dep, want := range map[string]string{"react": "^19.0.0", "react-dom": "^19.0.0"} {
+		if pkg.Dependencies[dep] != want {
+			t.Fatalf("expected %s %s, got %q", dep, want, pkg.Dependencies[dep])
+		}
+	}
+	for dep, want := range map[string]string{"@types/react": "^19.0.0", "vite": "6.0.1", "typescript": "^5.3.0"} {
+		if pkg.DevDependencies[dep] != want {
+			t.Fatalf("expected %s %s, got %q", dep, want, pkg.DevDependencies[dep])
+		}
+	}
+	if !strings.Contains(project.Files["README.md"], "**React 19**") {
+		t.Fatalf("expected README to mention React 19:\n%s", project.Files["README.md"])
+	}
+
+	for _, version := range []string{"latest", "19.x", "^19.0.0 || ^18"} {
+		_, err := GenerateProject(&ProjectConfig{ProjectName: "bad", HTML: testPageHTML, Versions: DependencyVersions{React: version}})
+		if err == nil {
+			t.Fatalf("expected React version %q to be rejected", version)
+		}
+	}
+}
+
+func TestGenerateProjectMinify(t *testing.T) {
+	css := "/* theme */\nbody {\n  margin: 0;\n}\n"
+	for _, minify := range []bool{false, true} {
+		project, err := GenerateProject(&ProjectConfig{ProjectName: "minify", HTML: testPageHTML, CSS: css, Minify: minify})
+		if err != nil {
+			t.Fatalf("GenerateProject returned error: %v", err)
+		}
+		mainCSS := project.Files["src/styles/main.css"]
+		if minify && mainCSS != "body{margin: 0;}" {
+			t.Fatalf("expected minified main.css, got %q", mainCSS)
+		}
+		if !minify && mainCSS != css {
+			t.Fatalf("expected main.css unchanged without Minify, got %q", mainCSS)
+		}
+	}
+
+	static, err := GenerateStaticProject(&ProjectConfig{
+		ProjectName: "minify-static",
+		HTML:        testPageHTML,
+		InlineJS:    []extractor.InlineResource{{Path: "inline/script-1.js", Content: "// boot\nstart()\n"}},
+		Minify:      true,
+	})
+	if err != nil {
+		t.Fatalf("GenerateStaticProject returned error: %v", err)
+	}
+	if got := static.Files["js/script-1.js"]; got != "start()" {
+		t.Fatalf("expected comment to be stripped from js/script-1.js, got %q", got)
+	}
+}
+
+func TestGenerateProjectRouter(t *testing.T) {
+	project, err := GenerateProject(&ProjectConfig{
+		ProjectName: "routed",
+		HTML:        testPageHTML,
+		Router:      true,
+		Versions:    DependencyVersions{ReactRouter: "^7.1.0"},
+	})
+	if err != nil {
+		t.Fatalf("GenerateProject returned error: %v", err)
+	}
+
+	var pkg struct {
+		Dependencies map[string]string `json:"dependencies"`
+	}
+	if err := json.Unmarshal([]byte(project.Files["package.json"]), &pkg); err != nil {
+		t.Fatalf("package.json is not valid JSON: %v", err)
+	}
+	if pkg.Dependencies["react-router-dom"] != "^7.1.0" {
+		t.Fatalf("expected react-router-dom ^7.1.0 in dependencies, got %v", pkg.Dependencies)
+	}
+
+	mainTsx := project.Files["src/main.tsx"]
+	for _, want := range []string{
+		"<BrowserRouter>",
+		`<Route path="/" element={<App />} />`,
+		`<Route path="/section-hero" element={<SectionHero />} />`,
+		`<Route path="/footer-footer" element={<FooterFooter />} />`,
+		`<NavLink to="/section-hero">SectionHero</NavLink>`,
+		"import SectionHero from './components/SectionHero'",
+	} {
+		if !strings.Contains(mainTsx, want) {
+			t.Fatalf("expected main.tsx to contain %q, got:\n%s", want, mainTsx)
+		}
+	}
+
+	plain, err := GenerateProject(&ProjectConfig{ProjectName: "plain", HTML: testPageHTML})
+	if err != nil {
+		t.Fatalf("GenerateProject returned error: %v", err)
+	}
+	if strings.Contains(plain.Files["package.json"], "react-router-dom") || strings.Contains(plain.Files["src/main.tsx"], "BrowserRouter") {
+		t.Fatalf("expected no router without the Router flag")
+	}
+}
+
+func TestGenerateProjectLayout(t *testing.T) {
+	project, err := GenerateProject(&ProjectConfig{
+		ProjectName: "laid-out",
+		HTML:        testPageHTML,
+		CSS:         "body { margin: 0; }",
+		ExternalCSS: []fetcher.FetchedResource{{URL: "https://cdn.example/site.css", Filename: "site.css", Content: "p { color: red; }"}},
+		ExternalJS:  []fetcher.FetchedResource{{URL: "https://cdn.example/app.js", Filename: "app.js", Content: "start()"}},
+		Router:      true,
+		Layout:      Layout{Components: "features/home/components", Styles: "assets/styles", Scripts: "assets/scripts"},
+	})
+	if err != nil {
+		t.Fatalf("GenerateProject returned error: %v", err)
+	}
+
+	for _, path := range []string{
+		"src/features/home/components/MainComponent.tsx",
+		"src/features/home/components/SectionHero.tsx",
+		"src/assets/styles/main.css",
+		"src/assets/styles/external/site.css",
+		"src/assets/scripts/external/app.js",
+	} {
+		if _, ok := project.Files[path]; !ok {
+			t.Fatalf("expected %s to be generated", path)
+		}
+	}
+	for path := range project.Files {
+		if strings.HasPrefix(path, "src/components/") || strings.HasPrefix(path, "src/styles/") || strings.HasPrefix(path, "src/scripts/") {
+			t.Fatalf("expected no files in the default folders, got %s", path)
+		}
+	}
+
+	mainTsx := project.Files["src/main.tsx"]
+	for _, want := range []string{
+		"import './assets/styles/main.css'",
+		"import './assets/styles/external/site.css'",
+		"import SectionHero from './features/home/components/SectionHero'",
+	} {
+		if !strings.Contains(mainTsx, want) {
+			t.Fatalf("expected main.tsx to contain %q, got:\n%s", want, mainTsx)
+		}
+	}
+	if app := project.Files["src/App.tsx"]; !strings.Contains(app, "import MainComponent from './features/home/components/MainComponent'") {
+		t.Fatalf("expected App.tsx to import from the components folder, got:\n%s", app)
+	}
+
+	if _, err := GenerateProject(&ProjectConfig{ProjectName: "bad", HTML: testPageHTML, Layout: Layout{Styles: "../styles"}}); err == nil {
+		t.Fatalf("expected a folder outside src/ to be rejected")
+	}
+}
+
+func TestGenerateProjectLinksResourcesKeptExternal(t *testing.T) {
+	project, err := GenerateProject(&ProjectConfig{
+		ProjectName: "cdn",
+		HTML:        testPageHTML,
+		ExternalCSS: []fetcher.FetchedResource{{URL: "https://cdn.example/site.css", Type: "css", SkipReason: "kept external"}},
+		ExternalJS:  []fetcher.FetchedResource{{URL: "https://cdn.example/app.js", Type: "js", SkipReason: "kept external", Async: true}},
+	})
+	if err != nil {
+		t.Fatalf("GenerateProject returned error: %v", err)
+	}
+
+	index := project.Files["src/index.html"]
+	if !strings.Contains(index, `<link rel="stylesheet" href="https://cdn.example/site.css" />`) ||
+		!strings.Contains(index, `<script async src="https://cdn.example/app.js"></script>`) {
+		t.Fatalf("expected index.html to load the CDN resources, got:\n%s", index)
+	}
+	if strings.Contains(project.Files["src/main.tsx"], "external") {
+		t.Fatalf("expected no local imports for resources kept external, got:\n%s", project.Files["src/main.tsx"])
+	}
+}
+
+// The React split shares selectComponentRoot with the EJS partials, so a
+// single div only counts as a page wrapper with a hint such as page-wrapper.
+// Sections below a plain div are still found, one level deeper.
+func TestGenerateTSXViewsRootDetection(t *testing.T) {
+	content := `<header class="top"><a href="/">Home</a></header><section class="hero"><h1>Hello</h1></section><footer class="bottom"><p>Bye</p></footer>`
+	for _, wrapper := range []string{`<div class="page-wrapper">`, `<div class="card">`, `<div>`} {
+		page := "<html><body>" + wrapper + content + "</div></body></html>"
+		sections, mainComponent, _, err := generateTSXViews(page, "", nil, "ts", "MainComponent", false, defaultLayout, nil, nil)
+		if err != nil {
+			t.Fatalf("%s: generateTSXViews returned error: %v", wrapper, err)
+		}
+		var names []string
+		for path := range sections {
+			names = append(names, path)
+		}
+		sort.Strings(names)
+		if len(names) != 3 || !strings.Contains(mainComponent, "<SectionHero />") {
+			t.Fatalf("%s: expected the header, hero, and footer as sections, got %v and:\n%s", wrapper, names, mainComponent)
+		}
+	}
+}
@@ -3,9 +3,11 @@ package nodejs
 import (
 	"bytes"
 	"fmt"
+	"github.com/omariomari2/uncluster/internal/analyzer"
 	"github.com/omariomari2/uncluster/internal/extractor"
 	"github.com/omariomari2/uncluster/internal/fetcher"
 	"github.com/omariomari2/uncluster/internal/formatter"
+	"github.com/omariomari2/uncluster/internal/htmlparse"
 	"sort"
 	"strings"
 	"text/template"
@@ -20,6 +22,46 @@ type EJSProjectConfig struct {
 	InlineJS    []extractor.InlineResource
 	ExternalCSS []fetcher.FetchedResource
 	ExternalJS  []fetcher.FetchedResource
+	Extraction  EJSExtractionOptions
+}
+
+// EJSExtractionOptions tunes how the page is split into partials. Zero values
+// keep the defaults, which suit Webflow-style markup.
+type EJSExtractionOptions struct {
+	// MaxDepth is how many levels below the content root are searched for
+	// sections. Defaults to 5.
+	MaxDepth int
+	// ExtraKeywords are class or id values that mark an element as a section,
+	// in addition to navbar, nav, header, footer, hero, and section.
+	ExtraKeywords []string
+	// MinTextLength is the minimum rendered size in bytes of a section before
+	// it becomes a partial. Defaults to 500.
+	MinTextLength int
+	// Exclude lists elements that never become partials. The zero value
+	// excludes analyzer.DefaultExclusions.
+	Exclude analyzer.Exclusions
+	// RootDepth is how many single-child wrappers below the body are
+	// descended through to find the content root (see isWrapperElement).
+	// Defaults to 4; a negative value keeps the body as the root.
+	RootDepth int
+}
+
+const (
+	defaultSectionDepth = 5
+	defaultRootDepth    = 4
+)
+
+func (o EJSExtractionOptions) withDefaults() EJSExtractionOptions {
+	if o.MaxDepth <= 0 {
+		o.MaxDepth = defaultSectionDepth
+	}
+	if o.MinTextLength <= 0 {
+		o.MinTextLength = minPartialBytes
+	}
+	if o.RootDepth == 0 {
+		o.RootDepth = defaultRootDepth
+	}
+	return o
 }
 
 type ejsComponent struct {
@@ -45,7 +87,7 @@ func GenerateEJSProject(config *EJSProjectConfig) (*ProjectFiles, error) {
 	}
 	files["README.md"] = readme
 
-	indexHTML, partials, err := generateEJSViews(config.HTML)
+	indexHTML, partials, err := generateEJSViews(config.HTML, config.Extraction)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate views: %w", err)
 	}
@@ -68,14 +110,14 @@ func GenerateEJSProject(config *EJSProjectConfig) (*ProjectFiles, error) {
 	}
 
 	for _, css := range config.ExternalCSS {
-		if css.Error == nil && strings.TrimSpace(css.Content) != "" {
-			files["public/external/css/"+css.Filename] = css.Content
+		if content, ok := externalFileContent(css); ok {
+			files["public/external/css/"+css.Filename] = content
 		}
 	}
 
 	for _, js := range config.ExternalJS {
-		if js.Error == nil && strings.TrimSpace(js.Content) != "" {
-			files["public/external/js/"+js.Filename] = js.Content
+		if content, ok := externalFileContent(js); ok {
+			files["public/external/js/"+js.Filename] = content
 		}
 	}
 
@@ -111,32 +153,106 @@ func generateEJSReadme(config *EJSProjectConfig) (string, error) {
 const minPartialBytes = 500
 const minPartialLines = 15
 
-func isPartialWorthExtracting(html string) bool {
-	return len(html) >= minPartialBytes && strings.Count(html, "\n") >= minPartialLines
+// normalizeComponentContent reduces rendered component HTML to a key that
+// ignores state classes and whitespace, so sections that only differ in which
+// link is active share a partial.
+func normalizeComponentContent(content string) string {
+	content = classAttrPattern.ReplaceAllStringFunc(content, func(attr string) string {
+		var kept []string
+		for _, class := range strings.Fields(classAttrPattern.FindStringSubmatch(attr)[1]) {
+			if !isStateClass(class) {
+				kept = append(kept, class)
+			}
+		}
+		return `class="` + strings.Join(kept, " ") + `"`
+	})
+	return strings.Join(strings.Fields(content), " ")
 }
 
-func generateEJSViews(htmlContent string) (string, map[string]string, error) {
-	doc, err := html.Parse(strings.NewReader(htmlContent))
+// stateClasses are classes toggled at runtime or per page rather than part of
+// a component's structure.
+var stateClasses = map[string]bool{
+	"active":     true,
+	"current":    true,
+	"selected":   true,
+	"open":       true,
+	"show":       true,
+	"w--current": true,
+	"w--open":    true,
+	"w--active":  true,
+}
+
+func isStateClass(class string) bool {
+	class = strings.ToLower(class)
+	return stateClasses[class] || strings.HasPrefix(class, "is-")
+}
+
+func isPartialWorthExtracting(html string, minBytes int) bool {
+	return len(html) >= minBytes && strings.Count(html, "\n") >= minPartialLines
+}
+
+func generateEJSViews(htmlContent string, opts EJSExtractionOptions) (string, map[string]string, error) {
+	opts = opts.withDefaults()
+
+	doc, err := htmlparse.Parse(htmlContent)
 	if err != nil {
 		return "", nil, err
 	}
 
+	// A fragment has no body; its nodes sit directly under the document, as
+	// do a frameset document's.
 	body := findElement(doc, "body")
 	if body == nil {
-		return htmlContent, map[string]string{}, nil
+		body = doc
 	}
 
-	root := selectComponentRoot(body)
-	components := collectBodyComponents(root)
+	root := selectComponentRoot(body, opts.RootDepth)
+	usedNames := make(map[string]int)
+	nameByContent := make(map[string]string)
+	components := extractPartials(collectBodyComponents(root, opts), opts, usedNames, nameByContent)
+
+	// Wrapper detection, or selectComponentNodes looking below a single
+	// child, can descend into the page's only section, whose children are
+	// then too small to be partials. Fall back to the content children of the
+	// body (or of a fragment's root) themselves.
+	if len(components) == 0 {
+		components = extractPartials(contentComponents(body, opts), opts, usedNames, nameByContent)
+	}
 
 	if len(components) == 0 {
 		return htmlContent, map[string]string{}, nil
 	}
 
-	usedNames := make(map[string]int)
-	nameByContent := make(map[string]string)
-	var resolved []ejsComponent
+	var buf bytes.Buffer
+	if err := html.Render(&buf, doc); err != nil {
+		return "", nil, err
+	}
+
+	rendered := buf.String()
+	if formatted, err := formatter.Format(rendered); err == nil {
+		rendered = formatted
+	}
+
+	indexReplacements := buildIncludeReplacements(components, "partials/")
+	partialReplacements := buildIncludeReplacements(components, "")
+	rendered = applyIncludeReplacements(rendered, indexReplacements)
+
+	partials := make(map[string]string, len(components))
+	for _, component := range components {
+		if _, exists := partials[component.Name]; exists {
+			continue
+		}
+		partials[component.Name] = applyIncludeReplacements(component.HTML, partialReplacements)
+	}
 
+	return rendered, partials, nil
+}
+
+// extractPartials names the components worth a partial and replaces each
+// one's node with an include marker. Components with the same content, up to
+// state classes, share a name.
+func extractPartials(components []ejsComponent, opts EJSExtractionOptions, usedNames map[string]int, nameByContent map[string]string) []ejsComponent {
+	var resolved []ejsComponent
 	for idx, component := range components {
 		content, err := renderNodeHTML(component.Node)
 		if err != nil {
@@ -147,14 +263,15 @@ func generateEJSViews(htmlContent string) (string, map[string]string, error) {
 			continue
 		}
 
-		if !isPartialWorthExtracting(trimmed) {
+		if !isPartialWorthExtracting(trimmed, opts.MinTextLength) {
 			continue
 		}
 
-		name, ok := nameByContent[trimmed]
+		key := normalizeComponentContent(trimmed)
+		name, ok := nameByContent[key]
 		if !ok {
 			name = buildComponentName(component.Node, idx, usedNames)
-			nameByContent[trimmed] = name
+			nameByContent[key] = name
 		}
 
 		resolved = append(resolved, ejsComponent{
@@ -165,36 +282,21 @@ func generateEJSViews(htmlContent string) (string, map[string]string, error) {
 
 		replaceNodeWithIncludeMarker(component.Node, name)
 	}
+	return resolved
+}
 
-	components = resolved
-
-	var buf bytes.Buffer
-	if err := html.Render(&buf, doc); err != nil {
-		return "", nil, err
-	}
-
-	rendered := buf.String()
-	if formatted, err := formatter.Format(rendered); err == nil {
-		rendered = formatted
-	}
-
-	indexReplacements := buildIncludeReplacements(components, "partials/")
-	partialReplacements := buildIncludeReplacements(components, "")
-	rendered = applyIncludeReplacements(rendered, indexReplacements)
-
-	partials := make(map[string]string, len(components))
-	for _, component := range components {
-		if _, exists := partials[component.Name]; exists {
-			continue
-		}
-		partials[component.Name] = applyIncludeReplacements(component.HTML, partialReplacements)
+// contentComponents returns the component candidates among n's content
+// children.
+func contentComponents(n *html.Node, opts EJSExtractionOptions) []ejsComponent {
+	var components []ejsComponent
+	for _, child := range filterComponentCandidates(contentChildren(n), opts.Exclude) {
+		components = append(components, ejsComponent{Node: child})
 	}
-
-	return rendered, partials, nil
+	return components
 }
 
-func collectBodyComponents(root *html.Node) []ejsComponent {
-	nodes := selectComponentNodes(root)
+func collectBodyComponents(root *html.Node, opts EJSExtractionOptions) []ejsComponent {
+	nodes := selectComponentNodes(root, opts)
 	if len(nodes) == 0 {
 		return nil
 	}
@@ -205,7 +307,7 @@ func collectBodyComponents(root *html.Node) []ejsComponent {
 
 	var components []ejsComponent
 	for _, child := range nodes {
-		if !isComponentCandidate(child) {
+		if !isComponentCandidate(child, opts.Exclude) {
 			continue
 		}
 		components = append(components, ejsComponent{
@@ -218,9 +320,14 @@ func collectBodyComponents(root *html.Node) []ejsComponent {
 	return components
 }
 
-func selectComponentRoot(body *html.Node) *html.Node {
+// selectComponentRoot descends from body through up to maxDepth elements that
+// are the only content child of their parent and look like page wrappers. It
+// picks the root of both the EJS partials and the React project's section
+// components. Sections inside a plain div it stops at are still found, one
+// level deeper.
+func selectComponentRoot(body *html.Node, maxDepth int) *html.Node {
 	root := body
-	for depth := 0; depth < 4; depth++ {
+	for depth := 0; depth < maxDepth; depth++ {
 		children := contentChildren(root)
 		if len(children) != 1 {
 			break
@@ -235,11 +342,11 @@ func selectComponentRoot(body *html.Node) *html.Node {
 	return root
 }
 
-func isComponentCandidate(n *html.Node) bool {
+func isComponentCandidate(n *html.Node, exclude analyzer.Exclusions) bool {
 	if n.Type != html.ElementNode {
 		return false
 	}
-	if isNonContentElement(n) || isEmbedOnlyNode(n) {
+	if htmlparse.IsNonContent(n) || isEmbedOnlyNode(n) || exclude.Matches(n) {
 		return false
 	}
 	if getAttributeValue(n, "data-component") != "" {
@@ -253,41 +360,62 @@ func isComponentCandidate(n *html.Node) bool {
 	}
 }
 
+// wrapperHints are the words of a class or id that mark a div or section as a
+// page wrapper rather than content.
+var wrapperHints = map[string]bool{
+	"wrapper":   true,
+	"wrap":      true,
+	"container": true,
+	"page":      true,
+	"main":      true,
+	"layout":    true,
+	"root":      true,
+	"app":       true,
+	"site":      true,
+	"content":   true,
+}
+
+// isWrapperElement reports whether n is a <main>, or a div or section whose
+// class or id contains a wrapper hint as a whole word, such as "page-wrapper"
+// or "site_container". A div without such a hint, like a hero or a card, is
+// content in its own right.
 func isWrapperElement(n *html.Node) bool {
 	if n.Type != html.ElementNode {
 		return false
 	}
 	switch n.Data {
-	case "div", "main", "section":
-		classAttr := strings.ToLower(getAttributeValue(n, "class"))
-		idAttr := strings.ToLower(getAttributeValue(n, "id"))
-		wrapperHints := []string{
-			"wrapper", "container", "page", "main", "layout", "root", "app", "site", "content",
-		}
-		for _, hint := range wrapperHints {
-			if strings.Contains(classAttr, hint) || strings.Contains(idAttr, hint) {
-				return true
+	case "main":
+		return true
+	case "div", "section":
+		values := strings.Fields(strings.ToLower(getAttributeValue(n, "class")))
+		values = append(values, strings.ToLower(getAttributeValue(n, "id")))
+		for _, value := range values {
+			words := strings.FieldsFunc(value, func(r rune) bool { return r == '-' || r == '_' })
+			for _, word := range words {
+				if wrapperHints[word] {
+					return true
+				}
 			}
 		}
-		return true
+		return false
 	default:
 		return false
 	}
 }
 
-func selectComponentNodes(root *html.Node) []*html.Node {
-	sections := collectSectionComponents(root, 5)
+func selectComponentNodes(root *html.Node, opts EJSExtractionOptions) []*html.Node {
+	sections := collectSectionComponents(root, opts.MaxDepth, opts.ExtraKeywords, opts.Exclude)
 	if len(sections) > 1 {
 		return sections
 	}
 
-	children := filterComponentCandidates(contentChildren(root))
+	children := filterComponentCandidates(contentChildren(root), opts.Exclude)
 	if len(children) > 1 {
 		return children
 	}
 
 	if len(children) == 1 {
-		deeper := filterComponentCandidates(contentChildren(children[0]))
+		deeper := filterComponentCandidates(contentChildren(children[0]), opts.Exclude)
 		if len(deeper) > 1 {
 			return deeper
 		}
@@ -296,17 +424,20 @@ func selectComponentNodes(root *html.Node) []*html.Node {
 	return children
 }
 
-func filterComponentCandidates(nodes []*html.Node) []*html.Node {
+func filterComponentCandidates(nodes []*html.Node, exclude analyzer.Exclusions) []*html.Node {
 	var filtered []*html.Node
 	for _, node := range nodes {
-		if isComponentCandidate(node) {
+		if isComponentCandidate(node, exclude) {
 			filtered = append(filtered, node)
 		}
 	}
 	return filtered
 }
 
-func collectSectionComponents(root *html.Node, maxDepth int) []*html.Node {
+// collectSectionComponents returns the section elements (see
+// isSectionBoundary) within maxDepth levels of root, without descending into
+// a section once found or into an excluded element.
+func collectSectionComponents(root *html.Node, maxDepth int, extraKeywords []string, exclude analyzer.Exclusions) []*html.Node {
 	var nodes []*html.Node
 
 	var walk func(n *html.Node, depth int)
@@ -315,10 +446,10 @@ func collectSectionComponents(root *html.Node, maxDepth int) []*html.Node {
 			return
 		}
 		for child := n.FirstChild; child != nil; child = child.NextSibling {
-			if child.Type != html.ElementNode {
+			if child.Type != html.ElementNode || exclude.Matches(child) {
 				continue
 			}
-			if isSectionBoundary(child) {
+			if isSectionBoundary(child, extraKeywords) {
 				nodes = append(nodes, child)
 				continue
 			}
@@ -330,8 +461,12 @@ func collectSectionComponents(root *html.Node, maxDepth int) []*html.Node {
 	return nodes
 }
 
-func isSectionBoundary(n *html.Node) bool {
-	if isNonContentElement(n) || isEmbedOnlyNode(n) {
+// sectionKeywords are the class and id values that mark a non-semantic
+// element as a section.
+var sectionKeywords = []string{"navbar", "nav", "header", "footer", "hero", "section"}
+
+func isSectionBoundary(n *html.Node, extraKeywords []string) bool {
+	if n.Type != html.ElementNode || htmlparse.IsNonContent(n) || isEmbedOnlyNode(n) {
 		return false
 	}
 	// 'main' is treated as a transparent container — we recurse through it
@@ -344,10 +479,8 @@ func isSectionBoundary(n *html.Node) bool {
 	// For non-semantic elements, only match if a class or the id is exactly a known keyword.
 	classes := strings.Fields(strings.ToLower(getAttributeValue(n, "class")))
 	id := strings.ToLower(getAttributeValue(n, "id"))
-	keywords := []string{
-		"navbar", "nav", "header", "footer", "hero", "section",
-	}
-	for _, keyword := range keywords {
+	for _, keyword := range append(sectionKeywords, extraKeywords...) {
+		keyword = strings.ToLower(keyword)
 		if id == keyword {
 			return true
 		}
@@ -419,7 +552,7 @@ func contentChildren(n *html.Node) []*html.Node {
 		if child.Type != html.ElementNode {
 			continue
 		}
-		if isNonContentElement(child) {
+		if htmlparse.IsNonContent(child) {
 			continue
 		}
 		children = append(children, child)
@@ -427,19 +560,6 @@ func contentChildren(n *html.Node) []*html.Node {
 	return children
 }
 
-func isNonContentElement(n *html.Node) bool {
-	if n.Type != html.ElementNode {
-		return true
-	}
-	switch n.Data {
-	case "script", "style", "link", "meta", "title", "noscript",
-		"svg", "path", "circle", "rect", "line", "polygon", "polyline", "defs", "g", "use":
-		return true
-	default:
-		return false
-	}
-}
-
 func isEmbedOnlyNode(n *html.Node) bool {
 	if n.Type != html.ElementNode {
 		return false
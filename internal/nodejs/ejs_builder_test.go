@@ -0,0 +1,230 @@
+package nodejs
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/omariomari2/uncluster/internal/analyzer"
+	"github.com/omariomari2/uncluster/internal/extractor"
+	"golang.org/x/net/html"
+)
+
+// ejsSection returns a section large enough to be extracted as a partial.
+func ejsSection(tag, class string) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("<%s class=\"%s\">\n<ul>\n", tag, class))
+	for i := 1; i <= 16; i++ {
+		b.WriteString(fmt.Sprintf("<li class=\"item\"><a href=\"/page-%d\">Link number %d</a></li>\n", i, i))
+	}
+	b.WriteString(fmt.Sprintf("</ul>\n</%s>\n", tag))
+	return b.String()
+}
+
+func TestGenerateEJSViewsHandlesFragments(t *testing.T) {
+	index, partials, err := generateEJSViews(ejsSection("nav", "navbar")+ejsSection("footer", "footer"), EJSExtractionOptions{})
+	if err != nil {
+		t.Fatalf("generateEJSViews returned error: %v", err)
+	}
+	if strings.Contains(index, "<html") || strings.Contains(index, "<body") {
+		t.Fatalf("fragment index should not gain document scaffolding:\n%s", index)
+	}
+	if len(partials) != 2 {
+		t.Fatalf("expected 2 partials, got %d: %v", len(partials), partials)
+	}
+	for name := range partials {
+		if !strings.Contains(index, "<%- include('partials/"+name+"') %>") {
+			t.Fatalf("expected index to include %s:\n%s", name, index)
+		}
+	}
+
+	index, partials, err = generateEJSViews("just some text", EJSExtractionOptions{})
+	if err != nil {
+		t.Fatalf("generateEJSViews returned error: %v", err)
+	}
+	if strings.TrimSpace(index) != "just some text" || len(partials) != 0 {
+		t.Fatalf("expected bare text to pass through, got %q and %v", index, partials)
+	}
+}
+
+func TestGenerateEJSViewsExtractionOptions(t *testing.T) {
+	nested := "<html><body>" +
+		"<div class=\"col-a\"><div>" + ejsSection("section", "one") + "</div></div>" +
+		"<div class=\"col-b\"><div>" + ejsSection("section", "two") + "</div></div>" +
+		"</body></html>"
+
+	_, partials, err := generateEJSViews(nested, EJSExtractionOptions{})
+	if err != nil {
+		t.Fatalf("generateEJSViews returned error: %v", err)
+	}
+	if _, ok := partials["section-one"]; !ok {
+		t.Fatalf("expected nested sections to be found by default, got %v", partials)
+	}
+
+	_, partials, err = generateEJSViews(nested, EJSExtractionOptions{MaxDepth: 1})
+	if err != nil {
+		t.Fatalf("generateEJSViews returned error: %v", err)
+	}
+	if _, ok := partials["section-one"]; ok {
+		t.Fatalf("expected MaxDepth 1 to stop before the nested sections, got %v", partials)
+	}
+
+	page := "<html><body><main><div>" + ejsSection("div", "promo") + ejsSection("div", "pricing") + "<p>x</p></div></main></body></html>"
+
+	_, partials, err = generateEJSViews(page, EJSExtractionOptions{ExtraKeywords: []string{"Promo", "pricing"}})
+	if err != nil {
+		t.Fatalf("generateEJSViews returned error: %v", err)
+	}
+	for _, name := range []string{"div-promo", "div-pricing"} {
+		if _, ok := partials[name]; !ok {
+			t.Fatalf("expected partial %s with extra keywords, got %v", name, partials)
+		}
+	}
+
+	_, partials, err = generateEJSViews(page, EJSExtractionOptions{ExtraKeywords: []string{"promo", "pricing"}, MinTextLength: 10000})
+	if err != nil {
+		t.Fatalf("generateEJSViews returned error: %v", err)
+	}
+	if len(partials) != 0 {
+		t.Fatalf("expected MinTextLength to skip small sections, got %v", partials)
+	}
+}
+
+func TestGenerateEJSViewsDeduplicatesStateVariants(t *testing.T) {
+	activeNav := strings.Replace(ejsSection("nav", "navbar"), `class="navbar"`, `class="navbar  active"`, 1)
+	activeNav = strings.Replace(activeNav, "\n<ul>", "\n  <ul>", 1)
+	page := "<html><body>" + ejsSection("nav", "navbar") + "<p>Body copy</p>" + activeNav + "</body></html>"
+
+	index, partials, err := generateEJSViews(page, EJSExtractionOptions{})
+	if err != nil {
+		t.Fatalf("generateEJSViews returned error: %v", err)
+	}
+	if len(partials) != 1 {
+		t.Fatalf("expected a single partial for both navbars, got %v", partials)
+	}
+	if _, ok := partials["nav-navbar"]; !ok {
+		t.Fatalf("expected nav-navbar partial, got %v", partials)
+	}
+	if got := strings.Count(index, "include('partials/nav-navbar')"); got != 2 {
+		t.Fatalf("expected two includes of nav-navbar, got %d in:\n%s", got, index)
+	}
+}
+
+func TestGenerateEJSViewsKeepsComments(t *testing.T) {
+	page := "<!DOCTYPE html><html><head><title>x</title></head><body>" +
+		ejsSection("nav", "navbar") + "<!-- [Webflow] -->" + ejsSection("footer", "footer") +
+		"</body></html>"
+	extracted, err := extractor.Extract(page)
+	if err != nil {
+		t.Fatalf("Extract returned error: %v", err)
+	}
+	if !strings.Contains(extracted.HTML, "</nav>\n\t\t<!-- [Webflow] -->\n\t\t<footer") {
+		t.Fatalf("expected comment to stay between the sections after extraction, got:\n%s", extracted.HTML)
+	}
+
+	index, partials, err := generateEJSViews(extracted.RewriteForEJS(), EJSExtractionOptions{})
+	if err != nil {
+		t.Fatalf("generateEJSViews returned error: %v", err)
+	}
+	if len(partials) != 2 {
+		t.Fatalf("expected 2 partials, got %d", len(partials))
+	}
+	navInclude := strings.Index(index, "include('partials/nav-navbar')")
+	comment := strings.Index(index, "<!-- [Webflow] -->")
+	footerInclude := strings.Index(index, "include('partials/footer-footer')")
+	if navInclude < 0 || comment < navInclude || footerInclude < comment {
+		t.Fatalf("expected comment between the two includes, got:\n%s", index)
+	}
+}
+
+func TestGenerateEJSViewsKeepsNoscript(t *testing.T) {
+	fallback := `<noscript><img src="https://px.example.com/p.gif" height="1" width="1"></noscript>`
+	page := "<!DOCTYPE html><html><head><title>x</title></head><body>" +
+		fallback + ejsSection("nav", "navbar") + ejsSection("footer", "footer") +
+		"</body></html>"
+	extracted, err := extractor.Extract(page)
+	if err != nil {
+		t.Fatalf("Extract returned error: %v", err)
+	}
+
+	index, partials, err := generateEJSViews(extracted.RewriteForEJS(), EJSExtractionOptions{})
+	if err != nil {
+		t.Fatalf("generateEJSViews returned error: %v", err)
+	}
+	if len(partials) != 2 {
+		t.Fatalf("expected 2 partials, got %d", len(partials))
+	}
+	if !strings.Contains(index, fallback) {
+		t.Fatalf("expected noscript fallback to survive extraction, got:\n%s", index)
+	}
+}
+
+func TestGenerateEJSViewsSkipsExcludedSections(t *testing.T) {
+	page := "<!DOCTYPE html><html><head><title>x</title></head><body>" +
+		ejsSection("nav", "navbar") + ejsSection("section", "tracking-pixels") + ejsSection("aside", "promo") +
+		ejsSection("footer", "footer") + "</body></html>"
+
+	_, partials, err := generateEJSViews(page, EJSExtractionOptions{Exclude: analyzer.Exclusions{Classes: []string{"promo"}}})
+	if err != nil {
+		t.Fatalf("generateEJSViews returned error: %v", err)
+	}
+	if len(partials) != 2 || partials["nav-navbar"] == "" || partials["footer-footer"] == "" {
+		t.Fatalf("expected only the nav and footer partials, got %d: %v", len(partials), partials)
+	}
+}
+
+func TestGenerateEJSViewsFallsBackToRootChildren(t *testing.T) {
+	var b strings.Builder
+	b.WriteString("<section class=\"features\">\n")
+	for i := 1; i <= 6; i++ {
+		b.WriteString(fmt.Sprintf("<div class=\"feature\">\n<h3>Feature %d</h3>\n<p>A short description of feature number %d.</p>\n</div>\n", i, i))
+	}
+	b.WriteString("</section>\n")
+
+	index, partials, err := generateEJSViews(b.String(), EJSExtractionOptions{})
+	if err != nil {
+		t.Fatalf("generateEJSViews returned error: %v", err)
+	}
+	if len(partials) != 1 {
+		t.Fatalf("expected the section to become one partial, got %d: %v", len(partials), partials)
+	}
+	for name, partial := range partials {
+		if strings.TrimSpace(index) != "<%- include('partials/"+name+"') %>" {
+			t.Fatalf("expected index to include %s only, got:\n%s", name, index)
+		}
+		if strings.Count(partial, `class="feature"`) != 6 {
+			t.Fatalf("expected the partial to hold every feature, got:\n%s", partial)
+		}
+	}
+}
+
+func TestSelectComponentRootStopsAtContentDivs(t *testing.T) {
+	parse := func(page string) *html.Node {
+		doc, err := html.Parse(strings.NewReader(page))
+		if err != nil {
+			t.Fatalf("parse: %v", err)
+		}
+		return findElement(doc, "body")
+	}
+
+	body := parse(`<body><div class="hero"><h1>Title</h1><p>Intro</p></div></body>`)
+	if root := selectComponentRoot(body, defaultRootDepth); root != body {
+		t.Fatalf("expected a single hero div to stay content, got <%s class=%q>", root.Data, getAttributeValue(root, "class"))
+	}
+
+	body = parse(`<body><div class="page-wrapper"><main><div class="hero"><h1>Title</h1></div><p>Intro</p></main></div></body>`)
+	if root := selectComponentRoot(body, defaultRootDepth); root.Data != "main" {
+		t.Fatalf("expected to descend through the wrapper into <main>, got <%s>", root.Data)
+	}
+	if root := selectComponentRoot(body, 1); getAttributeValue(root, "class") != "page-wrapper" {
+		t.Fatalf("expected a depth of 1 to stop at the wrapper, got <%s>", root.Data)
+	}
+	if root := selectComponentRoot(body, -1); root != body {
+		t.Fatalf("expected a negative depth to keep the body, got <%s>", root.Data)
+	}
+
+	body = parse(`<body><div class="maintenance-banner"><p>Down</p><p>Soon</p></div></body>`)
+	if root := selectComponentRoot(body, defaultRootDepth); root != body {
+		t.Fatalf("expected hints to match whole words only, got <%s>", root.Data)
+	}
+}
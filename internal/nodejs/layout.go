@@ -0,0 +1,71 @@
+package nodejs
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Layout names the folders under src/ that the React project's generated
+// files go to. Empty fields keep the default layout. Folders may be nested,
+// so a feature-folder layout can use "features/home/components".
+type Layout struct {
+	Components string // converted and suggested components; default "components"
+	Styles     string // main.css and external/ stylesheets; default "styles"
+	Scripts    string // external/ scripts; default "scripts"
+}
+
+var defaultLayout = Layout{
+	Components: "components",
+	Styles:     "styles",
+	Scripts:    "scripts",
+}
+
+// layoutFolderPattern matches a relative folder path of plain names.
+var layoutFolderPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+(?:/[A-Za-z0-9_-]+)*$`)
+
+// Normalize fills in the default folders and rejects anything but a relative
+// path of letters, digits, "-", and "_" segments, such as "assets/styles".
+func (l Layout) Normalize() (Layout, error) {
+	fields := []struct {
+		name  string
+		value *string
+		def   string
+	}{
+		{"components", &l.Components, defaultLayout.Components},
+		{"styles", &l.Styles, defaultLayout.Styles},
+		{"scripts", &l.Scripts, defaultLayout.Scripts},
+	}
+	for _, field := range fields {
+		*field.value = strings.Trim(strings.TrimSpace(*field.value), "/")
+		if *field.value == "" {
+			*field.value = field.def
+		} else if !layoutFolderPattern.MatchString(*field.value) {
+			return l, fmt.Errorf("invalid %s folder %q (expected a path under src/ such as assets/%s)", field.name, *field.value, field.name)
+		}
+	}
+	return l, nil
+}
+
+// ComponentPath returns the project path of the component file name.
+func (l Layout) ComponentPath(name string) string {
+	return path.Join("src", l.Components, name)
+}
+
+// StylePath returns the project path of the stylesheet name, such as
+// "main.css" or "external/site.css".
+func (l Layout) StylePath(name string) string {
+	return path.Join("src", l.Styles, name)
+}
+
+// ScriptPath returns the project path of the script name.
+func (l Layout) ScriptPath(name string) string {
+	return path.Join("src", l.Scripts, name)
+}
+
+// srcImport returns the import specifier of the project path file from a
+// module directly in src/, such as main.tsx or App.tsx.
+func srcImport(file string) string {
+	return "./" + strings.TrimPrefix(file, "src/")
+}
@@ -0,0 +1,137 @@
+package nodejs
+
+import (
+	"strings"
+
+	"github.com/omariomari2/uncluster/internal/converter"
+)
+
+// outputCSS returns css as it is written to the project: minified when
+// config.Minify is set, unchanged otherwise.
+func (c *ProjectConfig) outputCSS(css string) string {
+	if !c.Minify {
+		return css
+	}
+	return minifyCSS(css)
+}
+
+// outputJS returns js as it is written to the project, like outputCSS.
+func (c *ProjectConfig) outputJS(js string) string {
+	if !c.Minify {
+		return js
+	}
+	return minifyJS(js)
+}
+
+// minifyCSS strips comments and collapses whitespace, dropping it entirely
+// around braces, semicolons, and commas. Quoted strings are copied as-is.
+// Spaces elsewhere are kept as one, since "a :hover" and calc(1px + 2px)
+// depend on them.
+func minifyCSS(css string) string {
+	var b strings.Builder
+	pendingSpace := false
+	for i := 0; i < len(css); i++ {
+		ch := css[i]
+		switch {
+		case ch == '/' && i+1 < len(css) && css[i+1] == '*':
+			end := strings.Index(css[i+2:], "*/")
+			if end < 0 {
+				i = len(css)
+			} else {
+				i += end + 3
+			}
+			pendingSpace = true
+		case ch == '"' || ch == '\'':
+			end := i + 1
+			for end < len(css) && css[end] != ch {
+				if css[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(css) {
+				end = len(css) - 1
+			}
+			writeCSSSpace(&b, pendingSpace, ch)
+			pendingSpace = false
+			b.WriteString(css[i : end+1])
+			i = end
+		case ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r' || ch == '\f':
+			pendingSpace = true
+		default:
+			writeCSSSpace(&b, pendingSpace, ch)
+			pendingSpace = false
+			b.WriteByte(ch)
+		}
+	}
+	return b.String()
+}
+
+// writeCSSSpace writes the single space a run of whitespace collapses to,
+// unless it sits at the start or next to punctuation that doesn't need it.
+func writeCSSSpace(b *strings.Builder, pending bool, next byte) {
+	if !pending || b.Len() == 0 || strings.IndexByte("{};,", next) >= 0 {
+		return
+	}
+	if prev := b.String()[b.Len()-1]; strings.IndexByte("{};,", prev) >= 0 {
+		return
+	}
+	b.WriteByte(' ')
+}
+
+// minifyJS drops blank lines, lines that hold only a // comment, and trailing
+// whitespace. Lines inside a multi-line template literal are kept as they are.
+// Comments after code and indentation are left alone: telling "//" in strings
+// and regular expressions apart from a comment needs a JS parser.
+func minifyJS(js string) string {
+	breaks := templateLineBreaks(js)
+	var lines []string
+	offset := 0
+	inTemplate := false
+	for _, line := range strings.Split(js, "\n") {
+		end := offset + len(line)
+		offset = end + 1
+		startsInTemplate := inTemplate
+		inTemplate = breaks[end]
+		if !inTemplate {
+			line = strings.TrimRight(line, " \t\r")
+		}
+		if trimmed := strings.TrimSpace(line); !startsInTemplate && (trimmed == "" || strings.HasPrefix(trimmed, "//")) {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// templateLineBreaks returns the indices of the line breaks in js that sit
+// inside a template literal. Like the converter's script scanner it does not
+// recognize regular expression literals.
+func templateLineBreaks(js string) map[int]bool {
+	breaks := make(map[int]bool)
+	for i := 0; i < len(js); i++ {
+		switch ch := js[i]; {
+		case strings.HasPrefix(js[i:], "//"):
+			for i+1 < len(js) && js[i+1] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(js[i:], "/*"):
+			end := strings.Index(js[i+2:], "*/")
+			if end < 0 {
+				return breaks
+			}
+			i += end + 3
+		case ch == '\'' || ch == '"' || ch == '`':
+			end := converter.SkipJSString(js, i)
+			if ch == '`' {
+				for j := i; j < end && j < len(js); j++ {
+					if js[j] == '\n' {
+						breaks[j] = true
+					}
+				}
+			}
+			i = end
+		}
+	}
+	return breaks
+}
@@ -0,0 +1,33 @@
+package nodejs
+
+import "testing"
+
+func TestMinifyCSS(t *testing.T) {
+	cases := map[string]string{
+		"/* header */\nbody {\n  margin: 0;\n  padding: 0 ;\n}\n":     "body{margin: 0;padding: 0;}",
+		"a :hover, a:focus { width: calc(100% - 2px); }":              "a :hover,a:focus{width: calc(100% - 2px);}",
+		`.icon::before { content: "/* keep */  {x}"; }`:               `.icon::before{content: "/* keep */  {x}";}`,
+		"@media (max-width: 600px) {\n  .a { color: red } /* x */\n}": "@media (max-width: 600px){.a{color: red}}",
+	}
+	for input, want := range cases {
+		if got := minifyCSS(input); got != want {
+			t.Errorf("minifyCSS(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestMinifyJS(t *testing.T) {
+	input := "// setup\nconst url = 'https://example.com' // trailing\n\n  // indented comment\n  run(url)   \n"
+	want := "const url = 'https://example.com' // trailing\n  run(url)"
+	if got := minifyJS(input); got != want {
+		t.Fatalf("minifyJS(%q) = %q, want %q", input, got, want)
+	}
+}
+
+func TestMinifyJSKeepsTemplateLiterals(t *testing.T) {
+	input := "const card = `\n  <div>  \n\n  // not a comment\n  ${name}\n`\n\n// done\nrender(card)\n"
+	want := "const card = `\n  <div>  \n\n  // not a comment\n  ${name}\n`\nrender(card)"
+	if got := minifyJS(input); got != want {
+		t.Fatalf("minifyJS(%q) = %q, want %q", input, got, want)
+	}
+}
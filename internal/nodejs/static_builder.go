@@ -0,0 +1,93 @@
+package nodejs
+
+import (
+	"fmt"
+	"github.com/omariomari2/uncluster/internal/fetcher"
+	"path"
+	"strings"
+	"text/template"
+)
+
+// GenerateStaticProject lays the extracted HTML, CSS, and JS out as a plain
+// static site (index.html, css/, js/, external/) with a minimal package.json
+// that serves the folder. config.HTML is expected to already reference the
+// css/ and js/ paths (see ExtractedContent.RewriteForStatic).
+func GenerateStaticProject(config *ProjectConfig) (*ProjectFiles, error) {
+	config.logger().Debug("generating static project", "project", config.ProjectName)
+
+	if err := config.normalizePackageManager(); err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]string)
+
+	packageJSON, err := executeProjectTemplate("package.json", staticPackageJSONTemplate, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate package.json: %w", err)
+	}
+	files["package.json"] = packageJSON
+
+	readme, err := executeProjectTemplate("README.md", staticReadmeTemplate, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate README: %w", err)
+	}
+	files["README.md"] = readme
+	files[".gitignore"] = gitignoreTemplate + gitignoreExtras[config.PackageManager]
+	files["index.html"] = config.HTML
+
+	for _, css := range config.InlineCSS {
+		if strings.TrimSpace(css.Content) != "" {
+			files["css/"+path.Base(css.Path)] = config.outputCSS(css.Content)
+		}
+	}
+
+	for _, js := range config.InlineJS {
+		if strings.TrimSpace(js.Content) != "" {
+			files["js/"+path.Base(js.Path)] = config.outputJS(js.Content)
+		}
+	}
+
+	for _, css := range config.ExternalCSS {
+		if content, ok := externalFileContent(css); ok {
+			files["external/css/"+css.Filename] = content
+		}
+	}
+
+	for _, js := range config.ExternalJS {
+		if content, ok := externalFileContent(js); ok {
+			files["external/js/"+js.Filename] = content
+		}
+	}
+
+	config.logger().Debug("generated static project", "project", config.ProjectName, "files", len(files))
+
+	return &ProjectFiles{Files: files}, nil
+}
+
+func executeProjectTemplate(name, text string, data interface{}) (string, error) {
+	tmpl, err := template.New(name).Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var buf strings.Builder
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// externalFileContent returns the body to write for a fetched resource: its
+// content, or a placeholder when the download failed so that links in the
+// page still resolve.
+func externalFileContent(resource fetcher.FetchedResource) (string, bool) {
+	if resource.Filename == "" {
+		return "", false
+	}
+	if resource.Error != nil {
+		return resource.Placeholder(), true
+	}
+	if strings.TrimSpace(resource.Content) == "" {
+		return "", false
+	}
+	return resource.Content, true
+}
@@ -0,0 +1,49 @@
+package nodejs
+
+const staticPackageJSONTemplate = `{
+  "name": "{{.ProjectName}}",
+  "version": "1.0.0",
+  "private": true,
+  "description": "Static HTML/CSS/JS site generated from HTML",
+  "scripts": {
+    "start": "serve . -l 8080",
+    "dev": "serve . -l 8080"
+  },
+  "devDependencies": {
+    "serve": "^14.2.1"
+  }{{if .PackageManagerSpec}},
+  "packageManager": "{{.PackageManagerSpec}}"{{end}}
+}`
+
+const staticReadmeTemplate = `# {{.ProjectName}}
+
+A plain HTML/CSS/JS site generated from HTML.
+
+## Quick Start
+
+1. Install the dev server:
+   ` + "```" + `bash
+   {{.InstallCommand}}
+   ` + "```" + `
+
+2. Serve the site:
+   ` + "```" + `bash
+   {{.RunCommand "start"}}
+   ` + "```" + `
+
+3. Open your browser to http://localhost:8080
+
+You can also open ` + "`" + `index.html` + "`" + ` directly or upload the folder to any static host.
+
+## Project Structure
+
+` + "```" + `
+{{.ProjectName}}/
+  index.html
+  css/
+  js/
+  external/
+    css/
+    js/
+` + "```" + `
+`
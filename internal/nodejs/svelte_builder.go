@@ -0,0 +1,151 @@
+package nodejs
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// GenerateSvelteProject lays the extracted page out as a Vite + Svelte
+// project. The body markup becomes src/App.svelte, with config.CSS in its
+// <style global> block and config.JS in an onMount callback. External CSS is
+// imported from src/main.js and external JS is served from public/scripts.
+func GenerateSvelteProject(config *ProjectConfig) (*ProjectFiles, error) {
+	config.logger().Debug("generating Svelte project", "project", config.ProjectName)
+
+	if err := config.normalizePackageManager(); err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]string)
+
+	packageJSON, err := executeProjectTemplate("package.json", sveltePackageJSONTemplate, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate package.json: %w", err)
+	}
+	files["package.json"] = packageJSON
+
+	readme, err := executeProjectTemplate("README.md", svelteReadmeTemplate, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate README: %w", err)
+	}
+	files["README.md"] = readme
+
+	indexHTML, err := executeProjectTemplate("index.html", svelteIndexHTMLTemplate, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate index.html: %w", err)
+	}
+
+	markup, err := svelteMarkup(config.HTML)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert HTML to Svelte: %w", err)
+	}
+
+	files["vite.config.js"] = svelteViteConfigTemplate
+	files[".gitignore"] = gitignoreTemplate + gitignoreExtras[config.PackageManager]
+	files["src/App.svelte"] = generateAppSvelte(markup, config.outputCSS(config.CSS), config.outputJS(config.JS))
+
+	var cssImports []string
+	for _, css := range config.ExternalCSS {
+		if content, ok := externalFileContent(css); ok {
+			files["src/styles/external/"+css.Filename] = content
+			cssImports = append(cssImports, "./styles/external/"+css.Filename)
+		}
+	}
+	files["src/main.js"] = generateSvelteMainJS(cssImports)
+
+	var scriptTags strings.Builder
+	for _, js := range config.ExternalJS {
+		if content, ok := externalFileContent(js); ok {
+			files["public/scripts/external/"+js.Filename] = content
+			scriptTags.WriteString(fmt.Sprintf("    <script%s src=\"/scripts/external/%s\"></script>\n", js.ScriptAttributes(), js.Filename))
+		}
+	}
+	// Deferred and module scripts run in document order, so placing them
+	// after main.js lets them see the mounted markup.
+	files["src/index.html"] = strings.Replace(indexHTML, "  </body>", scriptTags.String()+"  </body>", 1)
+
+	config.logger().Debug("generated Svelte project", "project", config.ProjectName, "files", len(files))
+
+	return &ProjectFiles{Files: files}, nil
+}
+
+// svelteBraceEscaper escapes the braces Svelte would otherwise read as
+// template expressions.
+var svelteBraceEscaper = strings.NewReplacer("{", "&#123;", "}", "&#125;")
+
+// svelteMarkup renders the body of htmlContent as Svelte template markup.
+// Scripts, styles, and stylesheet links are dropped since the generator moves
+// them into the component and main.js.
+func svelteMarkup(htmlContent string) (string, error) {
+	doc, err := html.Parse(strings.NewReader(htmlContent))
+	if err != nil {
+		return "", err
+	}
+	body := findElement(doc, "body")
+	if body == nil {
+		return "", nil
+	}
+	removeSvelteHoistedElements(body)
+
+	var buf strings.Builder
+	for child := body.FirstChild; child != nil; child = child.NextSibling {
+		if err := html.Render(&buf, child); err != nil {
+			return "", err
+		}
+	}
+	return strings.TrimSpace(svelteBraceEscaper.Replace(buf.String())), nil
+}
+
+func removeSvelteHoistedElements(n *html.Node) {
+	for child := n.FirstChild; child != nil; {
+		next := child.NextSibling
+		if child.Type == html.ElementNode && (child.Data == "script" || child.Data == "style" ||
+			(child.Data == "link" && strings.EqualFold(getAttributeValue(child, "rel"), "stylesheet"))) {
+			n.RemoveChild(child)
+		} else {
+			removeSvelteHoistedElements(child)
+		}
+		child = next
+	}
+}
+
+func generateAppSvelte(markup, css, js string) string {
+	var buf strings.Builder
+
+	if strings.TrimSpace(js) != "" {
+		buf.WriteString("<script>\n  import { onMount } from 'svelte'\n\n  onMount(() => {\n")
+		for _, line := range strings.Split(strings.TrimSpace(js), "\n") {
+			buf.WriteString(strings.TrimRight("    "+line, " \t") + "\n")
+		}
+		buf.WriteString("  })\n</script>\n\n")
+	}
+
+	buf.WriteString(markup)
+	buf.WriteString("\n")
+
+	if strings.TrimSpace(css) != "" {
+		buf.WriteString("\n<style global>\n")
+		buf.WriteString(strings.TrimSpace(css))
+		buf.WriteString("\n</style>\n")
+	}
+
+	return buf.String()
+}
+
+func generateSvelteMainJS(cssImports []string) string {
+	var buf strings.Builder
+	for _, path := range cssImports {
+		buf.WriteString(fmt.Sprintf("import '%s'\n", path))
+	}
+	buf.WriteString(`import App from './App.svelte'
+
+const app = new App({
+  target: document.getElementById('app'),
+})
+
+export default app
+`)
+	return buf.String()
+}
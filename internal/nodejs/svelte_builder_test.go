@@ -0,0 +1,66 @@
+package nodejs
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/omariomari2/uncluster/internal/fetcher"
+)
+
+func TestGenerateSvelteProject(t *testing.T) {
+	project, err := GenerateSvelteProject(&ProjectConfig{
+		ProjectName: "svelte-project",
+		HTML:        `<html><head></head><body><h1 class="title">Hi {name}</h1><script src="inline/script-1.js"></script></body></html>`,
+		CSS:         "body { margin: 0; }",
+		JS:          "console.log('ready')",
+	})
+	if err != nil {
+		t.Fatalf("GenerateSvelteProject returned error: %v", err)
+	}
+
+	for _, path := range []string{"package.json", "vite.config.js", "src/index.html", "src/main.js", "src/App.svelte"} {
+		if _, ok := project.Files[path]; !ok {
+			t.Fatalf("expected %s to be generated", path)
+		}
+	}
+
+	app := project.Files["src/App.svelte"]
+	for _, want := range []string{
+		`<h1 class="title">Hi &#123;name&#125;</h1>`,
+		"onMount(() => {\n    console.log('ready')\n  })",
+		"<style global>\nbody { margin: 0; }\n</style>",
+	} {
+		if !strings.Contains(app, want) {
+			t.Fatalf("expected %q in App.svelte, got:\n%s", want, app)
+		}
+	}
+	if strings.Contains(app, "inline/script-1.js") {
+		t.Fatalf("page script tags should not be copied into the markup:\n%s", app)
+	}
+}
+
+func TestGenerateSvelteProjectKeepsScriptLoadingAttributes(t *testing.T) {
+	project, err := GenerateSvelteProject(&ProjectConfig{
+		ProjectName: "svelte-scripts",
+		HTML:        `<html><head></head><body><p>Hi</p></body></html>`,
+		ExternalJS: []fetcher.FetchedResource{
+			{Filename: "app.js", Content: "export {}", ScriptType: "module"},
+			{Filename: "analytics.js", Content: "track()", Async: true},
+			{Filename: "jquery.js", Content: "jQuery()"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("GenerateSvelteProject returned error: %v", err)
+	}
+
+	index := project.Files["src/index.html"]
+	for _, want := range []string{
+		`<script type="module" src="/scripts/external/app.js"></script>`,
+		`<script async src="/scripts/external/analytics.js"></script>`,
+		`<script defer src="/scripts/external/jquery.js"></script>`,
+	} {
+		if !strings.Contains(index, want) {
+			t.Fatalf("expected %s in index.html, got:\n%s", want, index)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package nodejs
+
+const sveltePackageJSONTemplate = `{
+  "name": "{{.ProjectName}}",
+  "version": "1.0.0",
+  "private": true,
+  "type": "module",
+  "description": "Generated Svelte project from HTML",
+  "scripts": {
+    "dev": "vite",
+    "build": "vite build",
+    "preview": "vite preview",
+    "start": "{{.RunCommand "dev"}}"
+  },
+  "devDependencies": {
+    "@sveltejs/vite-plugin-svelte": "^3.0.2",
+    "svelte": "^4.2.12",
+    "svelte-preprocess": "^5.1.3",
+    "vite": "^5.1.4"
+  },
+  "keywords": ["svelte", "vite"],
+  "author": "",
+  "license": "MIT"{{if .PackageManagerSpec}},
+  "packageManager": "{{.PackageManagerSpec}}"{{end}}
+}`
+
+// svelteViteConfigTemplate enables svelte-preprocess so App.svelte can use a
+// <style global> block: the extracted CSS targets the whole page, not just
+// the component.
+const svelteViteConfigTemplate = `import { defineConfig } from 'vite'
+import { svelte } from '@sveltejs/vite-plugin-svelte'
+import sveltePreprocess from 'svelte-preprocess'
+
+export default defineConfig({
+  plugins: [svelte({ preprocess: sveltePreprocess() })],
+  root: 'src',
+  publicDir: '../public',
+  build: {
+    outDir: '../dist',
+    emptyOutDir: true
+  },
+  server: {
+    port: 8080,
+    open: true,
+    host: true
+  },
+  preview: {
+    port: 8080,
+    open: true,
+    host: true
+  }
+})`
+
+const svelteIndexHTMLTemplate = `<!DOCTYPE html>
+<html lang="en">
+  <head>
+    <meta charset="UTF-8" />
+    <meta name="viewport" content="width=device-width, initial-scale=1.0" />
+    <title>{{.ProjectName}}</title>
+{{- range .RemoteStylesheets}}
+    <link rel="stylesheet" href="{{html .URL}}" />
+{{- end}}
+  </head>
+  <body>
+    <div id="app"></div>
+    <script type="module" src="/main.js"></script>
+{{- range .RemoteScripts}}
+    <script{{.ScriptAttributes}} src="{{html .URL}}"></script>
+{{- end}}
+  </body>
+</html>
+`
+
+const svelteReadmeTemplate = `# {{.ProjectName}}
+
+A Svelte + Vite project generated from HTML.
+
+## Quick Start
+
+1. Install dependencies:
+   ` + "```" + `bash
+   {{.InstallCommand}}
+   ` + "```" + `
+
+2. Start the development server:
+   ` + "```" + `bash
+   {{.RunCommand "dev"}}
+   ` + "```" + `
+
+3. Open your browser to http://localhost:8080
+
+## Project Structure
+
+` + "```" + `
+{{.ProjectName}}/
+├── package.json
+├── vite.config.js
+├── public/               # Downloaded assets and external scripts
+└── src/
+    ├── index.html        # Vite entry HTML
+    ├── main.js           # Mounts App.svelte
+    ├── App.svelte        # Converted page markup, scripts, and styles
+    └── styles/
+        └── external/     # Downloaded external CSS
+` + "```" + `
+
+## Notes
+
+- The page styles live in a ` + "`" + `<style global>` + "`" + ` block so they apply to the whole page.
+- The page scripts run in ` + "`" + `onMount` + "`" + `, after the markup is in the DOM.
+`
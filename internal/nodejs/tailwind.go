@@ -0,0 +1,84 @@
+package nodejs
+
+import (
+	"regexp"
+	"strings"
+)
+
+// tailwindDirectives is prepended to src/styles/main.css when Tailwind is enabled.
+const tailwindDirectives = `@tailwind base;
+@tailwind components;
+@tailwind utilities;
+`
+
+// minTailwindUtilities is the number of distinct utility classes that must be
+// seen before a page is treated as a Tailwind page.
+const minTailwindUtilities = 3
+
+var (
+	classAttrPattern = regexp.MustCompile(`(?i)\bclass(?:Name)?\s*=\s*["']([^"']*)["']`)
+
+	tailwindUtilityPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`^-?(p|px|py|pt|pr|pb|pl|m|mx|my|mt|mr|mb|ml|gap|gap-x|gap-y|space-x|space-y|inset|top|right|bottom|left)-(\d+(\.5)?|px|auto)$`),
+		regexp.MustCompile(`^(w|h|min-w|min-h|max-w|max-h|size)-(\d+(\.5)?|\d+/\d+|full|screen|auto|min|max|fit|xs|sm|md|lg|xl|[2-7]xl|prose)$`),
+		regexp.MustCompile(`^(text|bg|border|ring|fill|stroke|from|via|to|divide|outline|decoration|placeholder|accent|caret)-(slate|gray|zinc|neutral|stone|red|orange|amber|yellow|lime|green|emerald|teal|cyan|sky|blue|indigo|violet|purple|fuchsia|pink|rose)-\d{2,3}(/\d+)?$`),
+		regexp.MustCompile(`^text-(xs|sm|base|lg|xl|[2-9]xl)$`),
+		regexp.MustCompile(`^(font|leading|tracking)-(thin|extralight|light|normal|medium|semibold|bold|extrabold|black|none|tight|snug|relaxed|loose|tighter|wide|wider|widest|\d+)$`),
+		regexp.MustCompile(`^(rounded|shadow)(-(none|sm|md|lg|xl|2xl|3xl|full|inner|[trbl]|[trbl]-(sm|md|lg|xl|full)))?$`),
+		regexp.MustCompile(`^(flex|grid)-(row|col|wrap|nowrap|1|auto|initial|none|cols-\d+|rows-\d+|row-reverse|col-reverse)$`),
+		regexp.MustCompile(`^(items|justify|content|self|place-items|place-content)-(start|end|center|between|around|evenly|stretch|baseline)$`),
+		regexp.MustCompile(`^(col|row)-span-(\d+|full)$`),
+		regexp.MustCompile(`^(z|opacity|duration|delay)-\d+$`),
+	}
+)
+
+// UsesTailwind reports whether Tailwind scaffolding is generated for the project.
+func (c *ProjectConfig) UsesTailwind() bool {
+	return c.Tailwind != nil && *c.Tailwind
+}
+
+// detectTailwind reports whether the page looks like it was written with
+// Tailwind: either it references Tailwind directly (the CDN build or compiled
+// output with --tw- variables) or its class attributes use enough distinct
+// utility classes.
+func detectTailwind(htmlContent, css string) bool {
+	if strings.Contains(htmlContent, "cdn.tailwindcss.com") ||
+		strings.Contains(css, "--tw-") ||
+		strings.Contains(css, "@tailwind") {
+		return true
+	}
+
+	seen := make(map[string]bool)
+	for _, match := range classAttrPattern.FindAllStringSubmatch(htmlContent, -1) {
+		for _, class := range strings.Fields(match[1]) {
+			utility := stripTailwindVariants(class)
+			if seen[utility] || !isTailwindUtility(utility) {
+				continue
+			}
+			seen[utility] = true
+			if len(seen) >= minTailwindUtilities {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// stripTailwindVariants removes variant prefixes such as "md:" or "hover:" and
+// the "!" important modifier from a class name.
+func stripTailwindVariants(class string) string {
+	if i := strings.LastIndex(class, ":"); i >= 0 {
+		class = class[i+1:]
+	}
+	return strings.TrimPrefix(class, "!")
+}
+
+func isTailwindUtility(class string) bool {
+	for _, pattern := range tailwindUtilityPatterns {
+		if pattern.MatchString(class) {
+			return true
+		}
+	}
+	return false
+}
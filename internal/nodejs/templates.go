@@ -4,39 +4,52 @@ const packageJSONTemplate = `{
   "name": "{{.ProjectName}}",
   "version": "1.0.0",
   "type": "module",
-  "description": "Generated React TypeScript project from HTML",
+  "description": "Generated React {{if .IsTypeScript}}TypeScript{{else}}JavaScript{{end}} project from HTML",
   "main": "server.js",
   "scripts": {
     "dev": "vite",
     "build": "vite build",
     "preview": "vite preview",
     "serve": "node server.js",
-    "lint": "eslint . --ext .ts,.tsx,.js,.jsx",
+    "lint": "eslint . --ext {{if .IsTypeScript}}.ts,.tsx,{{end}}.js,.jsx",
     "format": "prettier --write .",
-    "start": "npm run serve",
-    "type-check": "tsc --noEmit"
+    "start": "{{.RunCommand "serve"}}"{{if .IsTypeScript}},
+    "type-check": "tsc --noEmit"{{end}}
   },
   "dependencies": {
-    "react": "^18.2.0",
-    "react-dom": "^18.2.0",
+    "react": "{{.Versions.React}}",
+    "react-dom": "{{.Versions.React}}",
+{{- if .Router}}
+    "react-router-dom": "{{.Versions.ReactRouter}}",
+{{- end}}
     "express": "^4.18.2"
   },
   "devDependencies": {
-    "@types/react": "^18.2.43",
-    "@types/react-dom": "^18.2.17",
+{{- if .IsTypeScript}}
+    "@types/react": "{{.Versions.TypesReact}}",
+    "@types/react-dom": "{{.Versions.TypesReactDOM}}",
     "@typescript-eslint/eslint-plugin": "^6.14.0",
     "@typescript-eslint/parser": "^6.14.0",
+{{- end}}
     "@vitejs/plugin-react": "^4.2.1",
     "eslint": "^8.55.0",
     "eslint-plugin-react-hooks": "^4.6.0",
     "eslint-plugin-react-refresh": "^0.4.5",
     "prettier": "^3.1.0",
-    "typescript": "^5.3.0",
-    "vite": "^5.0.0"
+{{- if .UsesTailwind}}
+    "autoprefixer": "^10.4.16",
+    "postcss": "^8.4.32",
+    "tailwindcss": "^3.4.0",
+{{- end}}
+{{- if .IsTypeScript}}
+    "typescript": "{{.Versions.TypeScript}}",
+{{- end}}
+    "vite": "{{.Versions.Vite}}"
   },
-  "keywords": ["react", "typescript", "vite", "express", "jsx"],
+  "keywords": ["react", {{if .IsTypeScript}}"typescript", {{end}}"vite", "express", "jsx"],
   "author": "",
-  "license": "MIT"
+  "license": "MIT"{{if .PackageManagerSpec}},
+  "packageManager": "{{.PackageManagerSpec}}"{{end}}
 }`
 
 const viteConfigTemplate = `import { defineConfig } from 'vite'
@@ -62,6 +75,24 @@ export default defineConfig({
   }
 })`
 
+const tailwindConfigTemplate = `/** @type {import('tailwindcss').Config} */
+export default {
+  content: ['./src/**/*.{html,js,jsx,ts,tsx}'],
+  theme: {
+    extend: {}
+  },
+  plugins: []
+}
+`
+
+const postcssConfigTemplate = `export default {
+  plugins: {
+    tailwindcss: {},
+    autoprefixer: {}
+  }
+}
+`
+
 const serverJSTemplate = `import express from 'express'
 import path from 'path'
 import { fileURLToPath } from 'url'
@@ -131,6 +162,43 @@ const eslintConfigTemplate = `{
   }
 }`
 
+const eslintConfigJSTemplate = `{
+  "env": {
+    "browser": true,
+    "es2021": true,
+    "node": true
+  },
+  "extends": [
+    "eslint:recommended",
+    "plugin:react-hooks/recommended"
+  ],
+  "parserOptions": {
+    "ecmaVersion": "latest",
+    "sourceType": "module",
+    "ecmaFeatures": {
+      "jsx": true
+    }
+  },
+  "plugins": [
+    "react-refresh"
+  ],
+  "rules": {
+    "indent": ["error", 2],
+    "linebreak-style": ["error", "unix"],
+    "quotes": ["error", "single"],
+    "semi": ["error", "always"],
+    "no-unused-vars": "warn",
+    "no-console": "off",
+    "react-refresh/only-export-components": [
+      "warn",
+      { "allowConstantExport": true }
+    ]
+  },
+  "globals": {
+    "process": "readonly"
+  }
+}`
+
 const prettierConfigTemplate = `{
   "semi": true,
   "trailingComma": "es5",
@@ -241,15 +309,32 @@ jspm_packages/
 .tern-port
 `
 
+// gitignoreExtras holds package-manager specific entries appended to gitignoreTemplate.
+var gitignoreExtras = map[string]string{
+	"pnpm": `
+.pnpm-debug.log*
+.pnpm-store/
+`,
+	"yarn": `
+.yarn/*
+!.yarn/patches
+!.yarn/plugins
+!.yarn/releases
+!.yarn/sdks
+!.yarn/versions
+.pnp.*
+`,
+}
+
 const readmeTemplate = `# {{.ProjectName}}
 
-A React TypeScript project generated from HTML with Vite build system and Express server.
+A React {{if .IsTypeScript}}TypeScript{{else}}JavaScript{{end}} project generated from HTML with Vite build system and Express server.
 
 ## Features
 
-- **React 18** - Modern React with hooks and concurrent features
-- **TypeScript** - Type safety and enhanced developer experience
-- **Vite** - Fast build tool and development server
+- **React {{.Versions.ReactMajor}}** - Modern React with hooks and concurrent features
+{{if .IsTypeScript}}- **TypeScript** - Type safety and enhanced developer experience
+{{end}}- **Vite** - Fast build tool and development server
 - **Express** - Production-ready web server
 - **Hot Module Reloading** - Instant updates during development
 - **ESLint** - Code quality and consistency with React rules
@@ -262,35 +347,35 @@ A React TypeScript project generated from HTML with Vite build system and Expres
 ### Prerequisites
 
 - Node.js 18+ 
-- npm (comes with Node.js)
+{{if eq .PackageManager "npm"}}- npm (comes with Node.js){{else}}- {{.PackageManager}} (enable it with ` + "`" + `corepack enable` + "`" + `){{end}}
 
 ### Installation
 
 1. Install dependencies:
    ` + "```" + `bash
-   npm install
+   {{.InstallCommand}}
    ` + "```" + `
 
 2. Start the server (builds automatically on first run):
    ` + "```" + `bash
-   npm start
+   {{.RunCommand "start"}}
    ` + "```" + `
 
    OR for live hot-reload development:
    ` + "```" + `bash
-   npm run dev
+   {{.RunCommand "dev"}}
    ` + "```" + `
 
 3. Open your browser to http://localhost:8080
 
 ## Available Scripts
 
-- ` + "`" + `npm run dev` + "`" + ` - Start development server with hot reload
-- ` + "`" + `npm run build` + "`" + ` - Build for production
-- ` + "`" + `npm run preview` + "`" + ` - Preview production build locally
-- ` + "`" + `npm run serve` + "`" + ` - Start production server
-- ` + "`" + `npm run lint` + "`" + ` - Check code quality with ESLint
-- ` + "`" + `npm run format` + "`" + ` - Format code with Prettier
+- ` + "`" + `{{.RunCommand "dev"}}` + "`" + ` - Start development server with hot reload
+- ` + "`" + `{{.RunCommand "build"}}` + "`" + ` - Build for production
+- ` + "`" + `{{.RunCommand "preview"}}` + "`" + ` - Preview production build locally
+- ` + "`" + `{{.RunCommand "serve"}}` + "`" + ` - Start production server
+- ` + "`" + `{{.RunCommand "lint"}}` + "`" + ` - Check code quality with ESLint
+- ` + "`" + `{{.RunCommand "format"}}` + "`" + ` - Format code with Prettier
 
 ## Project Structure
 
@@ -301,17 +386,17 @@ A React TypeScript project generated from HTML with Vite build system and Expres
 ├── server.js             # Express production server
 ├── .eslintrc.json        # ESLint configuration
 ├── .prettierrc           # Prettier configuration
-├── tsconfig.json         # TypeScript configuration
-├── .gitignore            # Git ignore rules
+{{if .IsTypeScript}}├── tsconfig.json         # TypeScript configuration
+{{end}}├── .gitignore            # Git ignore rules
 ├── README.md             # This file
 └── src/
     ├── index.html        # Vite entry HTML
-    ├── main.tsx          # React entry point
-    ├── App.tsx           # Main App component
-    ├── components/
-    │   ├── MainComponent.tsx  # Converted HTML component
-    │   └── Component*.tsx     # Additional components
-    └── styles/
+    ├── main.{{.SourceExt}}          # React entry point
+    ├── App.{{.SourceExt}}           # Main App component
+    ├── {{.Layout.Components}}/
+    │   ├── {{.ComponentName}}.{{.SourceExt}}  # Converted HTML component
+    │   └── Component*.{{.SourceExt}}     # Additional components
+    └── {{.Layout.Styles}}/
         ├── main.css      # Your inline styles
         └── external/     # Downloaded external CSS
 ` + "```" + `
@@ -323,28 +408,37 @@ The project uses Vite for development, which provides:
 - **Instant server start** - No bundling required
 - **Hot Module Replacement (HMR)** - Update modules without page reload
 - **Optimized builds** - Rollup-based production builds
-- **TypeScript support** - Built-in TypeScript support
-
+{{if .IsTypeScript}}- **TypeScript support** - Built-in TypeScript support
+{{end}}
 ## Production Deployment
 
 1. Build the project:
    ` + "```" + `bash
-   npm run build
+   {{.RunCommand "build"}}
    ` + "```" + `
 
 2. Start the production server:
    ` + "```" + `bash
-   npm run serve
+   {{.RunCommand "serve"}}
    ` + "```" + `
 
 3. The server will run on http://localhost:8080 (or PORT environment variable)
+{{if .IncludeDocker}}
+### Docker
+
+The multi-stage ` + "`" + `Dockerfile` + "`" + ` builds the project with Node and serves ` + "`" + `dist/` + "`" + ` with ` + "`" + `server.js` + "`" + `:
 
+` + "```" + `bash
+docker build -t {{.ProjectName}} .
+docker run -p 8080:8080 {{.ProjectName}}
+` + "```" + `
+{{end}}
 ## Customization
 
-- **Components**: Edit files in ` + "`" + `src/components/` + "`" + `
-- **Styling**: Edit files in ` + "`" + `src/styles/` + "`" + `
-- **Main App**: Edit ` + "`" + `src/App.tsx` + "`" + `
-- **Entry Point**: Edit ` + "`" + `src/main.tsx` + "`" + `
+- **Components**: Edit files in ` + "`" + `src/{{.Layout.Components}}/` + "`" + `
+- **Styling**: Edit files in ` + "`" + `src/{{.Layout.Styles}}/` + "`" + `
+- **Main App**: Edit ` + "`" + `src/App.{{.SourceExt}}` + "`" + `
+- **Entry Point**: Edit ` + "`" + `src/main.{{.SourceExt}}` + "`" + `
 - **Build config**: Modify ` + "`" + `vite.config.js` + "`" + `
 - **Server config**: Modify ` + "`" + `server.js` + "`" + `
 
@@ -354,16 +448,16 @@ This project includes the following external resources that were automatically d
 
 {{if .ExternalCSS}}
 ### CSS Files
-{{range .ExternalCSS}}
-- ` + "`" + `src/styles/external/{{.Filename}}` + "`" + ` ({{.URL}})
-{{end}}
+{{range .ExternalCSS}}{{if .Filename}}
+- ` + "`" + `src/{{$.Layout.Styles}}/external/{{.Filename}}` + "`" + ` ({{.URL}})
+{{end}}{{end}}
 {{end}}
 
 {{if .ExternalJS}}
 ### JavaScript Files
-{{range .ExternalJS}}
-- ` + "`" + `src/scripts/external/{{.Filename}}` + "`" + ` ({{.URL}})
-{{end}}
+{{range .ExternalJS}}{{if .Filename}}
+- ` + "`" + `src/{{$.Layout.Scripts}}/external/{{.Filename}}` + "`" + ` ({{.URL}})
+{{end}}{{end}}
 {{end}}
 
 ## License
@@ -371,6 +465,34 @@ This project includes the following external resources that were automatically d
 MIT
 `
 
+// dockerfileTemplate builds the Vite bundle in one stage and runs server.js
+// with production dependencies only in the next.
+const dockerfileTemplate = `FROM node:20-alpine AS build
+WORKDIR /app
+{{if ne .PackageManager "npm"}}RUN corepack enable
+{{end}}COPY . .
+RUN {{.InstallCommand}}
+RUN {{.RunCommand "build"}}
+
+FROM node:20-alpine
+WORKDIR /app
+ENV NODE_ENV=production
+{{if ne .PackageManager "npm"}}RUN corepack enable
+{{end}}COPY package.json ./
+RUN {{.ProductionInstallCommand}}
+COPY server.js ./
+COPY --from=build /app/dist ./dist
+EXPOSE 8080
+CMD {{.DockerCmd}}
+`
+
+const dockerignoreTemplate = `node_modules
+dist
+.git
+.env
+*.log
+`
+
 const mainTsxFallback = `import React from 'react'
 import ReactDOM from 'react-dom/client'
 import App from './App'
@@ -386,23 +508,36 @@ const indexHtmlTemplate = `<!DOCTYPE html>
 <html lang="en">
   <head>
     <meta charset="UTF-8" />
-    <meta name="viewport" content="width=device-width, initial-scale=1.0" />
-    <title>{{.ProjectName}}</title>
+{{- range .PageMeta}}
+    {{.}}
+{{- end}}
+    <title>{{html .PageTitle}}</title>
+{{- range .PageLinks}}
+    {{.}}
+{{- end}}
+{{- range .RemoteStylesheets}}
+    <link rel="stylesheet" href="{{html .URL}}" />
+{{- end}}
   </head>
   <body>
     <div id="root"></div>
-    <script type="module" src="/main.tsx"></script>
+    <script type="module" src="/main.{{.SourceExt}}"></script>
+{{- range .RemoteScripts}}
+    <script{{.ScriptAttributes}} src="{{html .URL}}"></script>
+{{- end}}
   </body>
 </html>
 `
 
+// appTsxTemplate is formatted with the main component name and its import
+// path.
 const appTsxTemplate = `import React from 'react'
-import MainComponent from './components/MainComponent'
+import %[1]s from '%[2]s'
 
 function App() {
   return (
     <div className="App">
-      <MainComponent />
+      <%[1]s />
     </div>
   )
 }
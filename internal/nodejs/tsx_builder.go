@@ -2,9 +2,10 @@ package nodejs
 
 import (
 	"fmt"
+	"github.com/omariomari2/uncluster/internal/analyzer"
 	"github.com/omariomari2/uncluster/internal/converter"
 	"github.com/omariomari2/uncluster/internal/fetcher"
-	"log"
+	"log/slog"
 	"strings"
 
 	"golang.org/x/net/html"
@@ -16,16 +17,46 @@ type tsxComponent struct {
 	Node *html.Node
 }
 
-// generateTSXViews finds semantic sections in htmlContent, converts each to a
-// TSX component, and returns:
-//   - sectionFiles: map "src/components/<Name>.tsx" → file content
-//   - mainComponent: content of MainComponent.tsx (imports + renders all sections)
+// generateTSXViews finds semantic sections in htmlContent, below the content
+// root selectComponentRoot picks as for EJS partials, converts each to a
+// TSX component rendering the suggested components (nil for none) in place of
+// their markup, and returns:
+//   - sectionFiles: map "src/<layout.Components>/<Name>.tsx" → file content
+//   - mainComponent: content of <componentName>.tsx (imports + renders all sections)
 //   - mainTsx: content of src/main.tsx (dynamic CSS imports)
+//
+// When language is "js" the same files are produced as plain .jsx. With router
+// set and two or more sections, mainTsx sets up react-router-dom with the full
+// page at / and a route per section. Sections that fail to convert are logged
+// to logger and left out.
 func generateTSXViews(
 	htmlContent string,
 	inlineCSS string,
 	externalCSS []fetcher.FetchedResource,
+	language string,
+	componentName string,
+	router bool,
+	layout Layout,
+	components *converter.ComponentLibrary,
+	logger *slog.Logger,
 ) (sectionFiles map[string]string, mainComponent string, mainTsx string, err error) {
+	typescript := language != "js"
+	ext := ".tsx"
+	if !typescript {
+		ext = ".jsx"
+	}
+	convertSection := func(htmlFragment, name string) (string, error) {
+		return converter.ConvertSectionWithComponents(htmlFragment, name, typescript, components)
+	}
+
+	// Sections are renamed rather than overwrite the main component or a
+	// suggested one.
+	reserved := map[string]bool{componentName: true}
+	if components != nil {
+		for _, component := range components.Components {
+			reserved[component.Name] = true
+		}
+	}
 
 	doc, err := html.Parse(strings.NewReader(htmlContent))
 	if err != nil {
@@ -34,22 +65,22 @@ func generateTSXViews(
 
 	body := findElement(doc, "body")
 	if body == nil {
-		mc, convErr := converter.ConvertSectionToTSX(htmlContent, "MainComponent")
+		mc, convErr := convertSection(htmlContent, componentName)
 		if convErr != nil {
 			return nil, "", "", convErr
 		}
-		return map[string]string{}, mc, generateMainTsx(inlineCSS, externalCSS), nil
+		return map[string]string{}, mc, generateMainTsx(inlineCSS, externalCSS, language, layout), nil
 	}
 
-	root := selectComponentRoot(body)
-	sections := collectSectionComponents(root, 5)
+	root := selectComponentRoot(body, defaultRootDepth)
+	sections := collectSectionComponents(root, defaultSectionDepth, nil, analyzer.Exclusions{})
 
 	if len(sections) == 0 {
-		mc, convErr := converter.ConvertSectionToTSX(htmlContent, "MainComponent")
+		mc, convErr := convertSection(htmlContent, componentName)
 		if convErr != nil {
 			return nil, "", "", convErr
 		}
-		return map[string]string{}, mc, generateMainTsx(inlineCSS, externalCSS), nil
+		return map[string]string{}, mc, generateMainTsx(inlineCSS, externalCSS, language, layout), nil
 	}
 
 	usedNames := make(map[string]int)
@@ -59,7 +90,7 @@ func generateTSXViews(
 	for idx, node := range sections {
 		rawHTML, renderErr := renderNodeHTML(node)
 		if renderErr != nil {
-			log.Printf("tsx_builder: failed to render section node %d: %v", idx, renderErr)
+			logger.Error("tsx_builder: failed to render section node", "index", idx, "error", renderErr)
 			continue
 		}
 		trimmed := strings.TrimSpace(rawHTML)
@@ -71,6 +102,9 @@ func generateTSXViews(
 		if !ok {
 			kebab := buildComponentName(node, idx, usedNames)
 			name = toPascalCase(kebab)
+			if reserved[name] {
+				name += "Section"
+			}
 			nameByContent[trimmed] = name
 		}
 
@@ -78,30 +112,36 @@ func generateTSXViews(
 	}
 
 	if len(resolved) == 0 {
-		mc, convErr := converter.ConvertSectionToTSX(htmlContent, "MainComponent")
+		mc, convErr := convertSection(htmlContent, componentName)
 		if convErr != nil {
 			return nil, "", "", convErr
 		}
-		return map[string]string{}, mc, generateMainTsx(inlineCSS, externalCSS), nil
+		return map[string]string{}, mc, generateMainTsx(inlineCSS, externalCSS, language, layout), nil
 	}
 
 	sectionFiles = make(map[string]string, len(resolved))
 	seen := make(map[string]bool)
+	var converted []string
 	for _, comp := range resolved {
 		if seen[comp.Name] {
 			continue
 		}
 		seen[comp.Name] = true
 
-		tsxContent, convErr := converter.ConvertSectionToTSX(comp.HTML, comp.Name)
+		tsxContent, convErr := convertSection(comp.HTML, comp.Name)
 		if convErr != nil {
-			log.Printf("tsx_builder: failed to convert section %q: %v", comp.Name, convErr)
+			logger.Error("tsx_builder: failed to convert section", "section", comp.Name, "error", convErr)
 			continue
 		}
-		sectionFiles["src/components/"+comp.Name+".tsx"] = tsxContent
+		sectionFiles[layout.ComponentPath(comp.Name+ext)] = tsxContent
+		converted = append(converted, comp.Name)
 	}
 
-	return sectionFiles, generateMainComponentTSX(resolved), generateMainTsx(inlineCSS, externalCSS), nil
+	mainTsx = generateMainTsx(inlineCSS, externalCSS, language, layout)
+	if router && len(converted) >= 2 {
+		mainTsx = generateRouterMainTsx(inlineCSS, externalCSS, language, layout, converted)
+	}
+	return sectionFiles, generateMainComponentTSX(componentName, resolved), mainTsx, nil
 }
 
 func toPascalCase(s string) string {
@@ -127,7 +167,7 @@ func toPascalCase(s string) string {
 	return result
 }
 
-func generateMainComponentTSX(sections []tsxComponent) string {
+func generateMainComponentTSX(componentName string, sections []tsxComponent) string {
 	var imports strings.Builder
 	var jsxLines strings.Builder
 
@@ -142,37 +182,99 @@ func generateMainComponentTSX(sections []tsxComponent) string {
 	}
 
 	return fmt.Sprintf(`import React from 'react'
-%s
-function MainComponent() {
+%[2]s
+function %[1]s() {
   return (
     <>
-%s    </>
+%[3]s    </>
   )
 }
 
-export default MainComponent
-`, imports.String(), jsxLines.String())
+export default %[1]s
+`, componentName, imports.String(), jsxLines.String())
 }
 
-func generateMainTsx(inlineCSS string, externalCSS []fetcher.FetchedResource) string {
-	var cssImports strings.Builder
-	if strings.TrimSpace(inlineCSS) != "" {
-		cssImports.WriteString("import './styles/main.css'\n")
-	}
-	for _, res := range externalCSS {
-		if res.Error == nil && strings.TrimSpace(res.Content) != "" {
-			cssImports.WriteString(fmt.Sprintf("import './styles/external/%s'\n", res.Filename))
-		}
-	}
+func generateMainTsx(inlineCSS string, externalCSS []fetcher.FetchedResource, language string, layout Layout) string {
+	cssImports, nonNull := mainTsxImports(inlineCSS, externalCSS, language, layout)
 
 	return fmt.Sprintf(`import React from 'react'
 import ReactDOM from 'react-dom/client'
 import App from './App'
 %s
-ReactDOM.createRoot(document.getElementById('root')!).render(
+ReactDOM.createRoot(document.getElementById('root')%s).render(
   <React.StrictMode>
     <App />
   </React.StrictMode>,
 )
-`, cssImports.String())
+`, cssImports, nonNull)
+}
+
+// generateRouterMainTsx is generateMainTsx wrapped in a BrowserRouter: App,
+// the whole page, is served at / and each section component at its own
+// route, with a nav linking them.
+func generateRouterMainTsx(inlineCSS string, externalCSS []fetcher.FetchedResource, language string, layout Layout, sections []string) string {
+	cssImports, nonNull := mainTsxImports(inlineCSS, externalCSS, language, layout)
+
+	var imports, links, routes strings.Builder
+	for _, name := range sections {
+		path := "/" + routePath(name)
+		imports.WriteString(fmt.Sprintf("import %s from '%s'\n", name, srcImport(layout.ComponentPath(name))))
+		links.WriteString(fmt.Sprintf("        <NavLink to=%q>%s</NavLink>\n", path, name))
+		routes.WriteString(fmt.Sprintf("        <Route path=%q element={<%s />} />\n", path, name))
+	}
+
+	return fmt.Sprintf(`import React from 'react'
+import ReactDOM from 'react-dom/client'
+import { BrowserRouter, NavLink, Route, Routes } from 'react-router-dom'
+import App from './App'
+%[1]s%[2]s
+ReactDOM.createRoot(document.getElementById('root')%[3]s).render(
+  <React.StrictMode>
+    <BrowserRouter>
+      <nav>
+        <NavLink to="/" end>Home</NavLink>
+%[4]s      </nav>
+      <Routes>
+        <Route path="/" element={<App />} />
+%[5]s      </Routes>
+    </BrowserRouter>
+  </React.StrictMode>,
+)
+`, imports.String(), cssImports, nonNull, links.String(), routes.String())
+}
+
+// routePath turns a component name into a URL segment: HeroSection becomes
+// hero-section.
+func routePath(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if r >= 'A' && r <= 'Z' {
+			if i > 0 {
+				b.WriteByte('-')
+			}
+			r += 'a' - 'A'
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// mainTsxImports returns the stylesheet imports of src/main.tsx and the
+// non-null assertion its getElementById call needs in TypeScript.
+func mainTsxImports(inlineCSS string, externalCSS []fetcher.FetchedResource, language string, layout Layout) (string, string) {
+	var cssImports strings.Builder
+	if strings.TrimSpace(inlineCSS) != "" {
+		cssImports.WriteString(fmt.Sprintf("import '%s'\n", srcImport(layout.StylePath("main.css"))))
+	}
+	for _, res := range externalCSS {
+		if res.Error == nil && strings.TrimSpace(res.Content) != "" {
+			cssImports.WriteString(fmt.Sprintf("import '%s'\n", srcImport(layout.StylePath("external/"+res.Filename))))
+		}
+	}
+
+	nonNull := "!"
+	if language == "js" {
+		nonNull = ""
+	}
+	return cssImports.String(), nonNull
 }
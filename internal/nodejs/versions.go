@@ -0,0 +1,81 @@
+package nodejs
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// DependencyVersions sets the package.json versions of the React project's
+// main dependencies. Empty fields keep the defaults.
+type DependencyVersions struct {
+	React       string // react and react-dom; @types/react follows its major version
+	Vite        string
+	TypeScript  string
+	ReactRouter string // react-router-dom, added when the project has routes
+}
+
+var defaultDependencyVersions = DependencyVersions{
+	React:       "^18.2.0",
+	Vite:        "^5.0.0",
+	TypeScript:  "^5.3.0",
+	ReactRouter: "^6.22.3",
+}
+
+// versionPattern matches a semver version, optionally partial or with a
+// prerelease, behind an optional ^, ~, or comparison operator.
+var versionPattern = regexp.MustCompile(`^(?:\^|~|>=|<=|>|<|=)?\d+(?:\.\d+){0,2}(?:-[0-9A-Za-z.-]+)?$`)
+
+// Normalize fills in the default versions and rejects values that are not a
+// version or simple range such as "19", "^19.0.0", or "~5.4.2".
+func (v DependencyVersions) Normalize() (DependencyVersions, error) {
+	fields := []struct {
+		name  string
+		value *string
+		def   string
+	}{
+		{"react", &v.React, defaultDependencyVersions.React},
+		{"vite", &v.Vite, defaultDependencyVersions.Vite},
+		{"typescript", &v.TypeScript, defaultDependencyVersions.TypeScript},
+		{"react-router-dom", &v.ReactRouter, defaultDependencyVersions.ReactRouter},
+	}
+	for _, field := range fields {
+		*field.value = strings.TrimSpace(*field.value)
+		if *field.value == "" {
+			*field.value = field.def
+		} else if !versionPattern.MatchString(*field.value) {
+			return v, fmt.Errorf("invalid %s version %q (expected a version such as 19.0.0 or ^19.0.0)", field.name, *field.value)
+		}
+	}
+	return v, nil
+}
+
+// ReactMajor returns React's major version, e.g. "18".
+func (v DependencyVersions) ReactMajor() string {
+	return majorVersion(v.React)
+}
+
+// TypesReact returns the @types/react version: the pinned default for the
+// default React version, otherwise the latest release of React's major.
+func (v DependencyVersions) TypesReact() string {
+	if v.React == defaultDependencyVersions.React {
+		return "^18.2.43"
+	}
+	return "^" + v.ReactMajor() + ".0.0"
+}
+
+// TypesReactDOM returns the @types/react-dom version, chosen like TypesReact.
+func (v DependencyVersions) TypesReactDOM() string {
+	if v.React == defaultDependencyVersions.React {
+		return "^18.2.17"
+	}
+	return "^" + v.ReactMajor() + ".0.0"
+}
+
+func majorVersion(version string) string {
+	version = strings.TrimLeft(version, "^~<>=")
+	if i := strings.IndexAny(version, ".-"); i >= 0 {
+		return version[:i]
+	}
+	return version
+}
@@ -1,11 +1,12 @@
 package nodejs
 
 import (
-	"archive/zip"
 	"bytes"
 	"fmt"
+	"github.com/omariomari2/uncluster/internal/zipper"
 	"io"
-	"log"
+	"log/slog"
+	"sort"
 )
 
 func CreateProjectZip(files map[string]string, projectName string) ([]byte, error) {
@@ -14,50 +15,95 @@ func CreateProjectZip(files map[string]string, projectName string) ([]byte, erro
 
 // CreateProjectZipWithBinary creates a ZIP archive containing both text files
 // and binary files (images, fonts, SVGs from scraped or uploaded sources).
+// Entries are written in sorted order with a fixed modification time, so the
+// same files always produce the same archive bytes.
 func CreateProjectZipWithBinary(files map[string]string, binaryFiles map[string][]byte, projectName string) ([]byte, error) {
+	return CreateProjectZipWithOptions(files, binaryFiles, projectName, zipper.Options{})
+}
+
+// CreateProjectZipWithOptions is CreateProjectZipWithBinary with control over
+// how the entries are compressed.
+func CreateProjectZipWithOptions(files map[string]string, binaryFiles map[string][]byte, projectName string, opts zipper.Options) ([]byte, error) {
+	opts, err := opts.Normalize()
+	if err != nil {
+		return nil, err
+	}
+
 	var buf bytes.Buffer
-	writer := zip.NewWriter(&buf)
+	if err := writeProject(opts.NewEntryWriter(&buf), files, binaryFiles, projectName); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// CreateProjectTarGz is CreateProjectZip for clients that prefer a gzipped
+// tarball. The entries are the same.
+func CreateProjectTarGz(files map[string]string, projectName string) ([]byte, error) {
+	return CreateProjectTarGzWithBinary(files, nil, projectName)
+}
 
+// CreateProjectTarGzWithBinary is CreateProjectZipWithBinary writing a
+// gzipped tarball.
+func CreateProjectTarGzWithBinary(files map[string]string, binaryFiles map[string][]byte, projectName string) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := writeProject(zipper.NewTarGzWriter(&buf), files, binaryFiles, projectName); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeProject adds the files under projectName/ to writer and closes it.
+func writeProject(writer zipper.EntryWriter, files map[string]string, binaryFiles map[string][]byte, projectName string) error {
 	written := 0
-	for filepath, content := range files {
+	for _, filepath := range sortedKeys(files) {
+		content := files[filepath]
 		fullPath := projectName + "/" + filepath
 
-		file, err := writer.Create(fullPath)
+		file, err := writer.CreateEntry(fullPath)
 		if err != nil {
-			log.Printf("zip: failed to create entry %s: %v", fullPath, err)
+			slog.Error("zip: failed to create entry", "path", fullPath, "error", err)
 			continue
 		}
 
 		if _, err = io.WriteString(file, content); err != nil {
-			log.Printf("zip: failed to write entry %s: %v", fullPath, err)
+			slog.Error("zip: failed to write entry", "path", fullPath, "error", err)
 			continue
 		}
 		written++
 	}
 
-	for filepath, data := range binaryFiles {
+	for _, filepath := range sortedKeys(binaryFiles) {
+		data := binaryFiles[filepath]
 		fullPath := projectName + "/" + filepath
 
-		file, err := writer.Create(fullPath)
+		file, err := writer.CreateEntry(fullPath)
 		if err != nil {
-			log.Printf("zip: failed to create binary entry %s: %v", fullPath, err)
+			slog.Error("zip: failed to create binary entry", "path", fullPath, "error", err)
 			continue
 		}
 
 		if _, err = file.Write(data); err != nil {
-			log.Printf("zip: failed to write binary entry %s: %v", fullPath, err)
+			slog.Error("zip: failed to write binary entry", "path", fullPath, "error", err)
 			continue
 		}
 		written++
 	}
 
 	if err := writer.Close(); err != nil {
-		return nil, err
+		return err
 	}
 
 	if written == 0 && (len(files) > 0 || len(binaryFiles) > 0) {
-		return nil, fmt.Errorf("failed to write any files to zip archive")
+		return fmt.Errorf("failed to write any files to archive")
 	}
+	return nil
+}
 
-	return buf.Bytes(), nil
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
 }
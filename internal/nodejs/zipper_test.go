@@ -0,0 +1,133 @@
+package nodejs
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestCreateProjectZipIsDeterministic(t *testing.T) {
+	files := map[string]string{
+		"package.json":              `{"name": "demo"}`,
+		"src/App.tsx":               "export default function App() { return null }",
+		"src/main.tsx":              "import App from './App'",
+		"src/styles/main.css":       "body { margin: 0; }",
+		"src/components/Header.tsx": "export default function Header() { return null }",
+		"src/components/Footer.tsx": "export default function Footer() { return null }",
+		"src/scripts/external/a.js": "console.log('a')",
+		"src/styles/external/b.css": "p { color: red; }",
+		"README.md":                 "# demo",
+		".gitignore":                "node_modules\n",
+	}
+	binary := map[string][]byte{
+		"public/assets/logo.png": {0x89, 'P', 'N', 'G'},
+		"public/assets/icon.ico": {0, 0, 1, 0},
+	}
+
+	first, err := CreateProjectZipWithBinary(files, binary, "demo")
+	if err != nil {
+		t.Fatalf("first zip failed: %v", err)
+	}
+	second, err := CreateProjectZipWithBinary(files, binary, "demo")
+	if err != nil {
+		t.Fatalf("second zip failed: %v", err)
+	}
+
+	if !bytes.Equal(first, second) {
+		t.Fatalf("expected identical archives for identical input")
+	}
+
+	reader, err := zip.NewReader(bytes.NewReader(first), int64(len(first)))
+	if err != nil {
+		t.Fatalf("failed to read zip: %v", err)
+	}
+	for i := 1; i < len(files); i++ {
+		if reader.File[i-1].Name > reader.File[i].Name {
+			t.Fatalf("expected sorted entries, got %q before %q", reader.File[i-1].Name, reader.File[i].Name)
+		}
+	}
+}
+
+func TestCreateProjectZipNeutralizesPathTraversal(t *testing.T) {
+	files := map[string]string{
+		"../../etc/passwd":          "root",
+		"src\\..\\..\\evil.js":      "alert(1)",
+		"/public/scripts/abs.js":    "abs",
+		"src/scripts/external/a.js": "ok",
+	}
+
+	data, err := CreateProjectZip(files, "demo")
+	if err != nil {
+		t.Fatalf("CreateProjectZip returned error: %v", err)
+	}
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("failed to read zip: %v", err)
+	}
+
+	want := map[string]bool{
+		"demo/etc/passwd":                true,
+		"demo/src/evil.js":               true,
+		"demo/public/scripts/abs.js":     true,
+		"demo/src/scripts/external/a.js": true,
+	}
+	for _, f := range reader.File {
+		if !want[f.Name] {
+			t.Fatalf("unexpected entry %q", f.Name)
+		}
+	}
+	if len(reader.File) != len(want) {
+		t.Fatalf("expected %d entries, got %d", len(want), len(reader.File))
+	}
+}
+
+func TestCreateProjectTarGzContainsProjectFiles(t *testing.T) {
+	files := map[string]string{
+		"package.json": `{"name": "demo"}`,
+		"src/App.tsx":  "export default function App() { return null }",
+		"../escape.js": "alert(1)",
+	}
+
+	data, err := CreateProjectTarGz(files, "demo")
+	if err != nil {
+		t.Fatalf("CreateProjectTarGz returned error: %v", err)
+	}
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("failed to read gzip: %v", err)
+	}
+	reader := tar.NewReader(gz)
+
+	want := map[string]string{
+		"demo/escape.js":    "alert(1)",
+		"demo/package.json": `{"name": "demo"}`,
+		"demo/src/App.tsx":  "export default function App() { return null }",
+	}
+	got := make(map[string]string)
+	for {
+		header, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("failed to read tar entry: %v", err)
+		}
+		content, err := io.ReadAll(reader)
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", header.Name, err)
+		}
+		got[header.Name] = string(content)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d entries, got %v", len(want), got)
+	}
+	for name, content := range want {
+		if got[name] != content {
+			t.Fatalf("entry %s = %q, want %q", name, got[name], content)
+		}
+	}
+}
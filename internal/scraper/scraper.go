@@ -6,9 +6,7 @@ import (
 	"github.com/omariomari2/uncluster/internal/extractor"
 	"github.com/omariomari2/uncluster/internal/fetcher"
 	"github.com/omariomari2/uncluster/internal/formatter"
-	"io"
-	"log"
-	"net/http"
+	"log/slog"
 	"net/url"
 	"path"
 	"regexp"
@@ -28,7 +26,7 @@ func ScrapeURL(rawURL string) (*extractor.ExtractedContent, error) {
 		return nil, fmt.Errorf("invalid URL: must start with http:// or https://")
 	}
 
-	pageHTML, err := fetchPage(rawURL)
+	pageHTML, err := fetcher.FetchPage(rawURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch page: %w", err)
 	}
@@ -38,6 +36,7 @@ func ScrapeURL(rawURL string) (*extractor.ExtractedContent, error) {
 		return nil, fmt.Errorf("failed to parse HTML: %w", err)
 	}
 
+	head := extractor.ExtractHeadMetadata(doc)
 	cssURLs, jsURLs, binaryURLs := findAllAssetURLs(doc, base)
 
 	// Build a URL→localPath map for path rewriting
@@ -50,8 +49,11 @@ func ScrapeURL(rawURL string) (*extractor.ExtractedContent, error) {
 	if len(cssURLs) > 0 {
 		externalCSS = fetcher.FetchExternalResources(cssURLs, "css")
 		for _, r := range externalCSS {
+			if r.SkipReason != "" {
+				continue
+			}
+			urlToLocal[r.URL] = "external/css/" + r.Filename
 			if r.Error == nil {
-				urlToLocal[r.URL] = "external/css/" + r.Filename
 				// Also scan CSS content for url() references (fonts, bg images)
 				extraBinary := extractCSSURLs(r.Content, r.URL)
 				binaryURLs = append(binaryURLs, extraBinary...)
@@ -62,9 +64,10 @@ func ScrapeURL(rawURL string) (*extractor.ExtractedContent, error) {
 	if len(jsURLs) > 0 {
 		externalJS = fetcher.FetchExternalResources(jsURLs, "js")
 		for _, r := range externalJS {
-			if r.Error == nil {
-				urlToLocal[r.URL] = "external/js/" + r.Filename
+			if r.SkipReason != "" {
+				continue
 			}
+			urlToLocal[r.URL] = "external/js/" + r.Filename
 		}
 	}
 
@@ -77,7 +80,7 @@ func ScrapeURL(rawURL string) (*extractor.ExtractedContent, error) {
 	for _, bURL := range binaryURLs {
 		data, mime, err := fetcher.FetchRaw(bURL)
 		if err != nil {
-			log.Printf("scraper: skipping binary asset %s: %v", bURL, err)
+			slog.Warn("scraper: skipping binary asset", "url", bURL, "error", err)
 			continue
 		}
 		filename := binaryFilename(bURL, mime, binaryUsedNames)
@@ -87,6 +90,7 @@ func ScrapeURL(rawURL string) (*extractor.ExtractedContent, error) {
 			Path:    localPath,
 			Content: data,
 			MIME:    mime,
+			URL:     bURL,
 		})
 	}
 
@@ -122,43 +126,11 @@ func ScrapeURL(rawURL string) (*extractor.ExtractedContent, error) {
 		ExternalCSS: externalCSS,
 		ExternalJS:  externalJS,
 		LocalAssets: localAssets,
-	}, nil
-}
+		Head:        head,
 
-// fetchPage downloads the HTML content of a URL with a browser User-Agent.
-func fetchPage(rawURL string) (string, error) {
-	client := &http.Client{
-		Timeout: 30 * time.Second,
-		CheckRedirect: func(req *http.Request, via []*http.Request) error {
-			if len(via) >= 10 {
-				return http.ErrUseLastResponse
-			}
-			return nil
-		},
-	}
-
-	req, err := http.NewRequest("GET", rawURL, nil)
-	if err != nil {
-		return "", err
-	}
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
-	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return "", err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return "", fmt.Errorf("HTTP %d", resp.StatusCode)
-	}
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", err
-	}
-	return string(body), nil
+		ExtractedAt:  time.Now().UTC(),
+		SourceLength: len(pageHTML),
+	}, nil
 }
 
 // findAllAssetURLs walks the HTML tree and collects absolute URLs for
@@ -397,10 +369,13 @@ func extractInlineResources(n *html.Node, cssContent, jsContent *strings.Builder
 			if strings.TrimSpace(content) != "" {
 				*jsIndex++
 				filename := fmt.Sprintf("inline/script-%d.js", *jsIndex)
-				*inlineJS = append(*inlineJS, extractor.InlineResource{Path: filename, Content: content})
-				jsContent.WriteString(content)
-				if !strings.HasSuffix(content, "\n") {
-					jsContent.WriteString("\n")
+				scriptType := strings.TrimSpace(getAttr(n, "type"))
+				*inlineJS = append(*inlineJS, extractor.InlineResource{Path: filename, Content: content, Type: scriptType})
+				if !strings.EqualFold(scriptType, "module") {
+					jsContent.WriteString(content)
+					if !strings.HasSuffix(content, "\n") {
+						jsContent.WriteString("\n")
+					}
 				}
 				script := &html.Node{
 					Type: html.ElementNode,
@@ -409,6 +384,9 @@ func extractInlineResources(n *html.Node, cssContent, jsContent *strings.Builder
 						{Key: "src", Val: "/" + filename},
 					},
 				}
+				if scriptType != "" {
+					script.Attr = append(script.Attr, html.Attribute{Key: "type", Val: scriptType})
+				}
 				replaceNode(n, script)
 				return
 			}
@@ -0,0 +1,101 @@
+package zipper
+
+import (
+	"archive/zip"
+	"compress/flate"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// entryModTime is stamped on every archive entry instead of the current time so
+// that identical inputs produce byte-identical archives.
+var entryModTime = time.Date(1980, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// Options controls how archive entries are compressed. The zero value
+// deflates at the default level.
+type Options struct {
+	// Store writes entries uncompressed. It saves CPU when the content is
+	// already compressed, such as images or base64 data.
+	Store bool
+	// Level is the deflate level, from flate.BestSpeed (1) to
+	// flate.BestCompression (9). Zero means flate.DefaultCompression.
+	Level int
+}
+
+// Normalize validates the options.
+func (o Options) Normalize() (Options, error) {
+	if o.Level < 0 || o.Level > flate.BestCompression {
+		return o, fmt.Errorf("invalid compression level %d (expected 1-9, or 0 for the default)", o.Level)
+	}
+	return o, nil
+}
+
+func (o Options) level() int {
+	if o.Level == 0 {
+		return flate.DefaultCompression
+	}
+	return o.Level
+}
+
+func (o Options) method() uint16 {
+	if o.Store {
+		return zip.Store
+	}
+	return zip.Deflate
+}
+
+// SanitizeEntryName makes name safe to extract: backslashes become slashes,
+// and empty, ".", and ".." segments, leading slashes, and drive letters are
+// dropped, so no entry can land outside the extraction directory (zip slip).
+// It returns an error when nothing is left of the name.
+func SanitizeEntryName(name string) (string, error) {
+	var segments []string
+	for i, segment := range strings.Split(strings.ReplaceAll(name, "\\", "/"), "/") {
+		if segment == "" || segment == "." || segment == ".." || (i == 0 && strings.HasSuffix(segment, ":")) {
+			continue
+		}
+		segments = append(segments, segment)
+	}
+	if len(segments) == 0 {
+		return "", fmt.Errorf("invalid zip entry name %q", name)
+	}
+	return strings.Join(segments, "/"), nil
+}
+
+// NewWriter returns a zip.Writer that compresses entries at a fixed deflate level.
+func NewWriter(w io.Writer) *zip.Writer {
+	return Options{}.NewWriter(w)
+}
+
+// NewWriter returns a zip.Writer that deflates entries at the options' level.
+func (o Options) NewWriter(w io.Writer) *zip.Writer {
+	level := o.level()
+	writer := zip.NewWriter(w)
+	writer.RegisterCompressor(zip.Deflate, func(out io.Writer) (io.WriteCloser, error) {
+		return flate.NewWriter(out, level)
+	})
+	return writer
+}
+
+// CreateEntry adds a deflated entry with a fixed modification time. Use it in
+// place of zip.Writer.Create to keep archives reproducible.
+func CreateEntry(writer *zip.Writer, name string) (io.Writer, error) {
+	return Options{}.CreateEntry(writer, name)
+}
+
+// CreateEntry adds an entry with a fixed modification time, stored or
+// deflated according to the options. The name is passed through
+// SanitizeEntryName first.
+func (o Options) CreateEntry(writer *zip.Writer, name string) (io.Writer, error) {
+	name, err := SanitizeEntryName(name)
+	if err != nil {
+		return nil, err
+	}
+	return writer.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   o.method(),
+		Modified: entryModTime,
+	})
+}
@@ -0,0 +1,122 @@
+package zipper
+
+import (
+	"encoding/json"
+	"github.com/omariomari2/uncluster/internal/extractor"
+	"github.com/omariomari2/uncluster/internal/fetcher"
+	"time"
+)
+
+// Manifest describes the contents of an export archive. It is written to
+// manifest.json at the archive root.
+type Manifest struct {
+	ExtractedAt  time.Time       `json:"extractedAt"`
+	SourceLength int             `json:"sourceLength"`
+	Files        []ManifestEntry `json:"files"`
+}
+
+// ManifestEntry describes one resource in the export. Fetched resources carry
+// the URL they came from; Status is "ok", "error", or "skipped" for resources
+// the fetch policy left at their original URL.
+type ManifestEntry struct {
+	Path   string `json:"path"`
+	Type   string `json:"type"`
+	Source string `json:"source"` // "document", "inline", or "fetched"
+	URL    string `json:"url,omitempty"`
+	Size   int    `json:"size"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// BuildManifest lists every resource of an extraction, including external
+// resources whose download failed.
+func BuildManifest(extracted *extractor.ExtractedContent) *Manifest {
+	manifest := &Manifest{
+		ExtractedAt:  extracted.ExtractedAt,
+		SourceLength: extracted.SourceLength,
+		Files:        []ManifestEntry{},
+	}
+
+	if extracted.HTML != "" {
+		manifest.Files = append(manifest.Files, ManifestEntry{
+			Path:   "index.html",
+			Type:   "html",
+			Source: "document",
+			Size:   len(extracted.HTML),
+			Status: "ok",
+		})
+	}
+
+	for _, resource := range extracted.InlineCSS {
+		manifest.Files = append(manifest.Files, inlineEntry(resource, "css"))
+	}
+	for _, resource := range extracted.InlineJS {
+		manifest.Files = append(manifest.Files, inlineEntry(resource, "js"))
+	}
+	for _, resource := range extracted.ExternalCSS {
+		manifest.Files = append(manifest.Files, fetchedEntry(resource, "external/css/"))
+	}
+	for _, resource := range extracted.ExternalJS {
+		manifest.Files = append(manifest.Files, fetchedEntry(resource, "external/js/"))
+	}
+
+	for _, asset := range extracted.LocalAssets {
+		if len(asset.Content) == 0 {
+			continue
+		}
+		// Assets without a URL were decoded from data: URIs in the page.
+		source := "fetched"
+		if asset.URL == "" {
+			source = "inline"
+		}
+		manifest.Files = append(manifest.Files, ManifestEntry{
+			Path:   asset.Path,
+			Type:   asset.MIME,
+			Source: source,
+			URL:    asset.URL,
+			Size:   len(asset.Content),
+			Status: "ok",
+		})
+	}
+
+	return manifest
+}
+
+// JSON renders the manifest as indented JSON.
+func (m *Manifest) JSON() ([]byte, error) {
+	return json.MarshalIndent(m, "", "  ")
+}
+
+func inlineEntry(resource extractor.InlineResource, resourceType string) ManifestEntry {
+	return ManifestEntry{
+		Path:   resource.Path,
+		Type:   resourceType,
+		Source: "inline",
+		Size:   len(resource.Content),
+		Status: "ok",
+	}
+}
+
+func fetchedEntry(resource fetcher.FetchedResource, dir string) ManifestEntry {
+	entry := ManifestEntry{
+		Type:   resource.Type,
+		Source: "fetched",
+		URL:    resource.URL,
+		Status: "ok",
+	}
+	if resource.Filename != "" {
+		entry.Path = dir + resource.Filename
+	}
+	if resource.SkipReason != "" {
+		entry.Status = "skipped"
+		entry.Error = resource.SkipReason
+		return entry
+	}
+	if resource.Error != nil {
+		entry.Status = "error"
+		entry.Error = resource.Error.Error()
+		return entry
+	}
+	entry.Size = len(resource.Content)
+	return entry
+}
@@ -0,0 +1,96 @@
+package zipper
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+)
+
+// EntryWriter adds entries to an archive. The zip and tar.gz writers both
+// implement it, so the code that lays out an archive works for either.
+type EntryWriter interface {
+	// CreateEntry starts an entry named name, sanitized with
+	// SanitizeEntryName. The returned writer is valid until the next call.
+	CreateEntry(name string) (io.Writer, error)
+	Close() error
+}
+
+type zipEntryWriter struct {
+	opts   Options
+	writer *zip.Writer
+}
+
+// NewEntryWriter returns an EntryWriter that writes a zip archive to w using
+// the options.
+func (o Options) NewEntryWriter(w io.Writer) EntryWriter {
+	return &zipEntryWriter{opts: o, writer: o.NewWriter(w)}
+}
+
+func (z *zipEntryWriter) CreateEntry(name string) (io.Writer, error) {
+	return z.opts.CreateEntry(z.writer, name)
+}
+
+func (z *zipEntryWriter) Close() error {
+	return z.writer.Close()
+}
+
+// tarGzWriter buffers each entry until the next one starts, since a tar
+// header needs the entry's size up front.
+type tarGzWriter struct {
+	gz      *gzip.Writer
+	tw      *tar.Writer
+	name    string
+	pending bytes.Buffer
+}
+
+// NewTarGzWriter returns an EntryWriter that writes a gzipped tarball to w.
+// Entries get the same fixed modification time as zip entries, so identical
+// inputs give identical archives.
+func NewTarGzWriter(w io.Writer) EntryWriter {
+	gz := gzip.NewWriter(w)
+	return &tarGzWriter{gz: gz, tw: tar.NewWriter(gz)}
+}
+
+func (t *tarGzWriter) CreateEntry(name string) (io.Writer, error) {
+	name, err := SanitizeEntryName(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.flush(); err != nil {
+		return nil, err
+	}
+	t.name = name
+	return &t.pending, nil
+}
+
+func (t *tarGzWriter) flush() error {
+	if t.name == "" {
+		return nil
+	}
+	err := t.tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     t.name,
+		Mode:     0o644,
+		Size:     int64(t.pending.Len()),
+		ModTime:  entryModTime,
+		Format:   tar.FormatPAX,
+	})
+	if err == nil {
+		_, err = t.tw.Write(t.pending.Bytes())
+	}
+	t.name = ""
+	t.pending.Reset()
+	return err
+}
+
+func (t *tarGzWriter) Close() error {
+	if err := t.flush(); err != nil {
+		return err
+	}
+	if err := t.tw.Close(); err != nil {
+		return err
+	}
+	return t.gz.Close()
+}
@@ -1,109 +1,141 @@
 package zipper
 
 import (
-	"archive/zip"
 	"bytes"
+	"fmt"
 	"github.com/omariomari2/uncluster/internal/extractor"
 	"github.com/omariomari2/uncluster/internal/fetcher"
-	"io"
+	"strings"
 )
 
-func CreateZipWithMetadata(html string, inlineCSS, inlineJS []extractor.InlineResource, externalCSS, externalJS []fetcher.FetchedResource, localAssets []extractor.LocalAsset) ([]byte, error) {
+func CreateZipWithMetadata(extracted *extractor.ExtractedContent) ([]byte, error) {
+	return CreateZipWithOptions(extracted, Options{})
+}
+
+// CreateZipWithOptions is CreateZipWithMetadata with control over how the
+// entries are compressed.
+func CreateZipWithOptions(extracted *extractor.ExtractedContent, opts Options) ([]byte, error) {
+	opts, err := opts.Normalize()
+	if err != nil {
+		return nil, err
+	}
+
 	var buf bytes.Buffer
-	writer := zip.NewWriter(&buf)
+	if err := writeExtracted(opts.NewEntryWriter(&buf), extracted); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
 
-	if html != "" {
-		htmlFile, err := writer.Create("index.html")
-		if err != nil {
-			return nil, err
-		}
-		_, err = io.WriteString(htmlFile, html)
-		if err != nil {
-			return nil, err
-		}
+// CreateTarGzWithMetadata is CreateZipWithMetadata for clients that prefer a
+// gzipped tarball. The entries are the same.
+func CreateTarGzWithMetadata(extracted *extractor.ExtractedContent) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := writeExtracted(NewTarGzWriter(&buf), extracted); err != nil {
+		return nil, err
 	}
+	return buf.Bytes(), nil
+}
 
-	if len(inlineCSS) > 0 {
-		for _, resource := range inlineCSS {
-			if resource.Content == "" {
-				continue
-			}
-			cssFile, err := writer.Create(resource.Path)
-			if err != nil {
-				continue
-			}
-			_, err = io.WriteString(cssFile, resource.Content)
-			if err != nil {
-				continue
-			}
+// Entry is one file of an export archive, by its path in the archive.
+type Entry struct {
+	Name    string
+	Content []byte
+}
+
+// Entries lists the files of the export of extracted in archive order: the
+// page, its inline and downloaded resources with placeholders for failed
+// downloads, local assets, errors.txt when a download failed, and
+// manifest.json. Names are passed through SanitizeEntryName, and resources
+// with no name left are dropped, so the list is also safe to write to disk.
+func Entries(extracted *extractor.ExtractedContent) ([]Entry, error) {
+	var entries []Entry
+	add := func(name string, content []byte) {
+		if name, err := SanitizeEntryName(name); err == nil {
+			entries = append(entries, Entry{Name: name, Content: content})
 		}
 	}
 
-	if len(inlineJS) > 0 {
-		for _, resource := range inlineJS {
-			if resource.Content == "" {
-				continue
-			}
-			jsFile, err := writer.Create(resource.Path)
-			if err != nil {
-				continue
-			}
-			_, err = io.WriteString(jsFile, resource.Content)
-			if err != nil {
-				continue
-			}
-		}
+	if extracted.HTML != "" {
+		add("index.html", []byte(extracted.HTML))
 	}
 
-	if len(externalCSS) > 0 {
-		for _, resource := range externalCSS {
-			if resource.Error == nil && resource.Content != "" {
-				path := "external/css/" + resource.Filename
-				cssFile, err := writer.Create(path)
-				if err != nil {
-					continue
-				}
-				_, err = io.WriteString(cssFile, resource.Content)
-				if err != nil {
-					continue
-				}
-			}
+	for _, resource := range extracted.InlineCSS {
+		if resource.Content != "" {
+			add(resource.Path, []byte(resource.Content))
+		}
+	}
+	for _, resource := range extracted.InlineJS {
+		if resource.Content != "" {
+			add(resource.Path, []byte(resource.Content))
 		}
 	}
 
-	if len(externalJS) > 0 {
-		for _, resource := range externalJS {
-			if resource.Error == nil && resource.Content != "" {
-				path := "external/js/" + resource.Filename
-				jsFile, err := writer.Create(path)
-				if err != nil {
-					continue
-				}
-				_, err = io.WriteString(jsFile, resource.Content)
-				if err != nil {
-					continue
-				}
-			}
+	for _, resource := range extracted.ExternalCSS {
+		if content := fetchedContent(resource); resource.Filename != "" && content != "" {
+			add("external/css/"+resource.Filename, []byte(content))
+		}
+	}
+	for _, resource := range extracted.ExternalJS {
+		if content := fetchedContent(resource); resource.Filename != "" && content != "" {
+			add("external/js/"+resource.Filename, []byte(content))
 		}
 	}
 
-	if len(localAssets) > 0 {
-		for _, asset := range localAssets {
-			if len(asset.Content) == 0 {
-				continue
-			}
-			f, err := writer.Create(asset.Path)
-			if err != nil {
-				continue
-			}
-			f.Write(asset.Content)
+	for _, asset := range extracted.LocalAssets {
+		if len(asset.Content) > 0 {
+			add(asset.Path, asset.Content)
 		}
 	}
 
-	err := writer.Close()
+	if failed := extracted.FailedResources(); len(failed) > 0 {
+		add("errors.txt", []byte(fetchErrorReport(failed)))
+	}
+
+	manifest, err := BuildManifest(extracted).JSON()
 	if err != nil {
 		return nil, err
 	}
+	add("manifest.json", manifest)
 
-	return buf.Bytes(), nil
+	return entries, nil
+}
+
+// fetchedContent is what a downloaded resource's file holds: its content, or
+// a placeholder when the download failed.
+func fetchedContent(resource fetcher.FetchedResource) string {
+	if resource.Error != nil {
+		return resource.Placeholder()
+	}
+	return resource.Content
+}
+
+// writeExtracted adds the entries of the extracted page to writer and closes
+// it.
+func writeExtracted(writer EntryWriter, extracted *extractor.ExtractedContent) error {
+	entries, err := Entries(extracted)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		f, err := writer.CreateEntry(entry.Name)
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(entry.Content); err != nil {
+			return err
+		}
+	}
+	return writer.Close()
+}
+
+// fetchErrorReport lists each resource that failed to download, one per line,
+// with the placeholder path it was replaced by.
+func fetchErrorReport(failed []fetcher.FetchedResource) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("%d resource(s) could not be downloaded and were replaced with placeholders:\n\n", len(failed)))
+	for _, resource := range failed {
+		b.WriteString(fmt.Sprintf("%s\t%s\texternal/%s/%s\t%v\n", resource.Type, resource.URL, resource.Type, resource.Filename, resource.Error))
+	}
+	return b.String()
 }
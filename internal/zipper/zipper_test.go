@@ -0,0 +1,220 @@
+package zipper
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/omariomari2/uncluster/internal/extractor"
+	"github.com/omariomari2/uncluster/internal/fetcher"
+)
+
+func readZipEntry(t *testing.T, data []byte, name string) []byte {
+	t.Helper()
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("failed to read zip: %v", err)
+	}
+	for _, f := range reader.File {
+		if f.Name != name {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("failed to open %s: %v", name, err)
+		}
+		defer rc.Close()
+		content, err := io.ReadAll(rc)
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", name, err)
+		}
+		return content
+	}
+	t.Fatalf("zip has no entry %s", name)
+	return nil
+}
+
+func TestCreateZipWithMetadataWritesManifest(t *testing.T) {
+	extracted := &extractor.ExtractedContent{
+		HTML:      "<html></html>",
+		InlineCSS: []extractor.InlineResource{{Path: "inline/style-1.css", Content: "p{}"}},
+		ExternalCSS: []fetcher.FetchedResource{
+			{URL: "https://cdn.example.com/site.css", Content: "body{}", Filename: "site.css", Type: "css"},
+		},
+		ExternalJS: []fetcher.FetchedResource{
+			{URL: "https://cdn.example.com/app.js", Type: "js", Error: errors.New("HTTP 404")},
+		},
+		ExtractedAt:  time.Date(2024, time.May, 1, 12, 0, 0, 0, time.UTC),
+		SourceLength: 42,
+	}
+
+	data, err := CreateZipWithMetadata(extracted)
+	if err != nil {
+		t.Fatalf("CreateZipWithMetadata returned error: %v", err)
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(readZipEntry(t, data, "manifest.json"), &manifest); err != nil {
+		t.Fatalf("manifest.json is not valid JSON: %v", err)
+	}
+
+	if !manifest.ExtractedAt.Equal(extracted.ExtractedAt) || manifest.SourceLength != 42 {
+		t.Fatalf("unexpected manifest header: %+v", manifest)
+	}
+	if len(manifest.Files) != 4 {
+		t.Fatalf("expected 4 manifest entries, got %+v", manifest.Files)
+	}
+
+	css := manifest.Files[2]
+	if css.Path != "external/css/site.css" || css.URL != "https://cdn.example.com/site.css" || css.Size != 6 || css.Status != "ok" {
+		t.Fatalf("unexpected external CSS entry: %+v", css)
+	}
+	js := manifest.Files[3]
+	if js.URL != "https://cdn.example.com/app.js" || js.Status != "error" || js.Error != "HTTP 404" {
+		t.Fatalf("unexpected failed JS entry: %+v", js)
+	}
+}
+
+func TestBuildManifestMarksDataURIImagesInline(t *testing.T) {
+	png := append([]byte("\x89PNG\r\n\x1a\n"), bytes.Repeat([]byte{0x42}, 6000)...)
+	page := `<html><body><img src="data:image/png;base64,` + base64.StdEncoding.EncodeToString(png) + `"></body></html>`
+	extracted, err := extractor.ExtractWithOptions(page, extractor.ExtractOptions{ExternalizeDataURIs: true})
+	if err != nil {
+		t.Fatalf("ExtractWithOptions returned error: %v", err)
+	}
+
+	var image *ManifestEntry
+	manifest := BuildManifest(extracted)
+	for i := range manifest.Files {
+		if manifest.Files[i].Path == "assets/data-image-1.png" {
+			image = &manifest.Files[i]
+		}
+	}
+	if image == nil {
+		t.Fatalf("expected a manifest entry for the externalized image")
+	}
+	if image.Source != "inline" || image.URL != "" || image.Type != "image/png" || image.Size != len(png) {
+		t.Fatalf("unexpected data URI entry: %+v", image)
+	}
+}
+
+func TestCreateZipWithMetadataWritesPlaceholdersForFailedFetches(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	unreachable := server.URL + "/missing.css"
+	server.Close()
+
+	extracted, err := extractor.Extract(`<html><head><link rel="stylesheet" href="` + unreachable + `"></head><body></body></html>`)
+	if err != nil {
+		t.Fatalf("Extract returned error: %v", err)
+	}
+
+	failed := extracted.FailedResources()
+	if len(failed) != 1 {
+		t.Fatalf("expected 1 failed resource, got %d", len(failed))
+	}
+	placeholderPath := "external/css/" + failed[0].Filename
+	if !strings.Contains(extracted.HTML, `href="`+placeholderPath+`"`) {
+		t.Fatalf("expected link to point at %s, got:\n%s", placeholderPath, extracted.HTML)
+	}
+
+	data, err := CreateZipWithMetadata(extracted)
+	if err != nil {
+		t.Fatalf("CreateZipWithMetadata returned error: %v", err)
+	}
+
+	placeholder := string(readZipEntry(t, data, placeholderPath))
+	if !strings.Contains(placeholder, "failed to fetch "+unreachable) {
+		t.Fatalf("unexpected placeholder content: %q", placeholder)
+	}
+	report := string(readZipEntry(t, data, "errors.txt"))
+	if !strings.Contains(report, unreachable) {
+		t.Fatalf("expected errors.txt to list %s, got:\n%s", unreachable, report)
+	}
+}
+
+func TestCreateZipWithOptionsStoresEntries(t *testing.T) {
+	extracted := &extractor.ExtractedContent{HTML: strings.Repeat("<p>repeated text</p>\n", 200)}
+
+	data, err := CreateZipWithOptions(extracted, Options{Store: true})
+	if err != nil {
+		t.Fatalf("CreateZipWithOptions returned error: %v", err)
+	}
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("failed to read zip: %v", err)
+	}
+	for _, f := range reader.File {
+		if f.Method != zip.Store || f.CompressedSize64 != f.UncompressedSize64 {
+			t.Fatalf("expected %s to be stored uncompressed, got method %d (%d -> %d bytes)", f.Name, f.Method, f.UncompressedSize64, f.CompressedSize64)
+		}
+	}
+	if got := readZipEntry(t, data, "index.html"); string(got) != extracted.HTML {
+		t.Fatalf("stored index.html does not round-trip")
+	}
+
+	deflated, err := CreateZipWithOptions(extracted, Options{Level: 9})
+	if err != nil {
+		t.Fatalf("CreateZipWithOptions returned error: %v", err)
+	}
+	if len(deflated) >= len(data) {
+		t.Fatalf("expected deflated archive (%d bytes) to be smaller than stored (%d bytes)", len(deflated), len(data))
+	}
+
+	if _, err := CreateZipWithOptions(extracted, Options{Level: 12}); err == nil {
+		t.Fatal("expected an error for an out-of-range level")
+	}
+}
+
+func TestSanitizeEntryName(t *testing.T) {
+	cases := map[string]string{
+		"index.html":                     "index.html",
+		"external/css/../../../evil.css": "external/css/evil.css",
+		"/etc/passwd":                    "etc/passwd",
+		`assets\..\..\win.ini`:           "assets/win.ini",
+		"C:/Windows/system.ini":          "Windows/system.ini",
+		"./a//b/./c.txt":                 "a/b/c.txt",
+	}
+	for input, want := range cases {
+		got, err := SanitizeEntryName(input)
+		if err != nil || got != want {
+			t.Fatalf("SanitizeEntryName(%q) = %q, %v; want %q", input, got, err, want)
+		}
+	}
+	for _, input := range []string{"", "..", "../..", "/"} {
+		if _, err := SanitizeEntryName(input); err == nil {
+			t.Fatalf("expected an error for %q", input)
+		}
+	}
+}
+
+func TestCreateZipWithMetadataNeutralizesMaliciousFilenames(t *testing.T) {
+	extracted := &extractor.ExtractedContent{
+		HTML:        "<p>hi</p>",
+		ExternalCSS: []fetcher.FetchedResource{{URL: "https://example.com/x.css", Filename: "../../../evil.css", Content: "p{}", Type: "css"}},
+	}
+
+	data, err := CreateZipWithMetadata(extracted)
+	if err != nil {
+		t.Fatalf("CreateZipWithMetadata returned error: %v", err)
+	}
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("failed to read zip: %v", err)
+	}
+	for _, f := range reader.File {
+		if strings.Contains(f.Name, "..") || strings.HasPrefix(f.Name, "/") {
+			t.Fatalf("unsafe entry name %q", f.Name)
+		}
+	}
+	if got := readZipEntry(t, data, "external/css/evil.css"); string(got) != "p{}" {
+		t.Fatalf("expected the stylesheet under external/css, got %q", got)
+	}
+}
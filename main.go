@@ -1,33 +1,53 @@
 package main
 
 import (
-	"archive/zip"
+	"bufio"
 	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
+	"net/url"
 	"path/filepath"
+	"runtime"
+	"runtime/debug"
 
 	"github.com/omariomari2/uncluster/internal/analyzer"
 	"github.com/omariomari2/uncluster/internal/bundle"
 	"github.com/omariomari2/uncluster/internal/converter"
 	"github.com/omariomari2/uncluster/internal/extractor"
+	"github.com/omariomari2/uncluster/internal/fetcher"
 	"github.com/omariomari2/uncluster/internal/formatter"
+	"github.com/omariomari2/uncluster/internal/htmlparse"
+	"github.com/omariomari2/uncluster/internal/logging"
+	"github.com/omariomari2/uncluster/internal/middleware"
 	"github.com/omariomari2/uncluster/internal/nodejs"
 	"github.com/omariomari2/uncluster/internal/scraper"
 	"github.com/omariomari2/uncluster/internal/zipper"
+	"github.com/omariomari2/uncluster/pkg/htmlfmt"
 	"os"
+	"os/signal"
 	"strings"
+	"sync/atomic"
+	"syscall"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/fiber/v2/middleware/logger"
 	"github.com/gofiber/fiber/v2/middleware/recover"
+	"github.com/gofiber/fiber/v2/utils"
 )
 
 func main() {
+	logging.Setup()
+	fetcher.SetPolicy(fetcher.PolicyFromEnv())
+
 	app := fiber.New(fiber.Config{
-		BodyLimit: 50 * 1024 * 1024, // 50 MB — allows large ZIP uploads and scraped pages
+		BodyLimit: middleware.SizeLimitFromEnv("MAX_BODY_BYTES", defaultMaxBodyBytes),
 		ErrorHandler: func(c *fiber.Ctx, err error) error {
 			code := fiber.StatusInternalServerError
 			if e, ok := err.(*fiber.Error); ok {
@@ -39,75 +59,1208 @@ func main() {
 		},
 	})
 
-	app.Use(logger.New())
-	app.Use(recover.New())
-	app.Use(cors.New(cors.Config{
-		AllowOrigins: "*",
-		AllowMethods: "GET,POST,PUT,DELETE,OPTIONS",
-		AllowHeaders: "Origin,Content-Type,Accept,Authorization",
-	}))
+	app.Use(middleware.RequestID())
+	app.Use(logger.New(logger.Config{
+		Format: "${time} | ${locals:requestid} | ${status} | ${latency} | ${ip} | ${method} | ${path} | ${error}\n",
+	}))
+	app.Use(recover.New())
+	app.Use(cors.New(cors.Config{
+		AllowOrigins:  "*",
+		AllowMethods:  "GET,POST,PUT,DELETE,OPTIONS",
+		AllowHeaders:  "Origin,Content-Type,Content-Encoding,Accept,Authorization,X-API-Key,X-Request-ID",
+		ExposeHeaders: "Content-Disposition,X-Request-ID,X-Fetch-Errors,X-Resources-Total,X-Resources-Failed,Retry-After",
+	}))
+
+	setupRoutes(app)
+
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "3000"
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+		sig := <-signals
+
+		slog.Info("shutting down", "signal", sig.String(), "gracePeriod", shutdownGracePeriod)
+		ready.Store(false)
+		if err := app.ShutdownWithTimeout(shutdownGracePeriod); err != nil {
+			slog.Error("shutdown did not complete cleanly", "error", err)
+		}
+	}()
+
+	if err := app.Listen(":" + port); err != nil {
+		slog.Error("server failed to start", "error", err)
+		os.Exit(1)
+	}
+
+	// Listen returns as soon as the listener closes; wait for in-flight
+	// requests to drain.
+	<-stopped
+	slog.Info("server stopped")
+}
+
+// shutdownGracePeriod is how long in-flight requests, such as exports still
+// fetching external resources, get to finish after SIGINT or SIGTERM.
+const shutdownGracePeriod = 30 * time.Second
+
+type FormatRequest struct {
+	HTML string `json:"html" validate:"required"`
+	// EncodeEntities writes non-ASCII characters as HTML entities. Only
+	// /api/format reads it.
+	EncodeEntities bool `json:"encodeEntities"`
+	// Lenient formats elements where they were written instead of where the
+	// HTML parser would move them. Only /api/format reads it.
+	Lenient bool `json:"lenient"`
+	// VoidStyle is "xhtml" (default) for <br /> or "html" for <br>. Only
+	// /api/format reads it.
+	VoidStyle string `json:"voidStyle"`
+	// CSSFileName and JSFileName name the extracted inline files. The extract
+	// and export routes read them (/api/export-file takes them as form fields).
+	CSSFileName string `json:"cssFileName"`
+	JSFileName  string `json:"jsFileName"`
+	// KeepExternal links external CSS, JS, and icons at their original URLs
+	// instead of downloading them. The extract and export routes read it.
+	KeepExternal bool `json:"keepExternal"`
+	// ExtractCSS and ExtractJS default to true; setting one to false leaves
+	// that kind of resource in the HTML untouched. The extract and export
+	// routes read them.
+	ExtractCSS *bool `json:"extractCss"`
+	ExtractJS  *bool `json:"extractJs"`
+	// DedupeCSS drops rule blocks repeated in a later stylesheet. The
+	// extract and export routes read it.
+	DedupeCSS bool `json:"dedupeCss"`
+	// HashFilenames names downloaded CSS and JS after a hash of their
+	// content. The extract and export routes read it.
+	HashFilenames bool `json:"hashFilenames"`
+}
+
+// extractOptions returns the extractor options the request's fields select.
+func (r FormatRequest) extractOptions() extractor.ExtractOptions {
+	return extractor.ExtractOptions{
+		CSSFileName:   r.CSSFileName,
+		JSFileName:    r.JSFileName,
+		KeepExternal:  r.KeepExternal,
+		SkipCSS:       r.ExtractCSS != nil && !*r.ExtractCSS,
+		SkipJS:        r.ExtractJS != nil && !*r.ExtractJS,
+		DedupeCSS:     r.DedupeCSS,
+		HashFilenames: r.HashFilenames,
+	}
+}
+
+type ExportNodeJSRequest struct {
+	HTML           string `json:"html" validate:"required"`
+	PackageManager string `json:"packageManager"`
+	Language       string `json:"language"`
+	Tailwind       *bool  `json:"tailwind"`
+	ComponentName  string `json:"componentName"`
+	IncludeDocker  bool   `json:"includeDocker"`
+	// ExternalizeDataURIs writes data URI images larger than
+	// DataURIThreshold bytes to public/assets instead of leaving them inline.
+	ExternalizeDataURIs bool `json:"externalizeDataUris"`
+	// DataURIThreshold is the size in bytes above which ExternalizeDataURIs
+	// moves a data URI image to a file. Zero uses the 4 KB default.
+	DataURIThreshold int `json:"dataUriThreshold"`
+	// KeepExternal links external CSS and JS at their original URLs instead
+	// of downloading them into the project.
+	KeepExternal bool `json:"keepExternal"`
+	// DedupeCSS drops rule blocks repeated in a later stylesheet.
+	DedupeCSS bool `json:"dedupeCss"`
+	// HashFilenames names downloaded CSS and JS after a hash of their content.
+	HashFilenames bool `json:"hashFilenames"`
+	// ReactVersion, ViteVersion, TypeScriptVersion, and ReactRouterVersion
+	// override the package.json versions, e.g. "^19.0.0". Empty keeps the
+	// defaults.
+	ReactVersion       string `json:"reactVersion"`
+	ViteVersion        string `json:"viteVersion"`
+	TypeScriptVersion  string `json:"typescriptVersion"`
+	ReactRouterVersion string `json:"reactRouterVersion"`
+	// Minify strips comments and whitespace from the page's CSS.
+	Minify bool `json:"minify"`
+	// Router sets up react-router-dom with a route per major section when
+	// the page has two or more.
+	Router bool `json:"router"`
+	// ComponentsDir, StylesDir, and ScriptsDir rename the folders under src/
+	// that components, stylesheets, and scripts go to, e.g. "assets/styles".
+	// Empty keeps components, styles, and scripts.
+	ComponentsDir string `json:"componentsDir"`
+	StylesDir     string `json:"stylesDir"`
+	ScriptsDir    string `json:"scriptsDir"`
+}
+
+type ExportEJSRequest struct {
+	HTML          string   `json:"html" validate:"required"`
+	MaxDepth      int      `json:"maxDepth"`
+	ExtraKeywords []string `json:"extraKeywords"`
+	MinTextLength int      `json:"minTextLength"`
+	// RootDepth is how many single-child wrapper elements are descended
+	// through to find the content root. Zero keeps the default of 4 and a
+	// negative value keeps the body.
+	RootDepth int `json:"rootDepth"`
+	// Exclude adds elements that never become partials to the default
+	// exclusions (sr-only, ad, and tracking containers).
+	Exclude analyzer.Exclusions `json:"exclude"`
+	// KeepExternal links external CSS and JS at their original URLs.
+	KeepExternal bool `json:"keepExternal"`
+	// DedupeCSS drops rule blocks repeated in a later stylesheet.
+	DedupeCSS bool `json:"dedupeCss"`
+	// HashFilenames names downloaded CSS and JS after a hash of their content.
+	HashFilenames bool `json:"hashFilenames"`
+}
+
+type ExportSvelteRequest struct {
+	HTML           string `json:"html" validate:"required"`
+	PackageManager string `json:"packageManager"`
+	// KeepExternal links external CSS and JS at their original URLs.
+	KeepExternal bool `json:"keepExternal"`
+	// DedupeCSS drops rule blocks repeated in a later stylesheet.
+	DedupeCSS bool `json:"dedupeCss"`
+	// HashFilenames names downloaded CSS and JS after a hash of their content.
+	HashFilenames bool `json:"hashFilenames"`
+}
+
+type ConvertRequest struct {
+	HTML              string `json:"html" validate:"required"`
+	ComponentName     string `json:"componentName"`
+	Language          string `json:"language"`
+	WrapperMode       string `json:"wrapperMode"`
+	GenerateProps     bool   `json:"generateProps"`
+	KeepDocumentShell bool   `json:"keepDocumentShell"`
+	StyleStrategy     string `json:"styleStrategy"`
+	// Clean and StripAttributes remove editor-injected attributes; see
+	// htmlfmt.ConvertOptions.
+	Clean           bool     `json:"clean"`
+	StripAttributes []string `json:"stripAttributes"`
+	// Indent is "2" (default), "4", or "tab".
+	Indent string `json:"indent"`
+	// SingleFile renders the page's <style> blocks inside the component
+	// instead of importing a stylesheet. Only /api/convert reads it.
+	SingleFile bool `json:"singleFile"`
+	// Exclude adds elements that are never suggested as components to the
+	// default exclusions. Only /api/analyze reads it.
+	Exclude analyzer.Exclusions `json:"exclude"`
+}
+
+// converterOptions returns the request's conversion options, validated.
+func (req ConvertRequest) converterOptions() (htmlfmt.ConvertOptions, error) {
+	opts := htmlfmt.ConvertOptions{
+		ComponentName:     req.ComponentName,
+		Language:          req.Language,
+		WrapperMode:       req.WrapperMode,
+		GenerateProps:     req.GenerateProps,
+		KeepDocumentShell: req.KeepDocumentShell,
+		StyleStrategy:     req.StyleStrategy,
+		Clean:             req.Clean,
+		StripAttributes:   req.StripAttributes,
+		Indent:            req.Indent,
+		SingleFile:        req.SingleFile,
+	}
+	return opts, opts.Validate()
+}
+
+// PreviewResponse carries a conversion and its reverse render for
+// /api/convert-preview. Original and HTML are formatted the same way, so
+// they can be diffed line by line.
+type PreviewResponse struct {
+	Success  bool   `json:"success"`
+	Original string `json:"original,omitempty"`
+	JSX      string `json:"jsx,omitempty"`
+	HTML     string `json:"html,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// ConvertResponse is Response plus the CSS Module source produced by the
+// cssModules style strategy.
+type ConvertResponse struct {
+	Success     bool   `json:"success"`
+	Data        string `json:"data,omitempty"`
+	StyleModule string `json:"styleModule,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// FormatResponse is Response plus a warning when the parser moved elements
+// the input had misplaced, such as a <div> inside <head>.
+type FormatResponse struct {
+	Success bool   `json:"success"`
+	Data    string `json:"data,omitempty"`
+	Warning string `json:"warning,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+type Response struct {
+	Success bool   `json:"success"`
+	Data    string `json:"data,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+type FormatBatchRequest struct {
+	Documents []string `json:"documents"`
+}
+
+type FormatBatchResponse struct {
+	Success bool       `json:"success"`
+	Results []Response `json:"results,omitempty"`
+	Error   string     `json:"error,omitempty"`
+}
+
+type ComponentResponse struct {
+	Success     bool                           `json:"success"`
+	Suggestions []analyzer.ComponentSuggestion `json:"suggestions,omitempty"`
+	Groups      []analyzer.SuggestionGroup     `json:"groups,omitempty"`
+	Error       string                         `json:"error,omitempty"`
+}
+
+// ComponentizeResponse flattens htmlfmt.Componentization into the response
+// as main, components, and suggestions.
+type ComponentizeResponse struct {
+	Success bool `json:"success"`
+	*htmlfmt.Componentization
+	Error string `json:"error,omitempty"`
+}
+
+type PatternsResponse struct {
+	Success  bool                   `json:"success"`
+	Patterns []analyzer.PatternStat `json:"patterns,omitempty"`
+	Error    string                 `json:"error,omitempty"`
+}
+
+// ExtractResponse is the JSON form of an extraction: the rewritten HTML, the
+// combined inline CSS and JS, and the fetch status of each external resource.
+type ExtractResponse struct {
+	Success     bool               `json:"success"`
+	HTML        string             `json:"html"`
+	CSS         string             `json:"css"`
+	JS          string             `json:"js"`
+	ExternalCSS []ExternalResource `json:"externalCSS"`
+	ExternalJS  []ExternalResource `json:"externalJS"`
+	// DuplicateIDs maps each id used by more than one element to its count.
+	// The HTML is returned unchanged; it is a warning to fix before export.
+	DuplicateIDs map[string]int `json:"duplicateIds,omitempty"`
+	Error        string         `json:"error,omitempty"`
+}
+
+// ExportSummary is the ?format=json response of the export routes: the
+// manifest that would be written into extracted.zip, with per-file status.
+type ExportSummary struct {
+	Success         bool             `json:"success"`
+	ResourcesTotal  int              `json:"resourcesTotal"`
+	ResourcesFailed int              `json:"resourcesFailed"`
+	Manifest        *zipper.Manifest `json:"manifest"`
+}
+
+// ZipResponse carries a generated archive as base64 for clients that cannot
+// handle a binary response. The export routes return it instead of the zip
+// when the request accepts application/json or passes ?format=base64.
+type ZipResponse struct {
+	Success   bool   `json:"success"`
+	Filename  string `json:"filename"`
+	ZipBase64 string `json:"zipBase64"`
+	Size      int    `json:"size"`
+}
+
+type ExternalResource struct {
+	URL      string `json:"url"`
+	Filename string `json:"filename"`
+	Success  bool   `json:"success"`
+	Error    string `json:"error,omitempty"`
+	// Skipped is set, with the reason, when the fetch policy left the
+	// resource at its original URL.
+	Skipped string `json:"skipped,omitempty"`
+}
+
+// Default per-IP rate limits. Both can be overridden (or disabled with a rate
+// of 0) through the RATE_LIMIT_* and RATE_LIMIT_EXPORT_* environment variables.
+var (
+	defaultRateLimit       = middleware.RateLimit{PerMinute: 120, Burst: 30}
+	defaultExportRateLimit = middleware.RateLimit{PerMinute: 20, Burst: 5}
+)
+
+// Default size limits. MAX_BODY_BYTES caps the raw request body (50 MB allows
+// large ZIP uploads and scraped pages); MAX_HTML_BYTES caps the HTML a handler
+// will parse, since parsing, formatting, and converting multiply its memory use.
+const (
+	defaultMaxBodyBytes = 50 * 1024 * 1024
+	defaultMaxHTMLBytes = 10 * 1024 * 1024
+)
+
+var maxHTMLBytes = defaultMaxHTMLBytes
+
+// defaultExportTimeout bounds the resource downloads of one export, so a page
+// linking a hanging CDN can't hold the request for the per-fetch timeout
+// times the number of resources. EXPORT_TIMEOUT overrides it.
+const defaultExportTimeout = 30 * time.Second
+
+var exportTimeout = defaultExportTimeout
+
+// exportTimeoutFromEnv reads EXPORT_TIMEOUT as a Go duration such as "45s" or
+// "2m", falling back to defaultExportTimeout when it is unset or invalid.
+func exportTimeoutFromEnv() time.Duration {
+	if d, err := time.ParseDuration(strings.TrimSpace(os.Getenv("EXPORT_TIMEOUT"))); err == nil && d > 0 {
+		return d
+	}
+	return defaultExportTimeout
+}
+
+// htmlTooLargeError is the error message for HTML over MAX_HTML_BYTES.
+func htmlTooLargeError() string {
+	return fmt.Sprintf("HTML content is too large (limit is %d bytes)", maxHTMLBytes)
+}
+
+// htmlTooLarge sends the 413 response for HTML over MAX_HTML_BYTES. Every
+// handler that takes HTML in its request checks the limit before parsing.
+func htmlTooLarge(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusRequestEntityTooLarge).JSON(Response{
+		Success: false,
+		Error:   htmlTooLargeError(),
+	})
+}
+
+func setupRoutes(app *fiber.App) {
+	maxHTMLBytes = middleware.SizeLimitFromEnv("MAX_HTML_BYTES", defaultMaxHTMLBytes)
+	exportTimeout = exportTimeoutFromEnv()
+
+	api := app.Group("/api")
+	api.Use(middleware.APIKeyAuth(middleware.APIKeysFromEnv(), "/api/health", "/api/ready"))
+	api.Use(middleware.RateLimiter(middleware.RateLimitFromEnv("RATE_LIMIT", defaultRateLimit), "/api/health", "/api/ready"))
+	// Compressed bodies may expand to at most MAX_BODY_BYTES, the same cap
+	// Fiber applies to uncompressed ones.
+	api.Use(middleware.DecompressBody(middleware.SizeLimitFromEnv("MAX_BODY_BYTES", defaultMaxBodyBytes)))
+
+	// heavy is an additional, stricter limit for routes that fetch remote
+	// resources or build archives.
+	heavy := middleware.RateLimiter(middleware.RateLimitFromEnv("RATE_LIMIT_EXPORT", defaultExportRateLimit))
+
+	api.Post("/format", handleFormat)
+
+	api.Post("/convert", handleConvert)
+	api.Post("/convert-preview", handleConvertPreview)
+
+	api.Post("/analyze", handleAnalyze)
+	api.Post("/componentize", handleComponentize)
+	api.Post("/patterns", handlePatterns)
+
+	api.Post("/format-batch", handleFormatBatch)
+	api.Post("/format-file", handleFormatFile)
+	api.Post("/format-url", heavy, handleFormatURL)
+
+	api.Post("/extract", heavy, handleExtract)
+	api.Post("/export", heavy, handleExport)
+	api.Post("/export-file", heavy, handleExportFile)
+
+	api.Post("/export-nodejs", heavy, handleExportNodeJS)
+
+	api.Post("/export-nodejs-ejs", heavy, handleExportNodeJSEJS)
+	api.Post("/export-ejs", heavy, handleExportNodeJSEJS)
+
+	api.Post("/export-static", heavy, handleExportStatic)
+	api.Post("/export-svelte", heavy, handleExportSvelte)
+
+	api.Post("/bundle-zip", heavy, handleBundleZip)
+
+	api.Post("/scrape", heavy, handleScrape)
+	api.Post("/scrape-nodejs", heavy, handleScrapeNodeJS)
+	api.Post("/scrape-nodejs-ejs", heavy, handleScrapeNodeJSEJS)
+
+	api.Get("/health", handleHealth)
+	api.Get("/ready", handleReady)
+
+	app.Hooks().OnListen(func(fiber.ListenData) error {
+		ready.Store(true)
+		return nil
+	})
+
+	app.Static("/", "./dist")
+}
+
+func handleFormat(c *fiber.Ctx) error {
+	var req FormatRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(Response{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	if strings.TrimSpace(req.HTML) == "" {
+		return c.Status(400).JSON(Response{
+			Success: false,
+			Error:   "HTML content is required",
+		})
+	}
+
+	if len(req.HTML) > maxHTMLBytes {
+		return htmlTooLarge(c)
+	}
+
+	opts := htmlfmt.FormatOptions{
+		EncodeEntities: req.EncodeEntities,
+		Lenient:        req.Lenient,
+		VoidStyle:      req.VoidStyle,
+	}
+	if err := opts.Validate(); err != nil {
+		return c.Status(400).JSON(Response{
+			Success: false,
+			Error:   err.Error(),
+		})
+	}
+
+	formatted, err := htmlfmt.Format(req.HTML, opts)
+	if err != nil {
+		return c.Status(500).JSON(Response{
+			Success: false,
+			Error:   err.Error(),
+		})
+	}
+
+	resp := FormatResponse{
+		Success: true,
+		Data:    formatted,
+	}
+	if !req.Lenient {
+		if restructured, err := formatter.Restructured(req.HTML); err == nil && restructured {
+			resp.Warning = restructuredWarning
+		}
+	}
+	return c.JSON(resp)
+}
+
+// restructuredWarning tells /api/format clients that the output's structure
+// differs from the input's.
+const restructuredWarning = "the HTML parser moved misplaced elements, so the output structure differs from the input; set lenient to keep elements where they were written"
+
+// Limits for /api/format-batch.
+const (
+	maxBatchDocuments  = 100
+	maxBatchTotalBytes = 10 * 1024 * 1024
+)
+
+// handleFormatBatch formats each document independently; a document that
+// fails to format gets its own error entry without failing the batch.
+func handleFormatBatch(c *fiber.Ctx) error {
+	var req FormatBatchRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(FormatBatchResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	if len(req.Documents) == 0 {
+		return c.Status(400).JSON(FormatBatchResponse{
+			Success: false,
+			Error:   "At least one document is required",
+		})
+	}
+	if len(req.Documents) > maxBatchDocuments {
+		return c.Status(400).JSON(FormatBatchResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Too many documents: %d (maximum %d)", len(req.Documents), maxBatchDocuments),
+		})
+	}
+	total := 0
+	for _, doc := range req.Documents {
+		total += len(doc)
+	}
+	if total > maxBatchTotalBytes {
+		return c.Status(400).JSON(FormatBatchResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Documents exceed the %d MB total limit", maxBatchTotalBytes/(1024*1024)),
+		})
+	}
+
+	results := make([]Response, len(req.Documents))
+	for i, doc := range req.Documents {
+		if strings.TrimSpace(doc) == "" {
+			results[i] = Response{Success: false, Error: "HTML content is required"}
+			continue
+		}
+		if len(doc) > maxHTMLBytes {
+			results[i] = Response{Success: false, Error: htmlTooLargeError()}
+			continue
+		}
+		formatted, err := htmlfmt.Format(doc, htmlfmt.FormatOptions{})
+		if err != nil {
+			results[i] = Response{Success: false, Error: err.Error()}
+			continue
+		}
+		results[i] = Response{Success: true, Data: formatted}
+	}
+
+	return c.JSON(FormatBatchResponse{
+		Success: true,
+		Results: results,
+	})
+}
+
+// componentNameForURL names the main component of a scraped page after its
+// site, e.g. https://www.example.com/about becomes "ExamplePage".
+func componentNameForURL(rawURL string) string {
+	parsed, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || parsed.Hostname() == "" {
+		return converter.DefaultComponentName
+	}
+	site := strings.Split(strings.TrimPrefix(parsed.Hostname(), "www."), ".")[0]
+	return converter.ComponentNameFrom(site + " page")
+}
+
+// readUploadedHTML reads the multipart "file" field as an HTML document. The
+// returned error carries the status to respond with: 413 for a file over
+// MAX_HTML_BYTES, 400 otherwise.
+func readUploadedHTML(c *fiber.Ctx) (string, *fiber.Error) {
+	file, err := c.FormFile("file")
+	if err != nil {
+		return "", fiber.NewError(fiber.StatusBadRequest, "HTML file is required")
+	}
+
+	name := strings.ToLower(file.Filename)
+	contentType := strings.ToLower(file.Header.Get("Content-Type"))
+	if !strings.HasSuffix(name, ".html") && !strings.HasSuffix(name, ".htm") && !strings.HasPrefix(contentType, "text/html") {
+		return "", fiber.NewError(fiber.StatusBadRequest, "Only .html files are accepted")
+	}
+	if file.Size > int64(maxHTMLBytes) {
+		return "", fiber.NewError(fiber.StatusRequestEntityTooLarge, htmlTooLargeError())
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return "", fiber.NewError(fiber.StatusBadRequest, "Failed to open uploaded file")
+	}
+	defer src.Close()
+
+	data, err := io.ReadAll(io.LimitReader(src, int64(maxHTMLBytes)+1))
+	if err != nil {
+		return "", fiber.NewError(fiber.StatusBadRequest, "Failed to read uploaded file")
+	}
+	if len(data) > maxHTMLBytes {
+		return "", fiber.NewError(fiber.StatusRequestEntityTooLarge, htmlTooLargeError())
+	}
+	if strings.TrimSpace(string(data)) == "" {
+		return "", fiber.NewError(fiber.StatusBadRequest, "Uploaded HTML file is empty")
+	}
+
+	return htmlparse.ToUTF8(string(data), file.Header.Get("Content-Type")), nil
+}
+
+func handleFormatFile(c *fiber.Ctx) error {
+	htmlContent, uploadErr := readUploadedHTML(c)
+	if uploadErr != nil {
+		return c.Status(uploadErr.Code).JSON(Response{Success: false, Error: uploadErr.Message})
+	}
+
+	formatted, err := htmlfmt.Format(htmlContent, htmlfmt.FormatOptions{})
+	if err != nil {
+		return c.Status(500).JSON(Response{
+			Success: false,
+			Error:   err.Error(),
+		})
+	}
+
+	return c.JSON(Response{
+		Success: true,
+		Data:    formatted,
+	})
+}
+
+// handleFormatURL fetches a live page, resolves its relative resource URLs
+// against the page URL, and returns the formatted HTML.
+func handleFormatURL(c *fiber.Ctx) error {
+	var req ScrapeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(Response{Success: false, Error: "Invalid request body"})
+	}
+	pageURL := strings.TrimSpace(req.URL)
+	if pageURL == "" {
+		return c.Status(400).JSON(Response{Success: false, Error: "URL is required"})
+	}
+	if !strings.HasPrefix(pageURL, "http://") && !strings.HasPrefix(pageURL, "https://") {
+		return c.Status(400).JSON(Response{Success: false, Error: "invalid URL: must start with http:// or https://"})
+	}
+
+	pageHTML, err := fetcher.FetchPageContext(c.UserContext(), pageURL, maxHTMLBytes)
+	switch {
+	case errors.Is(err, fetcher.ErrPageTooLarge):
+		return htmlTooLarge(c)
+	case errors.Is(err, fetcher.ErrBlockedURL):
+		return c.Status(fiber.StatusForbidden).JSON(Response{Success: false, Error: "failed to fetch page: " + err.Error()})
+	case err != nil:
+		return c.Status(500).JSON(Response{Success: false, Error: "failed to fetch page: " + err.Error()})
+	}
+
+	resolved, err := extractor.ResolveRelativeURLs(pageHTML, pageURL)
+	if err != nil {
+		return c.Status(500).JSON(Response{Success: false, Error: err.Error()})
+	}
+
+	formatted, err := htmlfmt.Format(resolved, htmlfmt.FormatOptions{})
+	if err != nil {
+		return c.Status(500).JSON(Response{Success: false, Error: err.Error()})
+	}
+
+	return c.JSON(Response{
+		Success: true,
+		Data:    formatted,
+	})
+}
+
+func handleExportFile(c *fiber.Ctx) error {
+	htmlContent, uploadErr := readUploadedHTML(c)
+	if uploadErr != nil {
+		return c.Status(uploadErr.Code).JSON(Response{Success: false, Error: uploadErr.Message})
+	}
+
+	return sendExtractedZip(c, htmlContent, extractor.ExtractOptions{
+		CSSFileName:   c.FormValue("cssFileName"),
+		JSFileName:    c.FormValue("jsFileName"),
+		KeepExternal:  c.FormValue("keepExternal") == "true",
+		SkipCSS:       c.FormValue("extractCss") == "false",
+		SkipJS:        c.FormValue("extractJs") == "false",
+		DedupeCSS:     c.FormValue("dedupeCss") == "true",
+		HashFilenames: c.FormValue("hashFilenames") == "true",
+	})
+}
+
+func handleConvert(c *fiber.Ctx) error {
+	var req ConvertRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(Response{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	if strings.TrimSpace(req.HTML) == "" {
+		return c.Status(400).JSON(Response{
+			Success: false,
+			Error:   "HTML content is required",
+		})
+	}
+
+	if len(req.HTML) > maxHTMLBytes {
+		return htmlTooLarge(c)
+	}
+
+	opts, err := req.converterOptions()
+	if err != nil {
+		return c.Status(400).JSON(Response{
+			Success: false,
+			Error:   err.Error(),
+		})
+	}
+
+	component, err := htmlfmt.Convert(req.HTML, opts)
+	if err != nil {
+		return c.Status(500).JSON(Response{
+			Success: false,
+			Error:   err.Error(),
+		})
+	}
+
+	return c.JSON(ConvertResponse{
+		Success:     true,
+		Data:        component.Code,
+		StyleModule: component.StyleModule,
+	})
+}
+
+// handleConvertPreview converts the HTML like /api/convert and renders the
+// component back to HTML with converter.PreviewHTML, so the frontend can diff
+// the round trip against the original. generateProps is rejected, since the
+// preview cannot expand the list it renders from the items prop.
+func handleConvertPreview(c *fiber.Ctx) error {
+	var req ConvertRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(PreviewResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	if strings.TrimSpace(req.HTML) == "" {
+		return c.Status(400).JSON(PreviewResponse{
+			Success: false,
+			Error:   "HTML content is required",
+		})
+	}
+
+	if len(req.HTML) > maxHTMLBytes {
+		return htmlTooLarge(c)
+	}
+
+	opts, err := req.converterOptions()
+	if err != nil {
+		return c.Status(400).JSON(PreviewResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+	}
+
+	if opts.GenerateProps {
+		return c.Status(400).JSON(PreviewResponse{
+			Success: false,
+			Error:   "generateProps is not supported by the preview, which cannot render the items prop's list",
+		})
+	}
+
+	component, err := htmlfmt.Convert(req.HTML, opts)
+	if err != nil {
+		return c.Status(500).JSON(PreviewResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+	}
+
+	original, err := htmlfmt.Format(req.HTML, htmlfmt.FormatOptions{})
+	if err != nil {
+		return c.Status(500).JSON(PreviewResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+	}
+
+	preview, err := converter.PreviewHTML(component.Code)
+	if err != nil {
+		return c.Status(500).JSON(PreviewResponse{
+			Success: false,
+			Error:   fmt.Sprintf("failed to render the component back to HTML: %v", err),
+		})
+	}
+
+	return c.JSON(PreviewResponse{
+		Success:  true,
+		Original: original,
+		JSX:      component.Code,
+		HTML:     preview,
+	})
+}
+
+func handleComponentize(c *fiber.Ctx) error {
+	var req ConvertRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(ComponentizeResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	if strings.TrimSpace(req.HTML) == "" {
+		return c.Status(400).JSON(ComponentizeResponse{
+			Success: false,
+			Error:   "HTML content is required",
+		})
+	}
+
+	if len(req.HTML) > maxHTMLBytes {
+		return htmlTooLarge(c)
+	}
+
+	opts, err := req.converterOptions()
+	if err != nil {
+		return c.Status(400).JSON(ComponentizeResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+	}
+
+	result, err := htmlfmt.Componentize(req.HTML, opts)
+	if err != nil {
+		return c.Status(500).JSON(ComponentizeResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+	}
+
+	return c.JSON(ComponentizeResponse{
+		Success:          true,
+		Componentization: result,
+	})
+}
+
+func handleAnalyze(c *fiber.Ctx) error {
+	var req ConvertRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(ComponentResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	if strings.TrimSpace(req.HTML) == "" {
+		return c.Status(400).JSON(ComponentResponse{
+			Success: false,
+			Error:   "HTML content is required",
+		})
+	}
+
+	if len(req.HTML) > maxHTMLBytes {
+		return htmlTooLarge(c)
+	}
+
+	suggestions, err := analyzer.AnalyzeComponentsWithOptions(req.HTML, analyzer.AnalyzeOptions{Exclude: req.Exclude})
+	if err != nil {
+		return c.Status(500).JSON(ComponentResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+	}
+
+	if c.QueryBool("grouped") {
+		return c.JSON(ComponentResponse{
+			Success: true,
+			Groups:  analyzer.GroupSuggestions(suggestions),
+		})
+	}
+
+	return c.JSON(ComponentResponse{
+		Success:     true,
+		Suggestions: suggestions,
+	})
+}
+
+func handlePatterns(c *fiber.Ctx) error {
+	var req FormatRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(PatternsResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	if strings.TrimSpace(req.HTML) == "" {
+		return c.Status(400).JSON(PatternsResponse{
+			Success: false,
+			Error:   "HTML content is required",
+		})
+	}
+
+	if len(req.HTML) > maxHTMLBytes {
+		return htmlTooLarge(c)
+	}
+
+	patterns, err := analyzer.GetPatternStats(req.HTML)
+	if err != nil {
+		return c.Status(500).JSON(PatternsResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+	}
+
+	return c.JSON(PatternsResponse{
+		Success:  true,
+		Patterns: patterns,
+	})
+}
+
+func handleExport(c *fiber.Ctx) error {
+	var req FormatRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(Response{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	if strings.TrimSpace(req.HTML) == "" {
+		return c.Status(400).JSON(Response{
+			Success: false,
+			Error:   "HTML content is required",
+		})
+	}
+
+	if len(req.HTML) > maxHTMLBytes {
+		return htmlTooLarge(c)
+	}
+
+	return sendExtractedZip(c, req.HTML, req.extractOptions())
+}
+
+func handleExtract(c *fiber.Ctx) error {
+	var req FormatRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(ExtractResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	if strings.TrimSpace(req.HTML) == "" {
+		return c.Status(400).JSON(ExtractResponse{
+			Success: false,
+			Error:   "HTML content is required",
+		})
+	}
+
+	if len(req.HTML) > maxHTMLBytes {
+		return htmlTooLarge(c)
+	}
+
+	opts, err := req.extractOptions().Normalize()
+	if err != nil {
+		return c.Status(400).JSON(ExtractResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+	}
+	opts.Timeout = exportTimeout
+	extracted, err := extractor.ExtractContext(c.UserContext(), req.HTML, opts)
+	if err != nil {
+		return c.Status(500).JSON(ExtractResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+	}
+
+	duplicateIDs, err := analyzer.DuplicateIDs(req.HTML)
+	if err != nil {
+		return c.Status(500).JSON(ExtractResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+	}
+
+	return c.JSON(ExtractResponse{
+		Success:      true,
+		HTML:         extracted.HTML,
+		CSS:          extracted.CSS,
+		JS:           extracted.JS,
+		ExternalCSS:  externalResources(extracted.ExternalCSS),
+		ExternalJS:   externalResources(extracted.ExternalJS),
+		DuplicateIDs: duplicateIDs,
+	})
+}
+
+func externalResources(resources []fetcher.FetchedResource) []ExternalResource {
+	out := make([]ExternalResource, 0, len(resources))
+	for _, resource := range resources {
+		entry := ExternalResource{
+			URL:      resource.URL,
+			Filename: resource.Filename,
+			Success:  resource.Error == nil && resource.SkipReason == "",
+			Skipped:  resource.SkipReason,
+		}
+		if resource.Error != nil {
+			entry.Error = resource.Error.Error()
+		}
+		out = append(out, entry)
+	}
+	return out
+}
 
-	setupRoutes(app)
+// sendExtractedZip runs the extractor over htmlContent and responds with the
+// resulting extracted.zip. X-Resources-Total and X-Resources-Failed count the
+// external resources and those that could not be downloaded. With
+// ?format=json it responds with an ExportSummary instead of the archive, and
+// with ?format=base64 or Accept: application/json it sends the archive as a
+// ZipResponse. ?format=tar.gz or Accept: application/gzip gets extracted.tar.gz.
+// Accept: text/event-stream streams the export's progress; see
+// streamExtractedZip.
+func sendExtractedZip(c *fiber.Ctx, htmlContent string, opts extractor.ExtractOptions) error {
+	format := strings.ToLower(c.Query("format", "zip"))
+	if format != "zip" && format != "tar.gz" && format != "json" && format != "base64" {
+		return c.Status(400).JSON(Response{
+			Success: false,
+			Error:   fmt.Sprintf("invalid format %q (expected zip, tar.gz, json, or base64)", format),
+		})
+	}
 
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "3000"
+	opts, err := opts.Normalize()
+	if err != nil {
+		return c.Status(400).JSON(Response{
+			Success: false,
+			Error:   err.Error(),
+		})
 	}
+	opts.Timeout = exportTimeout
 
-	if err := app.Listen(":" + port); err != nil {
-		fmt.Printf("Server failed to start: %v\n", err)
-		os.Exit(1)
+	if wantsEventStream(c) {
+		return streamExtractedZip(c, htmlContent, opts, format == "json")
 	}
-}
 
-type FormatRequest struct {
-	HTML string `json:"html" validate:"required"`
-}
+	extracted, err := extractor.ExtractContext(c.UserContext(), htmlContent, opts)
+	if err != nil {
+		return c.Status(500).JSON(Response{
+			Success: false,
+			Error:   err.Error(),
+		})
+	}
 
-type ConvertRequest struct {
-	HTML string `json:"html" validate:"required"`
-}
+	total := len(extracted.ExternalCSS) + len(extracted.ExternalJS)
+	failed := len(extracted.FailedResources())
+	c.Set("X-Resources-Total", fmt.Sprintf("%d", total))
+	c.Set("X-Resources-Failed", fmt.Sprintf("%d", failed))
+
+	if format == "json" {
+		return c.JSON(ExportSummary{
+			Success:         true,
+			ResourcesTotal:  total,
+			ResourcesFailed: failed,
+			Manifest:        zipper.BuildManifest(extracted),
+		})
+	}
 
-type Response struct {
-	Success bool   `json:"success"`
-	Data    string `json:"data,omitempty"`
-	Error   string `json:"error,omitempty"`
+	createArchive := zipper.CreateZipWithMetadata
+	if wantsTarGz(c) {
+		createArchive = zipper.CreateTarGzWithMetadata
+	}
+	zipData, err := createArchive(extracted)
+	if err != nil {
+		return c.Status(500).JSON(Response{
+			Success: false,
+			Error:   err.Error(),
+		})
+	}
+
+	c.Set("X-Fetch-Errors", fmt.Sprintf("%d", failed))
+	return sendArchive(c, zipData, "extracted")
 }
 
-type ComponentResponse struct {
-	Success     bool                           `json:"success"`
-	Suggestions []analyzer.ComponentSuggestion `json:"suggestions,omitempty"`
-	Error       string                         `json:"error,omitempty"`
+// ExportProgress is the data of the zipping event streamed by
+// streamExtractedZip, sent once the downloads are over.
+type ExportProgress struct {
+	ResourcesTotal  int `json:"resourcesTotal"`
+	ResourcesFailed int `json:"resourcesFailed"`
 }
 
-func setupRoutes(app *fiber.App) {
-	api := app.Group("/api")
+// streamExtractedZip runs the export like sendExtractedZip, responding with
+// server-sent events: fetch-started and fetch-complete (fetcher.Progress) for
+// each external stylesheet and script, zipping (ExportProgress) once they are
+// downloaded, and done with the archive as a ZipResponse, which carries it as
+// base64 whatever the format. With summary (?format=json) done carries the
+// ExportSummary instead and no archive is built. A failure ends the stream
+// with an error event carrying a Response. When an event cannot be written
+// because the client has gone away, the downloads still running are
+// abandoned and the stream ends.
+func streamExtractedZip(c *fiber.Ctx, htmlContent string, opts extractor.ExtractOptions, summary bool) error {
+	ctx, cancel := context.WithCancel(c.UserContext())
+	createArchive, filename := zipper.CreateZipWithMetadata, "extracted.zip"
+	if wantsTarGz(c) {
+		createArchive, filename = zipper.CreateTarGzWithMetadata, "extracted.tar.gz"
+	}
+	// The stream outlives the handler, and with it the request buffers that
+	// form values point into.
+	opts.CSSFileName = utils.CopyString(opts.CSSFileName)
+	opts.JSFileName = utils.CopyString(opts.JSFileName)
+
+	c.Set("Content-Type", "text/event-stream")
+	c.Set("Cache-Control", "no-cache")
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer cancel()
+		// send writes an event and reports whether the client received it,
+		// cancelling the export when it did not.
+		send := func(event string, data any) bool {
+			payload, err := json.Marshal(data)
+			if err != nil {
+				return true
+			}
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, payload)
+			if err := w.Flush(); err != nil {
+				cancel()
+				return false
+			}
+			return true
+		}
 
-	api.Post("/format", handleFormat)
+		ctx := fetcher.WithProgress(ctx, func(p fetcher.Progress) { send(p.Stage, p) })
+		extracted, err := extractor.ExtractContext(ctx, htmlContent, opts)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			send("error", Response{Success: false, Error: err.Error()})
+			return
+		}
 
-	api.Post("/convert", handleConvert)
+		total, failed := len(extracted.ExternalCSS)+len(extracted.ExternalJS), len(extracted.FailedResources())
+		if summary {
+			send("done", ExportSummary{
+				Success:         true,
+				ResourcesTotal:  total,
+				ResourcesFailed: failed,
+				Manifest:        zipper.BuildManifest(extracted),
+			})
+			return
+		}
+		if !send("zipping", ExportProgress{ResourcesTotal: total, ResourcesFailed: failed}) {
+			return
+		}
+		archive, err := createArchive(extracted)
+		if err != nil {
+			send("error", Response{Success: false, Error: err.Error()})
+			return
+		}
+		send("done", ZipResponse{
+			Success:   true,
+			Filename:  filename,
+			ZipBase64: base64.StdEncoding.EncodeToString(archive),
+			Size:      len(archive),
+		})
+	})
+	return nil
+}
 
-	api.Post("/analyze", handleAnalyze)
+// wantsEventStream reports whether the client asked for the export's progress
+// as server-sent events with an Accept header that prefers text/event-stream.
+func wantsEventStream(c *fiber.Ctx) bool {
+	return c.Get(fiber.HeaderAccept) != "" && c.Accepts("application/zip", "text/event-stream") == "text/event-stream"
+}
 
-	api.Post("/export", handleExport)
+// wantsZipJSON reports whether the client asked for a ZipResponse rather than
+// the archive bytes, either with ?format=base64 or an Accept header that
+// prefers application/json. A missing or */* Accept header gets the zip.
+func wantsZipJSON(c *fiber.Ctx) bool {
+	if strings.EqualFold(c.Query("format"), "base64") {
+		return true
+	}
+	return c.Get(fiber.HeaderAccept) != "" && c.Accepts("application/zip", "application/json") == "application/json"
+}
 
-	api.Post("/export-nodejs", handleExportNodeJS)
+// wantsTarGz reports whether the client asked for a gzipped tarball instead
+// of a zip, either with ?format=tar.gz or an Accept header that prefers
+// application/gzip.
+func wantsTarGz(c *fiber.Ctx) bool {
+	if strings.EqualFold(c.Query("format"), "tar.gz") {
+		return true
+	}
+	return c.Get(fiber.HeaderAccept) != "" && c.Accepts("application/zip", "application/gzip") == "application/gzip"
+}
 
-	api.Post("/export-nodejs-ejs", handleExportNodeJSEJS)
+// createProjectArchive packs a generated project as a tar.gz when wantsTarGz
+// and as a zip otherwise.
+func createProjectArchive(c *fiber.Ctx, files map[string]string, binaryFiles map[string][]byte, projectName string) ([]byte, error) {
+	if wantsTarGz(c) {
+		return nodejs.CreateProjectTarGzWithBinary(files, binaryFiles, projectName)
+	}
+	return nodejs.CreateProjectZipWithBinary(files, binaryFiles, projectName)
+}
 
-	api.Post("/bundle-zip", handleBundleZip)
+// sendArchive responds with the archive as name plus .zip or .tar.gz,
+// following wantsTarGz, or with a ZipResponse when wantsZipJSON.
+func sendArchive(c *fiber.Ctx, archive []byte, name string) error {
+	contentType, filename := "application/zip", name+".zip"
+	if wantsTarGz(c) {
+		contentType, filename = "application/gzip", name+".tar.gz"
+	}
 
-	api.Post("/scrape", handleScrape)
-	api.Post("/scrape-nodejs", handleScrapeNodeJS)
-	api.Post("/scrape-nodejs-ejs", handleScrapeNodeJSEJS)
+	if wantsZipJSON(c) {
+		return c.JSON(ZipResponse{
+			Success:   true,
+			Filename:  filename,
+			ZipBase64: base64.StdEncoding.EncodeToString(archive),
+			Size:      len(archive),
+		})
+	}
 
-	api.Get("/health", handleHealth)
+	c.Set("Content-Type", contentType)
+	c.Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+	c.Set("Content-Length", fmt.Sprintf("%d", len(archive)))
 
-	app.Static("/", "./dist")
+	return c.Send(archive)
 }
 
-func handleFormat(c *fiber.Ctx) error {
-	var req FormatRequest
+func handleExportNodeJS(c *fiber.Ctx) error {
+	var req ExportNodeJSRequest
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(400).JSON(Response{
 			Success: false,
@@ -122,37 +1275,62 @@ func handleFormat(c *fiber.Ctx) error {
 		})
 	}
 
-	formatted, err := formatter.Format(req.HTML)
+	if len(req.HTML) > maxHTMLBytes {
+		return htmlTooLarge(c)
+	}
+
+	packageManager, err := nodejs.NormalizePackageManager(req.PackageManager)
 	if err != nil {
-		return c.Status(500).JSON(Response{
+		return c.Status(400).JSON(Response{
 			Success: false,
 			Error:   err.Error(),
 		})
 	}
 
-	return c.JSON(Response{
-		Success: true,
-		Data:    formatted,
-	})
-}
+	language := strings.ToLower(strings.TrimSpace(req.Language))
+	if language != "" && language != "ts" && language != "js" {
+		return c.Status(400).JSON(Response{
+			Success: false,
+			Error:   "language must be one of: ts, js",
+		})
+	}
 
-func handleConvert(c *fiber.Ctx) error {
-	var req ConvertRequest
-	if err := c.BodyParser(&req); err != nil {
+	componentName := strings.TrimSpace(req.ComponentName)
+	if componentName != "" {
+		if err := converter.ValidateComponentName(componentName); err != nil {
+			return c.Status(400).JSON(Response{
+				Success: false,
+				Error:   err.Error(),
+			})
+		}
+	}
+
+	versions, err := nodejs.DependencyVersions{
+		React:       req.ReactVersion,
+		Vite:        req.ViteVersion,
+		TypeScript:  req.TypeScriptVersion,
+		ReactRouter: req.ReactRouterVersion,
+	}.Normalize()
+	if err != nil {
 		return c.Status(400).JSON(Response{
 			Success: false,
-			Error:   "Invalid request body",
+			Error:   err.Error(),
 		})
 	}
 
-	if strings.TrimSpace(req.HTML) == "" {
+	layout, err := nodejs.Layout{
+		Components: req.ComponentsDir,
+		Styles:     req.StylesDir,
+		Scripts:    req.ScriptsDir,
+	}.Normalize()
+	if err != nil {
 		return c.Status(400).JSON(Response{
 			Success: false,
-			Error:   "HTML content is required",
+			Error:   err.Error(),
 		})
 	}
 
-	jsx, err := converter.ConvertToJSX(req.HTML, "", "", nil, nil)
+	extracted, err := extractor.ExtractContext(c.UserContext(), req.HTML, extractor.ExtractOptions{ExternalizeDataURIs: req.ExternalizeDataURIs, DataURIThreshold: req.DataURIThreshold, Timeout: exportTimeout, KeepExternal: req.KeepExternal, DedupeCSS: req.DedupeCSS, HashFilenames: req.HashFilenames})
 	if err != nil {
 		return c.Status(500).JSON(Response{
 			Success: false,
@@ -160,44 +1338,56 @@ func handleConvert(c *fiber.Ctx) error {
 		})
 	}
 
-	return c.JSON(Response{
-		Success: true,
-		Data:    jsx,
-	})
-}
+	rewrittenHTML := extracted.RewriteForNodeJS()
 
-func handleAnalyze(c *fiber.Ctx) error {
-	var req ConvertRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(400).JSON(ComponentResponse{
-			Success: false,
-			Error:   "Invalid request body",
-		})
+	projectName := fmt.Sprintf("project-%d", time.Now().Unix())
+
+	config := &nodejs.ProjectConfig{
+		ProjectName:    projectName,
+		PackageManager: packageManager,
+		Language:       language,
+		Tailwind:       req.Tailwind,
+		ComponentName:  componentName,
+		IncludeDocker:  req.IncludeDocker,
+		HTML:           rewrittenHTML,
+		CSS:            extracted.CSS,
+		JS:             extracted.JS,
+		ExternalCSS:    extracted.ExternalCSS,
+		ExternalJS:     extracted.ExternalJS,
+		Head:           extracted.Head,
+		Versions:       versions,
+		Minify:         req.Minify,
+		Router:         req.Router,
+		Layout:         layout,
+		Logger:         logging.FromContext(c.UserContext()),
 	}
 
-	if strings.TrimSpace(req.HTML) == "" {
-		return c.Status(400).JSON(ComponentResponse{
+	projectFiles, err := nodejs.GenerateProject(config)
+	if err != nil {
+		return c.Status(500).JSON(Response{
 			Success: false,
-			Error:   "HTML content is required",
+			Error:   err.Error(),
 		})
 	}
 
-	suggestions, err := analyzer.AnalyzeComponents(req.HTML)
+	binaryFiles := make(map[string][]byte, len(extracted.LocalAssets))
+	for _, asset := range extracted.LocalAssets {
+		binaryFiles["public/"+asset.Path] = asset.Content
+	}
+
+	zipData, err := createProjectArchive(c, projectFiles.Files, binaryFiles, projectName)
 	if err != nil {
-		return c.Status(500).JSON(ComponentResponse{
+		return c.Status(500).JSON(Response{
 			Success: false,
 			Error:   err.Error(),
 		})
 	}
 
-	return c.JSON(ComponentResponse{
-		Success:     true,
-		Suggestions: suggestions,
-	})
+	return sendArchive(c, zipData, projectName)
 }
 
-func handleExport(c *fiber.Ctx) error {
-	var req FormatRequest
+func handleExportNodeJSEJS(c *fiber.Ctx) error {
+	var req ExportEJSRequest
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(400).JSON(Response{
 			Success: false,
@@ -212,7 +1402,11 @@ func handleExport(c *fiber.Ctx) error {
 		})
 	}
 
-	extracted, err := extractor.Extract(req.HTML)
+	if len(req.HTML) > maxHTMLBytes {
+		return htmlTooLarge(c)
+	}
+
+	extracted, err := extractor.ExtractContext(c.UserContext(), req.HTML, extractor.ExtractOptions{Timeout: exportTimeout, KeepExternal: req.KeepExternal, DedupeCSS: req.DedupeCSS, HashFilenames: req.HashFilenames})
 	if err != nil {
 		return c.Status(500).JSON(Response{
 			Success: false,
@@ -220,7 +1414,27 @@ func handleExport(c *fiber.Ctx) error {
 		})
 	}
 
-	zipData, err := zipper.CreateZipWithMetadata(extracted.HTML, extracted.InlineCSS, extracted.InlineJS, extracted.ExternalCSS, extracted.ExternalJS, extracted.LocalAssets)
+	projectName := fmt.Sprintf("project-%d", time.Now().Unix())
+
+	rewrittenHTML := extracted.RewriteForEJS()
+
+	config := &nodejs.EJSProjectConfig{
+		ProjectName: projectName,
+		HTML:        rewrittenHTML,
+		InlineCSS:   extracted.InlineCSS,
+		InlineJS:    extracted.InlineJS,
+		ExternalCSS: extracted.ExternalCSS,
+		ExternalJS:  extracted.ExternalJS,
+		Extraction: nodejs.EJSExtractionOptions{
+			MaxDepth:      req.MaxDepth,
+			ExtraKeywords: req.ExtraKeywords,
+			MinTextLength: req.MinTextLength,
+			RootDepth:     req.RootDepth,
+			Exclude:       req.Exclude,
+		},
+	}
+
+	projectFiles, err := nodejs.GenerateEJSProject(config)
 	if err != nil {
 		return c.Status(500).JSON(Response{
 			Success: false,
@@ -228,14 +1442,23 @@ func handleExport(c *fiber.Ctx) error {
 		})
 	}
 
-	c.Set("Content-Type", "application/zip")
-	c.Set("Content-Disposition", "attachment; filename=\"extracted.zip\"")
-	c.Set("Content-Length", fmt.Sprintf("%d", len(zipData)))
+	binaryFiles := make(map[string][]byte, len(extracted.LocalAssets))
+	for _, asset := range extracted.LocalAssets {
+		binaryFiles["public/"+asset.Path] = asset.Content
+	}
 
-	return c.Send(zipData)
+	zipData, err := createProjectArchive(c, projectFiles.Files, binaryFiles, projectName)
+	if err != nil {
+		return c.Status(500).JSON(Response{
+			Success: false,
+			Error:   err.Error(),
+		})
+	}
+
+	return sendArchive(c, zipData, projectName+"-ejs")
 }
 
-func handleExportNodeJS(c *fiber.Ctx) error {
+func handleExportStatic(c *fiber.Ctx) error {
 	var req FormatRequest
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(400).JSON(Response{
@@ -251,7 +1474,11 @@ func handleExportNodeJS(c *fiber.Ctx) error {
 		})
 	}
 
-	extracted, err := extractor.Extract(req.HTML)
+	if len(req.HTML) > maxHTMLBytes {
+		return htmlTooLarge(c)
+	}
+
+	extracted, err := extractor.ExtractContext(c.UserContext(), req.HTML, extractor.ExtractOptions{Timeout: exportTimeout, KeepExternal: req.KeepExternal, DedupeCSS: req.DedupeCSS, HashFilenames: req.HashFilenames})
 	if err != nil {
 		return c.Status(500).JSON(Response{
 			Success: false,
@@ -259,21 +1486,20 @@ func handleExportNodeJS(c *fiber.Ctx) error {
 		})
 	}
 
-	rewrittenHTML := extracted.RewriteForNodeJS()
-
 	projectName := fmt.Sprintf("project-%d", time.Now().Unix())
 
 	config := &nodejs.ProjectConfig{
 		ProjectName:    projectName,
 		PackageManager: "npm",
-		HTML:           rewrittenHTML,
-		CSS:            extracted.CSS,
-		JS:             extracted.JS,
+		HTML:           extracted.RewriteForStatic(),
+		InlineCSS:      extracted.InlineCSS,
+		InlineJS:       extracted.InlineJS,
 		ExternalCSS:    extracted.ExternalCSS,
 		ExternalJS:     extracted.ExternalJS,
+		Logger:         logging.FromContext(c.UserContext()),
 	}
 
-	projectFiles, err := nodejs.GenerateProject(config)
+	projectFiles, err := nodejs.GenerateStaticProject(config)
 	if err != nil {
 		return c.Status(500).JSON(Response{
 			Success: false,
@@ -281,7 +1507,12 @@ func handleExportNodeJS(c *fiber.Ctx) error {
 		})
 	}
 
-	zipData, err := nodejs.CreateProjectZip(projectFiles.Files, projectName)
+	binaryFiles := make(map[string][]byte, len(extracted.LocalAssets))
+	for _, asset := range extracted.LocalAssets {
+		binaryFiles[asset.Path] = asset.Content
+	}
+
+	zipData, err := createProjectArchive(c, projectFiles.Files, binaryFiles, projectName)
 	if err != nil {
 		return c.Status(500).JSON(Response{
 			Success: false,
@@ -289,15 +1520,11 @@ func handleExportNodeJS(c *fiber.Ctx) error {
 		})
 	}
 
-	c.Set("Content-Type", "application/zip")
-	c.Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s.zip\"", projectName))
-	c.Set("Content-Length", fmt.Sprintf("%d", len(zipData)))
-
-	return c.Send(zipData)
+	return sendArchive(c, zipData, projectName+"-static")
 }
 
-func handleExportNodeJSEJS(c *fiber.Ctx) error {
-	var req FormatRequest
+func handleExportSvelte(c *fiber.Ctx) error {
+	var req ExportSvelteRequest
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(400).JSON(Response{
 			Success: false,
@@ -312,7 +1539,19 @@ func handleExportNodeJSEJS(c *fiber.Ctx) error {
 		})
 	}
 
-	extracted, err := extractor.Extract(req.HTML)
+	if len(req.HTML) > maxHTMLBytes {
+		return htmlTooLarge(c)
+	}
+
+	packageManager, err := nodejs.NormalizePackageManager(req.PackageManager)
+	if err != nil {
+		return c.Status(400).JSON(Response{
+			Success: false,
+			Error:   err.Error(),
+		})
+	}
+
+	extracted, err := extractor.ExtractContext(c.UserContext(), req.HTML, extractor.ExtractOptions{Timeout: exportTimeout, KeepExternal: req.KeepExternal, DedupeCSS: req.DedupeCSS, HashFilenames: req.HashFilenames})
 	if err != nil {
 		return c.Status(500).JSON(Response{
 			Success: false,
@@ -322,18 +1561,18 @@ func handleExportNodeJSEJS(c *fiber.Ctx) error {
 
 	projectName := fmt.Sprintf("project-%d", time.Now().Unix())
 
-	rewrittenHTML := extracted.RewriteForEJS()
-
-	config := &nodejs.EJSProjectConfig{
-		ProjectName: projectName,
-		HTML:        rewrittenHTML,
-		InlineCSS:   extracted.InlineCSS,
-		InlineJS:    extracted.InlineJS,
-		ExternalCSS: extracted.ExternalCSS,
-		ExternalJS:  extracted.ExternalJS,
+	config := &nodejs.ProjectConfig{
+		ProjectName:    projectName,
+		PackageManager: packageManager,
+		HTML:           extracted.HTML,
+		CSS:            extracted.CSS,
+		JS:             extracted.JS,
+		ExternalCSS:    extracted.ExternalCSS,
+		ExternalJS:     extracted.ExternalJS,
+		Logger:         logging.FromContext(c.UserContext()),
 	}
 
-	projectFiles, err := nodejs.GenerateEJSProject(config)
+	projectFiles, err := nodejs.GenerateSvelteProject(config)
 	if err != nil {
 		return c.Status(500).JSON(Response{
 			Success: false,
@@ -341,7 +1580,12 @@ func handleExportNodeJSEJS(c *fiber.Ctx) error {
 		})
 	}
 
-	zipData, err := nodejs.CreateProjectZip(projectFiles.Files, projectName)
+	binaryFiles := make(map[string][]byte, len(extracted.LocalAssets))
+	for _, asset := range extracted.LocalAssets {
+		binaryFiles["public/"+asset.Path] = asset.Content
+	}
+
+	zipData, err := createProjectArchive(c, projectFiles.Files, binaryFiles, projectName)
 	if err != nil {
 		return c.Status(500).JSON(Response{
 			Success: false,
@@ -349,11 +1593,7 @@ func handleExportNodeJSEJS(c *fiber.Ctx) error {
 		})
 	}
 
-	c.Set("Content-Type", "application/zip")
-	c.Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s-ejs.zip\"", projectName))
-	c.Set("Content-Length", fmt.Sprintf("%d", len(zipData)))
-
-	return c.Send(zipData)
+	return sendArchive(c, zipData, projectName+"-svelte")
 }
 
 type ScrapeRequest struct {
@@ -374,13 +1614,14 @@ func handleScrape(c *fiber.Ctx) error {
 		return c.Status(500).JSON(Response{Success: false, Error: err.Error()})
 	}
 
-	zipData, err := zipper.CreateZipWithMetadata(extracted.HTML, extracted.InlineCSS, extracted.InlineJS, extracted.ExternalCSS, extracted.ExternalJS, extracted.LocalAssets)
+	zipData, err := zipper.CreateZipWithMetadata(extracted)
 	if err != nil {
 		return c.Status(500).JSON(Response{Success: false, Error: err.Error()})
 	}
 
 	c.Set("Content-Type", "application/zip")
 	c.Set("Content-Disposition", "attachment; filename=\"extracted.zip\"")
+	c.Set("X-Fetch-Errors", fmt.Sprintf("%d", len(extracted.FailedResources())))
 	c.Set("Content-Length", fmt.Sprintf("%d", len(zipData)))
 	return c.Send(zipData)
 }
@@ -405,11 +1646,14 @@ func handleScrapeNodeJS(c *fiber.Ctx) error {
 	config := &nodejs.ProjectConfig{
 		ProjectName:    projectName,
 		PackageManager: "npm",
+		ComponentName:  componentNameForURL(req.URL),
 		HTML:           rewrittenHTML,
 		CSS:            extracted.CSS,
 		JS:             extracted.JS,
 		ExternalCSS:    extracted.ExternalCSS,
 		ExternalJS:     extracted.ExternalJS,
+		Head:           extracted.Head,
+		Logger:         logging.FromContext(c.UserContext()),
 	}
 
 	projectFiles, err := nodejs.GenerateProject(config)
@@ -519,7 +1763,7 @@ func handleBundleZip(c *fiber.Ctx) error {
 	}
 
 	var buf bytes.Buffer
-	zw := zip.NewWriter(&buf)
+	zw := zipper.NewWriter(&buf)
 
 	walkErr := filepath.Walk(tmpOutDir, func(path string, info os.FileInfo, werr error) error {
 		if werr != nil {
@@ -532,7 +1776,7 @@ func handleBundleZip(c *fiber.Ctx) error {
 		if err != nil {
 			return err
 		}
-		w, err := zw.Create(filepath.ToSlash(rel))
+		w, err := zipper.CreateEntry(zw, filepath.ToSlash(rel))
 		if err != nil {
 			return err
 		}
@@ -558,9 +1802,42 @@ func handleBundleZip(c *fiber.Ctx) error {
 }
 
 func handleHealth(c *fiber.Ctx) error {
+	goVersion, revision := buildStatus()
+	return c.JSON(fiber.Map{
+		"status":    "healthy",
+		"service":   "htmlfmt-api",
+		"version":   "1.0.0",
+		"goVersion": goVersion,
+		"revision":  revision,
+	})
+}
+
+// ready is set once the server is listening. /api/health only reports that the
+// process is alive; /api/ready tells orchestrators when to send traffic.
+var ready atomic.Bool
+
+func handleReady(c *fiber.Ctx) error {
+	if !ready.Load() {
+		return c.Status(503).JSON(fiber.Map{
+			"status": "starting",
+		})
+	}
 	return c.JSON(fiber.Map{
-		"status":  "healthy",
-		"service": "htmlfmt-api",
-		"version": "1.0.0",
+		"status": "ready",
 	})
 }
+
+// buildStatus reports the Go toolchain and VCS revision embedded in the binary.
+// The revision is empty for builds made outside a git checkout.
+func buildStatus() (goVersion, revision string) {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return runtime.Version(), ""
+	}
+	for _, setting := range info.Settings {
+		if setting.Key == "vcs.revision" {
+			revision = setting.Value
+		}
+	}
+	return info.GoVersion, revision
+}
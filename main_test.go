@@ -0,0 +1,779 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/omariomari2/uncluster/internal/fetcher"
+)
+
+func newTestApp() *fiber.App {
+	app := fiber.New()
+	setupRoutes(app)
+	return app
+}
+
+func multipartRequest(t *testing.T, target, filename, content string) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("file", filename)
+	if err != nil {
+		t.Fatalf("failed to create form file: %v", err)
+	}
+	io.WriteString(part, content)
+	writer.Close()
+
+	req := httptest.NewRequest(http.MethodPost, target, &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req
+}
+
+func decodeResponse(t *testing.T, resp *http.Response) Response {
+	t.Helper()
+	var out Response
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	return out
+}
+
+func TestFormatFileFormatsUploadedHTML(t *testing.T) {
+	app := newTestApp()
+
+	resp, err := app.Test(multipartRequest(t, "/api/format-file", "page.html", "<html><body><p>hi</p></body></html>"))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if resp.StatusCode != 200 {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	out := decodeResponse(t, resp)
+	if !out.Success || !bytes.Contains([]byte(out.Data), []byte("<p>")) {
+		t.Fatalf("unexpected response: %+v", out)
+	}
+}
+
+func TestFormatFileRejectsBadUploads(t *testing.T) {
+	app := newTestApp()
+
+	cases := map[string]*http.Request{
+		"empty file":      multipartRequest(t, "/api/format-file", "page.html", "   "),
+		"wrong extension": multipartRequest(t, "/api/format-file", "notes.txt", "<p>hi</p>"),
+		"missing file":    httptest.NewRequest(http.MethodPost, "/api/format-file", nil),
+	}
+	for name, req := range cases {
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("%s: request failed: %v", name, err)
+		}
+		if resp.StatusCode != 400 {
+			t.Fatalf("%s: expected 400, got %d", name, resp.StatusCode)
+		}
+	}
+}
+
+func TestExportFileReturnsZip(t *testing.T) {
+	app := newTestApp()
+
+	resp, err := app.Test(multipartRequest(t, "/api/export-file", "page.html", "<html><head><style>p{color:red}</style></head><body><p>hi</p></body></html>"))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if resp.StatusCode != 200 {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "application/zip" {
+		t.Fatalf("expected application/zip, got %q", ct)
+	}
+}
+
+func TestAPIKeyAuth(t *testing.T) {
+	t.Setenv("API_KEYS", "first-key, second-key")
+	app := newTestApp()
+
+	formatRequest := func(header, value string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/api/format", bytes.NewBufferString(`{"html":"<p>hi</p>"}`))
+		req.Header.Set("Content-Type", "application/json")
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+		return req
+	}
+
+	cases := []struct {
+		name   string
+		req    *http.Request
+		status int
+	}{
+		{"no key", formatRequest("", ""), 401},
+		{"wrong key", formatRequest("X-API-Key", "nope"), 401},
+		{"x-api-key", formatRequest("X-API-Key", "second-key"), 200},
+		{"bearer", formatRequest("Authorization", "Bearer first-key"), 200},
+		{"health stays public", httptest.NewRequest(http.MethodGet, "/api/health", nil), 200},
+	}
+	for _, tc := range cases {
+		resp, err := app.Test(tc.req)
+		if err != nil {
+			t.Fatalf("%s: request failed: %v", tc.name, err)
+		}
+		if resp.StatusCode != tc.status {
+			t.Fatalf("%s: expected %d, got %d", tc.name, tc.status, resp.StatusCode)
+		}
+	}
+}
+
+func TestExportRateLimit(t *testing.T) {
+	t.Setenv("RATE_LIMIT_EXPORT_PER_MINUTE", "1")
+	t.Setenv("RATE_LIMIT_EXPORT_BURST", "1")
+	app := newTestApp()
+
+	exportRequest := func() *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/api/export", bytes.NewBufferString(`{"html":"<p>hi</p>"}`))
+		req.Header.Set("Content-Type", "application/json")
+		return req
+	}
+
+	resp, err := app.Test(exportRequest())
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if resp.StatusCode != 200 {
+		t.Fatalf("expected first export to succeed, got %d", resp.StatusCode)
+	}
+
+	resp, err = app.Test(exportRequest())
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if resp.StatusCode != 429 || resp.Header.Get("Retry-After") == "" {
+		t.Fatalf("expected 429 with Retry-After, got %d %q", resp.StatusCode, resp.Header.Get("Retry-After"))
+	}
+
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/api/health", nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if resp.StatusCode != 200 {
+		t.Fatalf("expected health to stay available, got %d", resp.StatusCode)
+	}
+}
+
+func TestFormatWarnsWhenParserMovesElements(t *testing.T) {
+	app := newTestApp()
+
+	page := `<html><head><div class=\"banner\">x</div></head><body><p>a</p></body></html>`
+	for _, tc := range []struct {
+		body        string
+		wantWarning bool
+	}{
+		{`{"html":"` + page + `"}`, true},
+		{`{"html":"` + page + `","lenient":true}`, false},
+		{`{"html":"<p>fine</p>"}`, false},
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/api/format", bytes.NewBufferString(tc.body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		var out FormatResponse
+		if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if !out.Success || (out.Warning != "") != tc.wantWarning {
+			t.Fatalf("%s: expected warning=%v, got %+v", tc.body, tc.wantWarning, out)
+		}
+	}
+}
+
+func TestFormatAcceptsGzippedBody(t *testing.T) {
+	app := newTestApp()
+
+	var body bytes.Buffer
+	w := gzip.NewWriter(&body)
+	io.WriteString(w, `{"html":"<div><p>compressed</p></div>"}`)
+	w.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/format", &body)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Encoding", "gzip")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	var out FormatResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.StatusCode != 200 || !out.Success || !strings.Contains(out.Data, "<p>compressed</p>") {
+		t.Fatalf("expected the gzipped document to be formatted, got %d %+v", resp.StatusCode, out)
+	}
+}
+
+func TestFormatBatchKeepsOrderAndIsolatesFailures(t *testing.T) {
+	app := newTestApp()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/format-batch", bytes.NewBufferString(`{"documents":["<p>one</p>","  ","<div>three</div>"]}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if resp.StatusCode != 200 {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+
+	var out FormatBatchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(out.Results) != 3 {
+		t.Fatalf("expected 3 results, got %+v", out.Results)
+	}
+	if !out.Results[0].Success || !strings.Contains(out.Results[0].Data, "one") {
+		t.Fatalf("unexpected first result: %+v", out.Results[0])
+	}
+	if out.Results[1].Success || out.Results[1].Error == "" {
+		t.Fatalf("expected blank document to fail on its own, got %+v", out.Results[1])
+	}
+	if !out.Results[2].Success || !strings.Contains(out.Results[2].Data, "three") {
+		t.Fatalf("unexpected third result: %+v", out.Results[2])
+	}
+}
+
+func TestExtractReturnsPartsAsJSON(t *testing.T) {
+	app := newTestApp()
+
+	page := `<html><head><link rel="stylesheet" href="http://127.0.0.1:1/site.css"><style>p{color:red}</style></head><body><p id="intro">hi</p><p id="intro">again</p></body></html>`
+	body, _ := json.Marshal(map[string]string{"html": page})
+	req := httptest.NewRequest(http.MethodPost, "/api/extract", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if resp.StatusCode != 200 {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+
+	var out ExtractResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if !out.Success || !strings.Contains(out.CSS, "color:red") || !strings.Contains(out.HTML, `<p id="intro">hi</p>`) {
+		t.Fatalf("unexpected response: %+v", out)
+	}
+	if len(out.ExternalCSS) != 1 || out.ExternalCSS[0].Success || out.ExternalCSS[0].Error == "" {
+		t.Fatalf("expected the blocked stylesheet to be reported as failed, got %+v", out.ExternalCSS)
+	}
+	if out.DuplicateIDs["intro"] != 2 || !strings.Contains(out.HTML, `<p id="intro">again</p>`) {
+		t.Fatalf("expected the duplicate id to be reported and left in place, got %v", out.DuplicateIDs)
+	}
+}
+
+func TestExtractCSSOnly(t *testing.T) {
+	app := newTestApp()
+
+	page := `<html><head><style>p{color:red}</style></head><body><p>hi</p><script>start()</script></body></html>`
+	body, _ := json.Marshal(map[string]any{"html": page, "extractJs": false})
+	req := httptest.NewRequest(http.MethodPost, "/api/extract", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+
+	var out ExtractResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if !out.Success || !strings.Contains(out.CSS, "color:red") || out.JS != "" || !strings.Contains(out.HTML, "start()") {
+		t.Fatalf("expected only the CSS to be extracted, got %+v", out)
+	}
+}
+
+func TestHandlersRejectOversizedHTML(t *testing.T) {
+	t.Setenv("MAX_HTML_BYTES", "100")
+	app := newTestApp()
+	defer func() { maxHTMLBytes = defaultMaxHTMLBytes }()
+
+	body, _ := json.Marshal(map[string]string{"html": "<p>" + strings.Repeat("x", 200) + "</p>"})
+	for _, path := range []string{"/api/format", "/api/convert", "/api/convert-preview", "/api/componentize", "/api/analyze", "/api/export", "/api/export-svelte"} {
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("%s: request failed: %v", path, err)
+		}
+		if resp.StatusCode != 413 {
+			t.Fatalf("%s: expected 413, got %d", path, resp.StatusCode)
+		}
+		if out := decodeResponse(t, resp); out.Success || !strings.Contains(out.Error, "too large") {
+			t.Fatalf("%s: unexpected response: %+v", path, out)
+		}
+	}
+
+	resp, err := app.Test(multipartRequest(t, "/api/format-file", "page.html", "<p>"+strings.Repeat("x", 200)+"</p>"))
+	if err != nil {
+		t.Fatalf("upload request failed: %v", err)
+	}
+	if resp.StatusCode != 413 {
+		t.Fatalf("upload: expected 413, got %d", resp.StatusCode)
+	}
+
+	body, _ = json.Marshal(map[string][]string{"documents": {"<p>ok</p>", "<p>" + strings.Repeat("x", 200) + "</p>"}})
+	req := httptest.NewRequest(http.MethodPost, "/api/format-batch", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if resp, err = app.Test(req); err != nil {
+		t.Fatalf("batch request failed: %v", err)
+	}
+	var batch FormatBatchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&batch); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(batch.Results) != 2 || !batch.Results[0].Success || batch.Results[1].Success || !strings.Contains(batch.Results[1].Error, "too large") {
+		t.Fatalf("expected only the oversized document to fail, got %+v", batch)
+	}
+}
+
+func TestReadyReportsStartup(t *testing.T) {
+	t.Setenv("API_KEYS", "secret")
+	app := newTestApp()
+	defer ready.Store(false)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/api/ready", nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if resp.StatusCode != 503 {
+		t.Fatalf("expected 503 before the server listens, got %d", resp.StatusCode)
+	}
+
+	ready.Store(true)
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/api/ready", nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if resp.StatusCode != 200 {
+		t.Fatalf("expected 200 once ready, got %d", resp.StatusCode)
+	}
+}
+
+func TestExportReportsResourceCounts(t *testing.T) {
+	app := newTestApp()
+
+	page := `<html><head><link rel="stylesheet" href="http://127.0.0.1:1/site.css"><script src="http://127.0.0.1:1/app.js"></script></head><body><p>hi</p></body></html>`
+	body, _ := json.Marshal(map[string]string{"html": page})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/export", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if resp.StatusCode != 200 || resp.Header.Get("Content-Type") != "application/zip" {
+		t.Fatalf("expected a zip download, got %d %q", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	if total, failed := resp.Header.Get("X-Resources-Total"), resp.Header.Get("X-Resources-Failed"); total != "2" || failed != "2" {
+		t.Fatalf("expected 2 of 2 resources failed, got total=%q failed=%q", total, failed)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/export?format=json", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err = app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	var summary ExportSummary
+	if err := json.NewDecoder(resp.Body).Decode(&summary); err != nil {
+		t.Fatalf("failed to decode summary: %v", err)
+	}
+	if !summary.Success || summary.ResourcesTotal != 2 || summary.ResourcesFailed != 2 || summary.Manifest == nil {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+	errored := 0
+	for _, entry := range summary.Manifest.Files {
+		if entry.Status == "error" {
+			errored++
+		}
+	}
+	if errored != 2 {
+		t.Fatalf("expected two failed manifest entries, got %+v", summary.Manifest.Files)
+	}
+}
+
+func TestExportStreamsProgressEvents(t *testing.T) {
+	defer fetcher.SetPolicy(fetcher.SetPolicy(fetcher.Policy{AllowPrivate: true}))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("body {}"))
+	}))
+	defer server.Close()
+	app := newTestApp()
+
+	page := `<html><head><link rel="stylesheet" href="` + server.URL + `/site.css"></head><body><p>hi</p></body></html>`
+	body, _ := json.Marshal(map[string]string{"html": page})
+	req := httptest.NewRequest(http.MethodPost, "/api/export", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("expected an event stream, got %q", resp.Header.Get("Content-Type"))
+	}
+
+	stream, _ := io.ReadAll(resp.Body)
+	var events []string
+	var done ZipResponse
+	for _, block := range strings.Split(strings.TrimSpace(string(stream)), "\n\n") {
+		lines := strings.SplitN(block, "\n", 2)
+		event := strings.TrimPrefix(lines[0], "event: ")
+		events = append(events, event)
+		if event == "done" {
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(lines[1], "data: ")), &done); err != nil {
+				t.Fatalf("failed to decode done event: %v", err)
+			}
+		}
+	}
+	if strings.Join(events, ",") != "fetch-started,fetch-complete,zipping,done" {
+		t.Fatalf("unexpected events %v in:\n%s", events, stream)
+	}
+	archive, err := base64.StdEncoding.DecodeString(done.ZipBase64)
+	if err != nil || !done.Success || done.Filename != "extracted.zip" || len(archive) != done.Size {
+		t.Fatalf("expected the zip in the done event, got %+v", done)
+	}
+}
+
+func TestExportStreamHonorsJSONFormat(t *testing.T) {
+	app := newTestApp()
+
+	body, _ := json.Marshal(map[string]string{"html": "<html><head><style>p { color: red; }</style></head><body><p>hi</p></body></html>"})
+	req := httptest.NewRequest(http.MethodPost, "/api/export?format=json", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+
+	stream, _ := io.ReadAll(resp.Body)
+	lines := strings.SplitN(strings.TrimSpace(string(stream)), "\n", 2)
+	if lines[0] != "event: done" {
+		t.Fatalf("expected only the done event, got:\n%s", stream)
+	}
+	var summary ExportSummary
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(lines[1], "data: ")), &summary); err != nil {
+		t.Fatalf("failed to decode done event: %v", err)
+	}
+	if !summary.Success || summary.Manifest == nil || strings.Contains(string(stream), "zipBase64") {
+		t.Fatalf("expected the export summary in the done event, got:\n%s", stream)
+	}
+}
+
+func TestExportReturnsBase64ZipWhenJSONAccepted(t *testing.T) {
+	app := newTestApp()
+
+	body, _ := json.Marshal(map[string]string{"html": "<html><body><p>hi</p></body></html>"})
+	for _, target := range []string{"/api/export", "/api/export-nodejs"} {
+		req := httptest.NewRequest(http.MethodPost, target, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json")
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("%s: request failed: %v", target, err)
+		}
+		if resp.StatusCode != 200 {
+			t.Fatalf("%s: expected 200, got %d", target, resp.StatusCode)
+		}
+		var zipResp ZipResponse
+		if err := json.NewDecoder(resp.Body).Decode(&zipResp); err != nil {
+			t.Fatalf("%s: failed to decode response: %v", target, err)
+		}
+		zipData, err := base64.StdEncoding.DecodeString(zipResp.ZipBase64)
+		if err != nil {
+			t.Fatalf("%s: zipBase64 is not valid base64: %v", target, err)
+		}
+		if !zipResp.Success || !strings.HasSuffix(zipResp.Filename, ".zip") || zipResp.Size != len(zipData) {
+			t.Fatalf("%s: unexpected response: success=%v filename=%q size=%d", target, zipResp.Success, zipResp.Filename, zipResp.Size)
+		}
+		if _, err := zip.NewReader(bytes.NewReader(zipData), int64(len(zipData))); err != nil {
+			t.Fatalf("%s: decoded data is not a zip: %v", target, err)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/export-nodejs?format=base64", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+		t.Fatalf("expected ?format=base64 to return JSON, got %q", ct)
+	}
+}
+
+func TestExportReturnsTarGzWhenRequested(t *testing.T) {
+	app := newTestApp()
+
+	body, _ := json.Marshal(map[string]string{"html": "<html><body><p>hi</p></body></html>"})
+	for _, target := range []string{"/api/export?format=tar.gz", "/api/export-nodejs?format=tar.gz", "/api/export-nodejs-ejs"} {
+		req := httptest.NewRequest(http.MethodPost, target, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/gzip")
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("%s: request failed: %v", target, err)
+		}
+		if resp.StatusCode != 200 {
+			t.Fatalf("%s: expected 200, got %d", target, resp.StatusCode)
+		}
+		if ct := resp.Header.Get("Content-Type"); ct != "application/gzip" {
+			t.Fatalf("%s: expected application/gzip, got %q", target, ct)
+		}
+		if cd := resp.Header.Get("Content-Disposition"); !strings.Contains(cd, ".tar.gz\"") {
+			t.Fatalf("%s: expected a .tar.gz filename, got %q", target, cd)
+		}
+
+		gz, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			t.Fatalf("%s: body is not gzip: %v", target, err)
+		}
+		reader := tar.NewReader(gz)
+		entries := 0
+		for {
+			if _, err := reader.Next(); err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatalf("%s: failed to read tar entry: %v", target, err)
+			}
+			entries++
+		}
+		if entries == 0 {
+			t.Fatalf("%s: expected entries in the tarball", target)
+		}
+	}
+}
+
+func TestComponentizeReturnsMainComponentsAndSuggestions(t *testing.T) {
+	app := newTestApp()
+
+	page := `<main><figure class="card">1</figure><figure class="card">2</figure><figure class="card">3</figure></main>`
+	body, _ := json.Marshal(map[string]string{"html": page, "language": "ts"})
+	req := httptest.NewRequest(http.MethodPost, "/api/componentize", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if resp.StatusCode != 200 {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+
+	var out struct {
+		Success bool `json:"success"`
+		Main    struct {
+			Name, Filename, Code string
+		} `json:"main"`
+		Components []struct {
+			Name, Filename, Code string
+		} `json:"components"`
+		Suggestions []struct {
+			Name string `json:"name"`
+		} `json:"suggestions"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if !out.Success || out.Main.Filename != "MainComponent.tsx" ||
+		!strings.Contains(out.Main.Code, "import FigureCard from './FigureCard'") || !strings.Contains(out.Main.Code, `<FigureCard className="card" label="1" />`) {
+		t.Fatalf("unexpected main component: %+v", out.Main)
+	}
+	if len(out.Suggestions) != 1 || len(out.Components) != 1 || out.Components[0].Filename != "FigureCard.tsx" {
+		t.Fatalf("expected one suggested FigureCard component, got %+v / %+v", out.Components, out.Suggestions)
+	}
+}
+
+func TestConvertSingleFileInlinesPageStyles(t *testing.T) {
+	app := newTestApp()
+
+	page := `<html><head><style>.hero { color: red; }</style></head><body><section class="hero">Hi</section></body></html>`
+	body, _ := json.Marshal(map[string]any{"html": page, "singleFile": true})
+	req := httptest.NewRequest(http.MethodPost, "/api/convert", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+
+	out := decodeResponse(t, resp)
+	if !out.Success || !strings.Contains(out.Data, ".hero { color: red; }") || !strings.Contains(out.Data, "<style>{styles}</style>") || strings.Contains(out.Data, "import '") {
+		t.Fatalf("expected the page styles inline in the component, got %+v", out)
+	}
+}
+
+func TestConvertPreviewRendersJSXBackToHTML(t *testing.T) {
+	app := newTestApp()
+
+	page := `<section class="hero"><label for="q">Search</label><input id="q"></section>`
+	body, _ := json.Marshal(map[string]string{"html": page})
+	req := httptest.NewRequest(http.MethodPost, "/api/convert-preview", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if resp.StatusCode != 200 {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+
+	var out PreviewResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if !out.Success || !strings.Contains(out.JSX, `className="hero"`) || !strings.Contains(out.Original, `<section class="hero">`) {
+		t.Fatalf("unexpected response: %+v", out)
+	}
+	if !strings.Contains(out.HTML, `<section class="hero">`) || !strings.Contains(out.HTML, `<label for="q">Search</label>`) {
+		t.Fatalf("expected the preview to restore HTML attribute names, got:\n%s", out.HTML)
+	}
+}
+
+func TestConvertPreviewRejectsGenerateProps(t *testing.T) {
+	app := newTestApp()
+
+	body, _ := json.Marshal(map[string]any{"html": `<ul><li>A</li><li>B</li><li>C</li></ul>`, "generateProps": true})
+	req := httptest.NewRequest(http.MethodPost, "/api/convert-preview", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if resp.StatusCode != 400 {
+		t.Fatalf("expected 400, got %d", resp.StatusCode)
+	}
+}
+
+func TestExportNodeJSShipsExternalizedDataURIs(t *testing.T) {
+	app := newTestApp()
+
+	png := append([]byte("\x89PNG\r\n\x1a\n"), bytes.Repeat([]byte{0x42}, 6000)...)
+	page := `<html><body><img src="data:image/png;base64,` + base64.StdEncoding.EncodeToString(png) + `" alt="hero"></body></html>`
+	body, _ := json.Marshal(map[string]any{"html": page, "externalizeDataUris": true})
+	req := httptest.NewRequest(http.MethodPost, "/api/export-nodejs", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if resp.StatusCode != 200 {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+
+	zipData, _ := io.ReadAll(resp.Body)
+	zr, err := zip.NewReader(bytes.NewReader(zipData), int64(len(zipData)))
+	if err != nil {
+		t.Fatalf("response is not a zip: %v", err)
+	}
+	for _, f := range zr.File {
+		if !strings.HasSuffix(f.Name, "public/assets/data-image-1.png") {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("failed to open %s: %v", f.Name, err)
+		}
+		got, _ := io.ReadAll(rc)
+		rc.Close()
+		if !bytes.Equal(got, png) {
+			t.Fatalf("%s does not hold the decoded image", f.Name)
+		}
+		return
+	}
+	t.Fatal("expected public/assets/data-image-1.png in the archive")
+}
+
+func TestExportNodeJSShipsFetchedIcons(t *testing.T) {
+	defer fetcher.SetPolicy(fetcher.SetPolicy(fetcher.Policy{AllowPrivate: true}))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte("png-bytes"))
+	}))
+	defer server.Close()
+
+	app := newTestApp()
+
+	page := `<html><head><link rel="icon" href="` + server.URL + `/favicon.png"></head><body><p>hi</p></body></html>`
+	body, _ := json.Marshal(map[string]string{"html": page})
+	req := httptest.NewRequest(http.MethodPost, "/api/export-nodejs", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if resp.StatusCode != 200 {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+
+	zipData, _ := io.ReadAll(resp.Body)
+	zr, err := zip.NewReader(bytes.NewReader(zipData), int64(len(zipData)))
+	if err != nil {
+		t.Fatalf("response is not a zip: %v", err)
+	}
+	files := make(map[string]string)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("failed to open %s: %v", f.Name, err)
+		}
+		content, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name[strings.Index(f.Name, "/")+1:]] = string(content)
+	}
+	if files["public/assets/favicon.png"] != "png-bytes" {
+		t.Fatalf("expected public/assets/favicon.png in the archive")
+	}
+	if !strings.Contains(files["src/index.html"], `<link rel="icon" href="/assets/favicon.png" />`) {
+		t.Fatalf("expected src/index.html to link the icon:\n%s", files["src/index.html"])
+	}
+}
+
+func TestFormatURLStatusCodes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html><body><p>" + strings.Repeat("x", 200) + "</p></body></html>"))
+	}))
+	defer server.Close()
+
+	post := func(app *fiber.App) int {
+		body, _ := json.Marshal(map[string]string{"url": server.URL})
+		req := httptest.NewRequest(http.MethodPost, "/api/format-url", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		return resp.StatusCode
+	}
+
+	if status := post(newTestApp()); status != fiber.StatusForbidden {
+		t.Fatalf("expected a loopback URL to be refused with 403, got %d", status)
+	}
+
+	defer fetcher.SetPolicy(fetcher.SetPolicy(fetcher.Policy{AllowPrivate: true}))
+	t.Setenv("MAX_HTML_BYTES", "100")
+	if status := post(newTestApp()); status != fiber.StatusRequestEntityTooLarge {
+		t.Fatalf("expected a page over MAX_HTML_BYTES to be refused with 413, got %d", status)
+	}
+}
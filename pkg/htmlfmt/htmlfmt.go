@@ -0,0 +1,219 @@
+// Package htmlfmt is the library interface to uncluster: the formatting,
+// conversion, extraction, analysis, and project generation that the HTTP
+// server exposes, callable without running it. The server calls it too, so
+// the two behave the same way.
+//
+// Zero-value options give the server's defaults.
+package htmlfmt
+
+import (
+	"errors"
+
+	"github.com/omariomari2/uncluster/internal/analyzer"
+	"github.com/omariomari2/uncluster/internal/converter"
+	"github.com/omariomari2/uncluster/internal/extractor"
+	"github.com/omariomari2/uncluster/internal/formatter"
+	"github.com/omariomari2/uncluster/internal/zipper"
+)
+
+// Component is a converted React component.
+type Component struct {
+	Name     string `json:"name"`     // PascalCase component name
+	Filename string `json:"filename"` // Name plus .jsx or .tsx
+	Code     string `json:"code"`     // module source with a default export
+
+	// StyleModule is the source of Name + ".module.css", which Code imports,
+	// when the cssModules style strategy moved styles out of the markup.
+	StyleModule string `json:"styleModule,omitempty"`
+}
+
+// Extracted is a page with its inline and external CSS and JS split out.
+type Extracted struct {
+	// HTML is the page with its CSS and JS replaced by links to the files.
+	HTML string
+	// CSS and JS are the page's inline styles and scripts, concatenated.
+	CSS string
+	JS  string
+	// InlineCSS and InlineJS hold each inline block as its own file.
+	InlineCSS []File
+	InlineJS  []File
+	// ExternalCSS and ExternalJS are the linked stylesheets and scripts.
+	ExternalCSS []Resource
+	ExternalJS  []Resource
+	// LocalAssets are binary files the page links, such as downloaded icons
+	// and data URI images written out with ExternalizeDataURIs.
+	LocalAssets []Asset
+
+	source *extractor.ExtractedContent
+}
+
+// Files returns the extraction laid out as the server's export archive holds
+// it, by path: the page, its resources with placeholders for failed
+// downloads, the assets, errors.txt when a download failed, and
+// manifest.json. It reflects the extraction as Extract returned it.
+func (e *Extracted) Files() (map[string][]byte, error) {
+	if e.source == nil {
+		return nil, errors.New("htmlfmt: Files needs an Extracted returned by Extract")
+	}
+	entries, err := zipper.Entries(e.source)
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string][]byte, len(entries))
+	for _, entry := range entries {
+		files[entry.Name] = entry.Content
+	}
+	return files, nil
+}
+
+// File is an extracted inline style or script block.
+type File struct {
+	Path    string // e.g. "inline/style-1.css"
+	Content string
+	Type    string // script type attribute, e.g. "module"; empty for classic scripts and CSS
+}
+
+// Resource is an external stylesheet or script.
+type Resource struct {
+	URL      string
+	Filename string // file name under external/css or external/js; empty when not downloaded
+	Content  string
+	// Error is why the download failed, and SkipReason why it was not
+	// attempted, such as KeepExternal.
+	Error      error
+	SkipReason string
+}
+
+// Asset is a binary file belonging to the page.
+type Asset struct {
+	Path    string // e.g. "assets/logo.png"
+	Content []byte
+	MIME    string
+}
+
+// Suggestion is a repeated pattern that could become a component.
+type Suggestion struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	TagName     string            `json:"tagName"`
+	Attributes  map[string]string `json:"attributes"`
+	Children    []string          `json:"children"`
+	Count       int               `json:"count"`
+	JSXCode     string            `json:"jsxCode"`
+	// Category is navigation, forms, buttons, cards, layout, or other.
+	Category string `json:"category"`
+	// SourceLine and SourceColumn locate the first occurrence in the input
+	// (1-based), or are zero for elements the parser implied.
+	SourceLine   int `json:"sourceLine,omitempty"`
+	SourceColumn int `json:"sourceColumn,omitempty"`
+	// TextProps are the props JSXCode takes for text that differs between
+	// occurrences.
+	TextProps []string `json:"textProps,omitempty"`
+}
+
+// Componentization is a page converted into suggested components and a main
+// component that uses them.
+type Componentization struct {
+	Main        Component    `json:"main"`
+	Components  []Component  `json:"components"`
+	Suggestions []Suggestion `json:"suggestions"`
+}
+
+// Format pretty-prints htmlInput.
+func Format(htmlInput string, opts FormatOptions) (string, error) {
+	return formatter.FormatWithOptions(htmlInput, formatter.Options(opts))
+}
+
+// Convert converts htmlInput to a React component.
+func Convert(htmlInput string, opts ConvertOptions) (Component, error) {
+	// The converter leaves <style> elements out of the markup, so a single
+	// file gets the page's styles from the extractor, without downloads.
+	css := ""
+	if opts.SingleFile {
+		extracted, err := extractor.ExtractWithOptions(htmlInput, extractor.ExtractOptions{KeepExternal: true, SkipJS: true})
+		if err != nil {
+			return Component{}, err
+		}
+		css = extracted.CSS
+	}
+
+	component, err := converter.ConvertToComponent(htmlInput, css, "", nil, nil, converter.Options(opts))
+	if err != nil {
+		return Component{}, err
+	}
+	return Component(component), nil
+}
+
+// Componentize converts htmlInput into a component per suggestion and a main
+// component that renders the page with them.
+func Componentize(htmlInput string, opts ConvertOptions) (*Componentization, error) {
+	result, err := converter.Componentize(htmlInput, converter.Options(opts))
+	if err != nil {
+		return nil, err
+	}
+	out := &Componentization{
+		Main:        Component(result.Main),
+		Components:  make([]Component, len(result.Components)),
+		Suggestions: suggestions(result.Suggestions),
+	}
+	for i, component := range result.Components {
+		out.Components[i] = Component(component)
+	}
+	return out, nil
+}
+
+// Extract moves htmlInput's inline CSS and JS into files and downloads its
+// external stylesheets and scripts.
+func Extract(htmlInput string, opts ExtractOptions) (*Extracted, error) {
+	extracted, err := extractor.ExtractWithOptions(htmlInput, extractor.ExtractOptions(opts))
+	if err != nil {
+		return nil, err
+	}
+
+	out := &Extracted{HTML: extracted.HTML, CSS: extracted.CSS, JS: extracted.JS, source: extracted}
+	for _, r := range extracted.InlineCSS {
+		out.InlineCSS = append(out.InlineCSS, File(r))
+	}
+	for _, r := range extracted.InlineJS {
+		out.InlineJS = append(out.InlineJS, File(r))
+	}
+	for _, r := range extracted.ExternalCSS {
+		out.ExternalCSS = append(out.ExternalCSS, Resource{URL: r.URL, Filename: r.Filename, Content: r.Content, Error: r.Error, SkipReason: r.SkipReason})
+	}
+	for _, r := range extracted.ExternalJS {
+		out.ExternalJS = append(out.ExternalJS, Resource{URL: r.URL, Filename: r.Filename, Content: r.Content, Error: r.Error, SkipReason: r.SkipReason})
+	}
+	for _, asset := range extracted.LocalAssets {
+		out.LocalAssets = append(out.LocalAssets, Asset{Path: asset.Path, Content: asset.Content, MIME: asset.MIME})
+	}
+	return out, nil
+}
+
+// Analyze suggests components for the patterns repeated in htmlInput.
+func Analyze(htmlInput string, opts AnalyzeOptions) ([]Suggestion, error) {
+	found, err := analyzer.AnalyzeComponentsWithOptions(htmlInput, analyzer.AnalyzeOptions{Exclude: analyzer.Exclusions(opts.Exclude)})
+	if err != nil {
+		return nil, err
+	}
+	return suggestions(found), nil
+}
+
+func suggestions(found []analyzer.ComponentSuggestion) []Suggestion {
+	out := make([]Suggestion, len(found))
+	for i, s := range found {
+		out[i] = Suggestion{
+			Name:         s.Name,
+			Description:  s.Description,
+			TagName:      s.TagName,
+			Attributes:   s.Attributes,
+			Children:     s.Children,
+			Count:        s.Count,
+			JSXCode:      s.JSXCode,
+			Category:     s.Category,
+			SourceLine:   s.SourceLine,
+			SourceColumn: s.SourceColumn,
+			TextProps:    s.TextProps,
+		}
+	}
+	return out
+}
@@ -0,0 +1,122 @@
+package htmlfmt
+
+import (
+	"strings"
+	"testing"
+)
+
+const page = `<html><head><style>.card { color: red; }</style></head><body>
+<section class="hero"><h1>Hello</h1></section>
+<figure class="card"><h2>One</h2><p>First</p></figure>
+<figure class="card"><h2>Two</h2><p>Second</p></figure>
+<figure class="card"><h2>Three</h2><p>Third</p></figure>
+</body></html>`
+
+func TestLibraryEntryPoints(t *testing.T) {
+	formatted, err := Format(page, FormatOptions{})
+	if err != nil || !strings.Contains(formatted, "\n") {
+		t.Fatalf("Format returned %q, %v", formatted, err)
+	}
+
+	component, err := Convert(page, ConvertOptions{ComponentName: "Page"})
+	if err != nil || !strings.Contains(component.Code, "function Page()") {
+		t.Fatalf("Convert returned %q, %v", component.Code, err)
+	}
+
+	extracted, err := Extract(page, ExtractOptions{})
+	if err != nil || len(extracted.InlineCSS) != 1 {
+		t.Fatalf("Extract returned %+v, %v", extracted, err)
+	}
+
+	suggestions, err := Analyze(page, AnalyzeOptions{})
+	if err != nil || len(suggestions) == 0 {
+		t.Fatalf("Analyze returned %v, %v", suggestions, err)
+	}
+}
+
+func TestExtractedFilesMatchExportLayout(t *testing.T) {
+	input := `<html><head><link rel="stylesheet" href="http://127.0.0.1:1/site.css"><style>p { margin: 0; }</style></head><body><p>hi</p></body></html>`
+	extracted, err := Extract(input, ExtractOptions{})
+	if err != nil {
+		t.Fatalf("Extract returned error: %v", err)
+	}
+	files, err := extracted.Files()
+	if err != nil {
+		t.Fatalf("Files returned error: %v", err)
+	}
+	placeholder := "external/css/" + extracted.ExternalCSS[0].Filename
+	for _, name := range []string{"index.html", "inline/style-1.css", placeholder, "errors.txt", "manifest.json"} {
+		if len(files[name]) == 0 {
+			t.Fatalf("expected %s in %v", name, keys(files))
+		}
+	}
+	if !strings.Contains(string(files[placeholder]), "failed to fetch") {
+		t.Fatalf("expected a placeholder for the failed download, got %q", files[placeholder])
+	}
+
+	if _, err := (&Extracted{}).Files(); err == nil {
+		t.Fatalf("expected Files to fail on an Extracted not returned by Extract")
+	}
+}
+
+func keys(m map[string][]byte) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	return out
+}
+
+func TestConvertSingleFileAndComponentize(t *testing.T) {
+	component, err := Convert(page, ConvertOptions{SingleFile: true})
+	if err != nil || !strings.Contains(component.Code, ".card { color: red; }") {
+		t.Fatalf("expected the page's styles in the single file, got %q, %v", component.Code, err)
+	}
+
+	result, err := Componentize(page, ConvertOptions{})
+	if err != nil {
+		t.Fatalf("Componentize returned error: %v", err)
+	}
+	if len(result.Components) == 0 || len(result.Suggestions) != len(result.Components) || !strings.Contains(result.Main.Code, "import "+result.Components[0].Name) {
+		t.Fatalf("unexpected componentization %+v", result)
+	}
+}
+
+func TestOptionsValidate(t *testing.T) {
+	if err := (FormatOptions{VoidStyle: "sgml"}).Validate(); err == nil {
+		t.Fatalf("expected FormatOptions.Validate to reject an unknown void style")
+	}
+	if err := (ConvertOptions{Language: "coffee"}).Validate(); err == nil {
+		t.Fatalf("expected ConvertOptions.Validate to reject an unknown language")
+	}
+	if err := (ExtractOptions{CSSFileName: "../x.css"}).Validate(); err == nil {
+		t.Fatalf("expected ExtractOptions.Validate to reject a path")
+	}
+	if err := (ConvertOptions{}).Validate(); err != nil {
+		t.Fatalf("expected the zero ConvertOptions to be valid, got %v", err)
+	}
+}
+
+func TestGenerateProjects(t *testing.T) {
+	react, err := GenerateReactProject(page, ReactProjectOptions{Name: "demo"})
+	if err != nil {
+		t.Fatalf("GenerateReactProject returned error: %v", err)
+	}
+	if _, ok := react.Files["package.json"]; !ok || react.Name != "demo" {
+		t.Fatalf("unexpected React project %q with files %v", react.Name, react.Files)
+	}
+	if data, err := react.Zip(); err != nil || len(data) == 0 {
+		t.Fatalf("Zip returned %d bytes, %v", len(data), err)
+	}
+
+	ejs, err := GenerateEJSProject(page, EJSProjectOptions{})
+	if err != nil {
+		t.Fatalf("GenerateEJSProject returned error: %v", err)
+	}
+	if _, ok := ejs.Files["package.json"]; !ok || ejs.Name != "project" {
+		t.Fatalf("unexpected EJS project %q with files %v", ejs.Name, ejs.Files)
+	}
+	if data, err := ejs.TarGz(); err != nil || len(data) == 0 {
+		t.Fatalf("TarGz returned %d bytes, %v", len(data), err)
+	}
+}
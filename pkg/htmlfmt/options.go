@@ -0,0 +1,175 @@
+package htmlfmt
+
+import (
+	"time"
+
+	"github.com/omariomari2/uncluster/internal/analyzer"
+	"github.com/omariomari2/uncluster/internal/converter"
+	"github.com/omariomari2/uncluster/internal/extractor"
+	"github.com/omariomari2/uncluster/internal/formatter"
+	"github.com/omariomari2/uncluster/internal/nodejs"
+)
+
+// FormatOptions tunes Format.
+type FormatOptions struct {
+	// EncodeEntities re-encodes non-ASCII characters in text and attribute
+	// values as entities, named where a common name exists.
+	EncodeEntities bool
+	// EntityChars limits EncodeEntities to the characters in this string.
+	// Empty means every non-ASCII character.
+	EntityChars string
+	// Lenient formats misplaced elements where they were written instead of
+	// where the HTML parser moves them.
+	Lenient bool
+	// VoidStyle closes void elements as <br /> ("xhtml", default) or <br>
+	// ("html").
+	VoidStyle string
+}
+
+// Validate reports options Format would reject.
+func (o FormatOptions) Validate() error {
+	_, err := formatter.Options(o).Normalize()
+	return err
+}
+
+// ConvertOptions tunes Convert and Componentize.
+type ConvertOptions struct {
+	// ComponentName is the generated function name. Defaults to
+	// "MainComponent".
+	ComponentName string
+	// Language is "js" (default) or "ts".
+	Language string
+	// WrapperMode wraps the markup in a "fragment" (default), a "div", or,
+	// with "none", nothing when it has a single root element.
+	WrapperMode string
+	// GenerateProps turns a repeated list into an items prop that defaults
+	// to the page's data.
+	GenerateProps bool
+	// KeepDocumentShell renders <html>, <head>, and <body> instead of
+	// unwrapping them, for root layouts.
+	KeepDocumentShell bool
+	// StyleStrategy converts style attributes to "inline" objects (default)
+	// or moves them to a CSS Module with "cssModules".
+	StyleStrategy string
+	// StripScripts drops the page's scripts.
+	StripScripts bool
+	// StripStyles drops the page's stylesheet imports.
+	StripStyles bool
+	// Clean removes editor-injected attributes and empty style and class
+	// attributes before converting.
+	Clean bool
+	// StripAttributes lists more attributes to remove; names may use *
+	// wildcards, such as "data-wf-*".
+	StripAttributes []string
+	// Indent is the indentation of the generated code: "2" (default), "4",
+	// or "tab".
+	Indent string
+	// SingleFile renders the page's styles in a <style> element inside the
+	// component instead of importing a stylesheet.
+	SingleFile bool
+}
+
+// Validate reports options Convert would reject.
+func (o ConvertOptions) Validate() error {
+	_, err := converter.Options(o).Normalize()
+	return err
+}
+
+// ExtractOptions tunes Extract and the extraction step of the project
+// generators.
+type ExtractOptions struct {
+	// CSSFileName and JSFileName name the numbered files inline <style> and
+	// <script> blocks move to. They default to style.css and script.js.
+	CSSFileName string
+	JSFileName  string
+	// ExternalizeDataURIs writes data URI images longer than DataURIThreshold
+	// bytes (4 KB when zero) to assets/ as files.
+	ExternalizeDataURIs bool
+	DataURIThreshold    int
+	// Timeout bounds downloading external resources; zero means no limit.
+	Timeout time.Duration
+	// KeepExternal links external CSS and JS at their original URLs instead
+	// of downloading them.
+	KeepExternal bool
+	// SkipCSS and SkipJS leave the page's CSS or JS in the HTML.
+	SkipCSS bool
+	SkipJS  bool
+	// DedupeCSS drops CSS rule blocks repeated in a later stylesheet.
+	DedupeCSS bool
+	// HashFilenames names downloaded CSS and JS after a hash of their
+	// content.
+	HashFilenames bool
+}
+
+// Validate reports options Extract would reject.
+func (o ExtractOptions) Validate() error {
+	_, err := extractor.ExtractOptions(o).Normalize()
+	return err
+}
+
+// AnalyzeOptions tunes Analyze.
+type AnalyzeOptions struct {
+	// Exclude lists elements that are never suggested as components, in
+	// addition to the default exclusions.
+	Exclude Exclusions
+}
+
+// Exclusions selects elements that never become components or partials.
+type Exclusions struct {
+	// Tags are tag names, such as "iframe".
+	Tags []string `json:"tags,omitempty"`
+	// Classes match a class that is the value or contains it as
+	// hyphen-separated parts: "ad" matches "ad-slot" but not "add-to-cart".
+	Classes []string `json:"classes,omitempty"`
+	// DataAttributes are attribute names, such as "data-ad", whose presence
+	// excludes an element.
+	DataAttributes []string `json:"dataAttributes,omitempty"`
+	// NoDefaults drops the default exclusions (screen-reader-only helpers and
+	// ad and tracking containers) instead of adding to them.
+	NoDefaults bool `json:"noDefaults,omitempty"`
+}
+
+// DependencyVersions sets the React project's package.json versions; empty
+// fields keep the defaults.
+type DependencyVersions struct {
+	React       string // react and react-dom
+	Vite        string
+	TypeScript  string
+	ReactRouter string // react-router-dom, added with Router
+}
+
+// ProjectLayout names the React project's folders under src/; empty fields
+// keep the defaults.
+type ProjectLayout struct {
+	Components string // default "components"
+	Styles     string // default "styles"
+	Scripts    string // default "scripts"
+}
+
+// EJSExtractionOptions tunes how an EJS project is split into partials.
+type EJSExtractionOptions struct {
+	// MaxDepth is how many levels below the content root are searched for
+	// sections. Defaults to 5.
+	MaxDepth int
+	// ExtraKeywords are class or id values that mark an element as a
+	// section, in addition to navbar, nav, header, footer, hero, and section.
+	ExtraKeywords []string
+	// MinTextLength is the minimum size in bytes of a partial. Defaults to
+	// 500.
+	MinTextLength int
+	// Exclude lists elements that never become partials.
+	Exclude Exclusions
+	// RootDepth is how many single-child wrappers below the body are skipped
+	// to find the content root. Defaults to 4; negative keeps the body.
+	RootDepth int
+}
+
+func (o EJSExtractionOptions) internal() nodejs.EJSExtractionOptions {
+	return nodejs.EJSExtractionOptions{
+		MaxDepth:      o.MaxDepth,
+		ExtraKeywords: o.ExtraKeywords,
+		MinTextLength: o.MinTextLength,
+		Exclude:       analyzer.Exclusions(o.Exclude),
+		RootDepth:     o.RootDepth,
+	}
+}
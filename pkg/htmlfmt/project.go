@@ -0,0 +1,144 @@
+package htmlfmt
+
+import (
+	"github.com/omariomari2/uncluster/internal/extractor"
+	"github.com/omariomari2/uncluster/internal/nodejs"
+)
+
+// defaultProjectName is the directory a Project's archives are rooted at when
+// the options don't name one.
+const defaultProjectName = "project"
+
+// Project is a generated project.
+type Project struct {
+	// Name is the directory the archives put the files under.
+	Name string
+	// Files maps paths relative to the project root to their content.
+	Files map[string]string
+	// Assets holds binary files, such as images decoded from data URIs, by
+	// the same kind of path.
+	Assets map[string][]byte
+}
+
+// Zip packs the project into a zip archive.
+func (p *Project) Zip() ([]byte, error) {
+	return nodejs.CreateProjectZipWithBinary(p.Files, p.Assets, p.Name)
+}
+
+// TarGz packs the project into a gzipped tarball.
+func (p *Project) TarGz() ([]byte, error) {
+	return nodejs.CreateProjectTarGzWithBinary(p.Files, p.Assets, p.Name)
+}
+
+// ReactProjectOptions tunes GenerateReactProject.
+type ReactProjectOptions struct {
+	// Name is the project directory name. Defaults to "project".
+	Name string
+	// PackageManager is "npm" (default), "yarn", or "pnpm".
+	PackageManager string
+	// Language is "ts" (default) or "js".
+	Language string
+	// Tailwind forces Tailwind CSS on or off; nil detects it from the page.
+	Tailwind *bool
+	// ComponentName names the converted page component. Defaults to
+	// "MainComponent".
+	ComponentName string
+	// IncludeDocker adds a Dockerfile and .dockerignore.
+	IncludeDocker bool
+	// Minify minifies the page's own CSS. The page's script stays readable
+	// in the converted component.
+	Minify bool
+	// Router sets up react-router-dom with a route per major section when
+	// the page has two or more.
+	Router bool
+	// Versions sets dependency versions; empty fields keep the defaults.
+	Versions DependencyVersions
+	// Layout renames the folders under src/; empty fields keep the defaults.
+	Layout ProjectLayout
+	// Extract tunes how the page's resources are extracted.
+	Extract ExtractOptions
+}
+
+// GenerateReactProject turns htmlInput into a Vite + React project.
+func GenerateReactProject(htmlInput string, opts ReactProjectOptions) (*Project, error) {
+	extracted, err := extractor.ExtractWithOptions(htmlInput, extractor.ExtractOptions(opts.Extract))
+	if err != nil {
+		return nil, err
+	}
+
+	name := projectName(opts.Name)
+	files, err := nodejs.GenerateProject(&nodejs.ProjectConfig{
+		ProjectName:    name,
+		PackageManager: opts.PackageManager,
+		Language:       opts.Language,
+		Tailwind:       opts.Tailwind,
+		ComponentName:  opts.ComponentName,
+		IncludeDocker:  opts.IncludeDocker,
+		HTML:           extracted.RewriteForNodeJS(),
+		CSS:            extracted.CSS,
+		JS:             extracted.JS,
+		ExternalCSS:    extracted.ExternalCSS,
+		ExternalJS:     extracted.ExternalJS,
+		Head:           extracted.Head,
+		Versions:       nodejs.DependencyVersions(opts.Versions),
+		Minify:         opts.Minify,
+		Router:         opts.Router,
+		Layout:         nodejs.Layout(opts.Layout),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Project{Name: name, Files: files.Files, Assets: publicAssets(extracted.LocalAssets)}, nil
+}
+
+// EJSProjectOptions tunes GenerateEJSProject.
+type EJSProjectOptions struct {
+	// Name is the project directory name. Defaults to "project".
+	Name string
+	// Extraction tunes how the page is split into partials.
+	Extraction EJSExtractionOptions
+	// Extract tunes how the page's resources are extracted.
+	Extract ExtractOptions
+}
+
+// GenerateEJSProject turns htmlInput into an Express + EJS project with the
+// page's sections as partials.
+func GenerateEJSProject(htmlInput string, opts EJSProjectOptions) (*Project, error) {
+	extracted, err := extractor.ExtractWithOptions(htmlInput, extractor.ExtractOptions(opts.Extract))
+	if err != nil {
+		return nil, err
+	}
+
+	name := projectName(opts.Name)
+	files, err := nodejs.GenerateEJSProject(&nodejs.EJSProjectConfig{
+		ProjectName: name,
+		HTML:        extracted.RewriteForEJS(),
+		InlineCSS:   extracted.InlineCSS,
+		InlineJS:    extracted.InlineJS,
+		ExternalCSS: extracted.ExternalCSS,
+		ExternalJS:  extracted.ExternalJS,
+		Extraction:  opts.Extraction.internal(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Project{Name: name, Files: files.Files, Assets: publicAssets(extracted.LocalAssets)}, nil
+}
+
+// publicAssets places the page's binary assets under public/, which both
+// project kinds serve from the site root.
+func publicAssets(local []extractor.LocalAsset) map[string][]byte {
+	assets := make(map[string][]byte, len(local))
+	for _, asset := range local {
+		assets["public/"+asset.Path] = asset.Content
+	}
+	return assets
+}
+
+func projectName(name string) string {
+	if name == "" {
+		return defaultProjectName
+	}
+	return name
+}